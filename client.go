@@ -52,6 +52,9 @@ type APIClient struct {
 
 	// dumpWriter will receive HTTP dumps if non-nil.
 	dumpWriter io.Writer
+
+	// retainRawData makes entities keep the JSON body they were fetched with.
+	retainRawData bool
 }
 
 // Session holds the session ID and auth token needed to identify an
@@ -94,6 +97,13 @@ type ClientConfig struct {
 
 	// The maximum number of concurrent HTTP requests that will be made (default: 1)
 	MaxConcurrentRequests int64
+
+	// RetainRawData makes every entity fetched with the client keep the JSON
+	// body it was decoded from, so it can be decoded again with Entity.As
+	// without another request. This costs the memory of the bodies, except
+	// for types that already keep their body to compare updates, which share
+	// it.
+	RetainRawData bool
 }
 
 // setupClientWithConfig setups the client using the client config
@@ -103,9 +113,10 @@ func setupClientWithConfig(ctx context.Context, config *ClientConfig) (c *APICli
 	}
 
 	client := &APIClient{
-		endpoint:   config.Endpoint,
-		dumpWriter: config.DumpWriter,
-		ctx:        ctx,
+		endpoint:      config.Endpoint,
+		dumpWriter:    config.DumpWriter,
+		ctx:           ctx,
+		retainRawData: config.RetainRawData,
 	}
 
 	if config.MaxConcurrentRequests <= 0 {
@@ -237,6 +248,26 @@ func (c *APIClient) GetService() *Service {
 	return c.Service
 }
 
+// RetainsRawData reports whether entities fetched with the client keep the
+// JSON body they were decoded from.
+func (c *APIClient) RetainsRawData() bool {
+	return c.retainRawData
+}
+
+// Vendor returns the vendor reported by the service root, which selects the
+// OEM decoders used by Entity.OEMExtension.
+func (c *APIClient) Vendor() string {
+	if c.Service == nil {
+		return ""
+	}
+	return c.Service.Vendor
+}
+
+// Endpoint returns the URL of the Redfish service the client is connected to.
+func (c *APIClient) Endpoint() string {
+	return c.endpoint
+}
+
 // CloneWithSession will create a new Client with a session instead of basic auth.
 func (c *APIClient) CloneWithSession() (*APIClient, error) {
 	if c.auth.Session != "" {
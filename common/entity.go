@@ -6,7 +6,9 @@ package common
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"reflect"
 	"strings"
@@ -34,6 +36,19 @@ type Entity struct {
 	// This is a work around for bad vendor implementations where the If-Match header does not work - even with the '*' value
 	// and requests are incorrectly denied with an ETag mismatch error.
 	disableEtagMatch bool
+	// mergeOnEtagMismatch when set will merge updates rejected because the etag
+	// did not match with the current state of the entity and send them again.
+	mergeOnEtagMismatch bool
+	// rawData is the body the entity was decoded from, if the client retains
+	// raw data.
+	rawData []byte
+}
+
+// RawDataRetainer is implemented by clients that can ask entities to keep the
+// raw JSON body they were fetched with.
+type RawDataRetainer interface {
+	// RetainsRawData reports whether entities keep their raw JSON body.
+	RetainsRawData() bool
 }
 
 // SetClient sets the API client connection to use for accessing this
@@ -48,6 +63,50 @@ func (e *Entity) GetClient() Client {
 	return e.client
 }
 
+// ETag returns the etag header received when the entity was fetched, or an
+// empty string if the service did not return one.
+func (e *Entity) ETag() string {
+	return e.etag
+}
+
+// RawData returns the JSON body the entity was fetched with. It is only kept
+// if the client was configured to retain raw data. The body is decoded in
+// place, so types that keep their JSON to compare updates share it rather
+// than holding a second copy.
+func (e *Entity) RawData() []byte {
+	return e.rawData
+}
+
+// body returns the JSON body of the entity, fetching it from the service
+// again if the client does not retain raw data.
+func (e *Entity) body() ([]byte, error) {
+	if e.rawData != nil {
+		return e.rawData, nil
+	}
+	if e.client == nil || e.ODataID == "" {
+		return nil, errors.New("entity has no raw data and cannot be fetched again")
+	}
+
+	resp, err := e.client.Get(e.ODataID)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
+
+// As decodes the JSON body of the entity into target, such as a struct of
+// vendor-specific properties. If the client does not retain raw data, every
+// call fetches the entity from the service again.
+func (e *Entity) As(target interface{}) error {
+	b, err := e.body()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, target)
+}
+
 // Set stripEtagQuotes to enable/disable strupping etag quotes
 func (e *Entity) StripEtagQuotes(b bool) {
 	e.stripEtagQuotes = b
@@ -80,6 +139,9 @@ func (e *Entity) Update(originalEntity, updatedEntity reflect.Value, allowedUpda
 	// If there are any allowed updates, try to send updates to the system and
 	// return the result.
 	if len(payload) > 0 {
+		if e.mergeOnEtagMismatch {
+			return e.patchWithMerge(payload, getPatchPayloadFromUpdate(updatedEntity, originalEntity))
+		}
 		return e.Patch(e.ODataID, payload)
 	}
 
@@ -94,7 +156,16 @@ func (e *Entity) Get(c Client, uri string, payload interface{}) error {
 	}
 	defer resp.Body.Close()
 
-	err = json.NewDecoder(resp.Body).Decode(payload)
+	var rawData []byte
+	if retainer, ok := c.(RawDataRetainer); ok && retainer.RetainsRawData() {
+		rawData, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		err = json.Unmarshal(rawData, payload)
+	} else {
+		err = json.NewDecoder(resp.Body).Decode(payload)
+	}
 	if err != nil {
 		return err
 	}
@@ -102,6 +173,7 @@ func (e *Entity) Get(c Client, uri string, payload interface{}) error {
 	if resp.Header["Etag"] != nil {
 		e.etag = resp.Header["Etag"][0]
 	}
+	e.rawData = rawData
 	e.SetClient(c)
 	return nil
 }
@@ -158,6 +230,22 @@ func (e *Entity) PostWithResponse(uri string, payload interface{}) (*http.Respon
 	return e.client.PostWithHeaders(uri, payload, header)
 }
 
+// DeleteWithResponse performs a Delete request against the Redfish service with etag,
+// returning the response from the service.
+// Callers should make sure to call `resp.Body.Close()` when done with the response.
+func (e *Entity) DeleteWithResponse(uri string) (*http.Response, error) {
+	header := make(map[string]string)
+	if e.etag != "" && !e.disableEtagMatch {
+		if e.stripEtagQuotes {
+			e.etag = strings.Trim(e.etag, "\"")
+		}
+
+		header["If-Match"] = e.etag
+	}
+
+	return e.client.DeleteWithHeaders(uri, header)
+}
+
 type Filter string
 
 type FilterOption func(*Filter)
@@ -5,6 +5,8 @@
 package common
 
 import (
+	"encoding/json"
+	"net/http"
 	"reflect"
 	"testing"
 )
@@ -249,3 +251,95 @@ func TestGetPatchPayloadFromUpdate(t *testing.T) { //nolint:funlen
 		})
 	}
 }
+
+type rawDataEntity struct {
+	Entity
+	Model string
+}
+
+type vendorExtension struct {
+	VendorHealth struct {
+		Score int
+	}
+}
+
+// TestEntityAs tests decoding the raw JSON of an entity into another type.
+func TestEntityAs(t *testing.T) {
+	body := `{"@odata.id": "/redfish/v1/Chassis/1", "Id": "1", "Model": "X1", "VendorHealth": {"Score": 97}}`
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {TestResponse(http.StatusOK, body), TestResponse(http.StatusOK, body), TestResponse(http.StatusOK, body)},
+		},
+		RetainRawData: true,
+	}
+
+	var entity rawDataEntity
+	if err := entity.Get(testClient, "/redfish/v1/Chassis/1", &entity); err != nil {
+		t.Fatalf("Error making Get call: %s", err)
+	}
+	if entity.Model != "X1" || string(entity.RawData()) != body {
+		t.Errorf("Unexpected entity: %+v", entity)
+	}
+
+	var extension vendorExtension
+	if err := entity.As(&extension); err != nil {
+		t.Fatalf("Error making As call: %s", err)
+	}
+	if extension.VendorHealth.Score != 97 || len(testClient.CapturedCalls()) != 1 {
+		t.Errorf("Unexpected extension: %+v", extension)
+	}
+
+	// Without retained raw data the entity is fetched again
+	testClient.RetainRawData = false
+	entity = rawDataEntity{}
+	if err := entity.Get(testClient, "/redfish/v1/Chassis/1", &entity); err != nil {
+		t.Fatalf("Error making Get call: %s", err)
+	}
+	if entity.RawData() != nil {
+		t.Error("Expected no raw data to be retained")
+	}
+
+	extension = vendorExtension{}
+	if err := entity.As(&extension); err != nil {
+		t.Fatalf("Error making As call: %s", err)
+	}
+	if extension.VendorHealth.Score != 97 || len(testClient.CapturedCalls()) != 3 {
+		t.Errorf("Unexpected extension: %+v", extension)
+	}
+}
+
+type comparedEntity struct {
+	Entity
+	rawData []byte
+}
+
+func (entity *comparedEntity) UnmarshalJSON(b []byte) error {
+	type temp comparedEntity
+	var t temp
+	if err := json.Unmarshal(b, &t); err != nil {
+		return err
+	}
+	*entity = comparedEntity(t)
+	entity.rawData = b
+	return nil
+}
+
+// TestEntityRawDataShared tests that the retained body is shared with the
+// copy a type keeps to compare updates.
+func TestEntityRawDataShared(t *testing.T) {
+	body := `{"@odata.id": "/redfish/v1/Chassis/1", "Id": "1"}`
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {TestResponse(http.StatusOK, body)},
+		},
+		RetainRawData: true,
+	}
+
+	var entity comparedEntity
+	if err := entity.Get(testClient, "/redfish/v1/Chassis/1", &entity); err != nil {
+		t.Fatalf("Error making Get call: %s", err)
+	}
+	if len(entity.rawData) == 0 || &entity.rawData[0] != &entity.RawData()[0] {
+		t.Error("Expected the retained body to be shared")
+	}
+}
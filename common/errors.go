@@ -0,0 +1,296 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package common
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AsError finds the first Redfish error in the chain of err, including the
+// failures of a CollectionError.
+func AsError(err error) (*Error, bool) {
+	var redfishError *Error
+	if errors.As(err, &redfishError) {
+		return redfishError, true
+	}
+	return nil, false
+}
+
+// ErrorStatusCode returns the HTTP status code of the Redfish error in the
+// chain of err, or 0 if there is none.
+func ErrorStatusCode(err error) int {
+	if redfishError, ok := AsError(err); ok {
+		return redfishError.HTTPReturnedStatusCode
+	}
+	return 0
+}
+
+// isError reports whether err contains a Redfish error with the given status
+// code, or with an extended info of one of the given Base message keys.
+func isError(err error, statusCode int, messageKeys ...string) bool {
+	redfishError, ok := AsError(err)
+	if !ok {
+		return false
+	}
+	if redfishError.HTTPReturnedStatusCode == statusCode {
+		return true
+	}
+	for _, key := range messageKeys {
+		if redfishError.HasMessage("Base", key) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNotFound reports whether the resource was not found.
+func IsNotFound(err error) bool {
+	return isError(err, http.StatusNotFound, "ResourceNotFound", "ResourceMissingAtURI")
+}
+
+// IsUnauthorized reports whether the request was not authenticated, for
+// example because the session expired.
+func IsUnauthorized(err error) bool {
+	return isError(err, http.StatusUnauthorized, "NoValidSession")
+}
+
+// IsForbidden reports whether the account lacks the privileges for the
+// request.
+func IsForbidden(err error) bool {
+	return isError(err, http.StatusForbidden, "InsufficientPrivilege")
+}
+
+// IsMethodNotAllowed reports whether the resource does not support the
+// method of the request.
+func IsMethodNotAllowed(err error) bool {
+	return isError(err, http.StatusMethodNotAllowed)
+}
+
+// IsConflict reports whether the request conflicts with the state of the
+// resource, for example because it already exists.
+func IsConflict(err error) bool {
+	return isError(err, http.StatusConflict, "ResourceAlreadyExists")
+}
+
+// IsPreconditionFailed reports whether the ETag sent in If-Match did not
+// match the current ETag of the resource.
+func IsPreconditionFailed(err error) bool {
+	return isError(err, http.StatusPreconditionFailed, "PreconditionFailed")
+}
+
+// IsRequestTooLarge reports whether the request body was larger than the
+// service accepts.
+func IsRequestTooLarge(err error) bool {
+	return isError(err, http.StatusRequestEntityTooLarge)
+}
+
+// IsTooManyRequests reports whether the service throttled the request.
+func IsTooManyRequests(err error) bool {
+	return isError(err, http.StatusTooManyRequests)
+}
+
+// IsServiceUnavailable reports whether the service is temporarily
+// unavailable.
+func IsServiceUnavailable(err error) bool {
+	return isError(err, http.StatusServiceUnavailable, "ServiceTemporarilyUnavailable")
+}
+
+// RegistryPrefix returns the registry prefix of the MessageId, for example
+// 'Base' for 'Base.1.8.PropertyUnknown'.
+func (info *ErrExtendedInfo) RegistryPrefix() string {
+	return strings.SplitN(info.MessageID, ".", 2)[0]
+}
+
+// MessageKey returns the message key of the MessageId, for example
+// 'PropertyUnknown' for 'Base.1.8.PropertyUnknown'.
+func (info *ErrExtendedInfo) MessageKey() string {
+	return info.MessageID[strings.LastIndex(info.MessageID, ".")+1:]
+}
+
+// ExtendedInfosByPrefix returns the extended infos whose MessageId starts
+// with the prefix, for example 'Base.' or 'iDRAC.2.8.SYS'.
+func (e *Error) ExtendedInfosByPrefix(prefix string) []ErrExtendedInfo {
+	var result []ErrExtendedInfo
+	for i := range e.ExtendedInfos {
+		if strings.HasPrefix(e.ExtendedInfos[i].MessageID, prefix) {
+			result = append(result, e.ExtendedInfos[i])
+		}
+	}
+	return result
+}
+
+// HasMessage reports whether an extended info of the error has the message
+// key of the registry, in any version of the registry.
+func (e *Error) HasMessage(registryPrefix, messageKey string) bool {
+	for i := range e.ExtendedInfos {
+		if e.ExtendedInfos[i].RegistryPrefix() == registryPrefix && e.ExtendedInfos[i].MessageKey() == messageKey {
+			return true
+		}
+	}
+	return false
+}
+
+// parseJSONPointer splits an RFC6901 JSON pointer, or its URI fragment form,
+// into its reference tokens.
+func parseJSONPointer(pointer string) []string {
+	pointer = strings.TrimPrefix(pointer, "#")
+	if pointer == "" {
+		return nil
+	}
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens
+}
+
+// jsonFieldByName finds the field of a struct that encoding/json maps the
+// name to, including fields of embedded structs.
+func jsonFieldByName(v reflect.Value, name string) (reflect.Value, string, bool) {
+	var fold reflect.Value
+	var foldName string
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == "-" || field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		if field.Anonymous && tag == "" {
+			embedded := v.Field(i)
+			for embedded.Kind() == reflect.Ptr && !embedded.IsNil() {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if found, fieldName, ok := jsonFieldByName(embedded, name); ok {
+					return found, fieldName, true
+				}
+			}
+			continue
+		}
+
+		jsonName := tag
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+		if jsonName == name {
+			return v.Field(i), field.Name, true
+		}
+		if !fold.IsValid() && strings.EqualFold(jsonName, name) {
+			fold, foldName = v.Field(i), field.Name
+		}
+	}
+	return fold, foldName, fold.IsValid()
+}
+
+// payloadFieldPath maps the tokens of a JSON pointer to the path of the
+// field of the payload it points to, for example 'Boot.BootSourceOverrideTarget'.
+func payloadFieldPath(payload interface{}, tokens []string) (string, bool) {
+	var path strings.Builder
+	v := reflect.ValueOf(payload)
+	for _, token := range tokens {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return "", false
+			}
+			v = v.Elem()
+		}
+
+		switch v.Kind() {
+		case reflect.Struct:
+			field, name, ok := jsonFieldByName(v, token)
+			if !ok {
+				return "", false
+			}
+			if path.Len() > 0 {
+				path.WriteByte('.')
+			}
+			path.WriteString(name)
+			v = field
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return "", false
+			}
+			value := v.MapIndex(reflect.ValueOf(token).Convert(v.Type().Key()))
+			if !value.IsValid() {
+				return "", false
+			}
+			if path.Len() > 0 {
+				path.WriteByte('.')
+			}
+			path.WriteString(token)
+			v = value
+		case reflect.Slice, reflect.Array:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= v.Len() {
+				return "", false
+			}
+			path.WriteString("[" + token + "]")
+			v = v.Index(index)
+		default:
+			return "", false
+		}
+	}
+	return path.String(), true
+}
+
+// RelatedFields maps the RelatedProperties of the extended info, JSON
+// pointers into the request body, to the fields of the request payload that
+// was sent, such as 'Boot.BootSourceOverrideTarget'. The payload may be a
+// struct or a map, as passed to Patch or Post. Pointers that do not match a
+// field of the payload are returned as received.
+func (info *ErrExtendedInfo) RelatedFields(payload interface{}) []string {
+	result := make([]string, 0, len(info.RelatedProperties))
+	for _, pointer := range info.RelatedProperties {
+		if path, ok := payloadFieldPath(payload, parseJSONPointer(pointer)); ok && path != "" {
+			result = append(result, path)
+		} else {
+			result = append(result, pointer)
+		}
+	}
+	return result
+}
+
+// failures returns the failures of the collection in the order of their
+// links, so errors.As finds the same error each time.
+func (cr *CollectionError) failures() []error {
+	links := make([]string, 0, len(cr.Failures))
+	for link := range cr.Failures {
+		links = append(links, link)
+	}
+	sort.Strings(links)
+
+	result := make([]error, 0, len(links))
+	for _, link := range links {
+		result = append(result, cr.Failures[link])
+	}
+	return result
+}
+
+// As finds the first failure of the collection that matches target, so that
+// errors.As can inspect the errors of the individual items.
+func (cr *CollectionError) As(target interface{}) bool {
+	for _, err := range cr.failures() {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Is reports whether any failure of the collection matches target.
+func (cr *CollectionError) Is(target error) bool {
+	for _, err := range cr.failures() {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+var extendedErrorBody = `{
+	"error": {
+		"code": "Base.1.8.GeneralError",
+		"message": "A general error has occurred. See ExtendedInfo for more information.",
+		"@Message.ExtendedInfo": [
+			{
+				"@odata.type": "#Message.v1_0_0.Message",
+				"MessageId": "Base.1.8.PropertyValueNotInList",
+				"Message": "The value 'Fast' for the property BootSourceOverrideTarget is not in the list of acceptable values.",
+				"MessageArgs": ["Fast", "BootSourceOverrideTarget"],
+				"RelatedProperties": ["#/Boot/BootSourceOverrideTarget"],
+				"Severity": "Warning",
+				"Resolution": "Choose a value from the enumeration list."
+			},
+			{
+				"@odata.type": "#Message.v1_0_0.Message",
+				"MessageId": "Base.1.8.PropertyNotWritable",
+				"MessageArgs": ["Id"],
+				"RelatedProperties": ["#/Id", "#/Oem/Vendor~1Name"],
+				"Severity": "Warning"
+			},
+			{
+				"@odata.type": "#Message.v1_0_0.Message",
+				"MessageId": "IDRAC.2.8.SYS403",
+				"Severity": "Critical"
+			}
+		]
+	}
+}`
+
+// TestErrorPredicates tests classifying Redfish errors.
+func TestErrorPredicates(t *testing.T) {
+	notFound := ConstructError(http.StatusNotFound, []byte("{}"))
+	preconditionFailed := ConstructError(http.StatusBadRequest, []byte(`{"error": {"@Message.ExtendedInfo": [{"MessageId": "Base.1.16.0.PreconditionFailed"}]}}`))
+	tooLarge := fmt.Errorf("uploading image: %w", ConstructError(http.StatusRequestEntityTooLarge, []byte("")))
+
+	if !IsNotFound(notFound) || IsNotFound(preconditionFailed) || IsNotFound(errors.New("404")) {
+		t.Error("Invalid IsNotFound")
+	}
+	if !IsPreconditionFailed(preconditionFailed) || !IsPreconditionFailed(ConstructError(http.StatusPreconditionFailed, nil)) {
+		t.Error("Invalid IsPreconditionFailed")
+	}
+	if !IsRequestTooLarge(tooLarge) || ErrorStatusCode(tooLarge) != http.StatusRequestEntityTooLarge {
+		t.Error("Invalid IsRequestTooLarge through a wrapped error")
+	}
+	if !IsUnauthorized(ConstructError(http.StatusUnauthorized, nil)) || IsUnauthorized(notFound) {
+		t.Error("Invalid IsUnauthorized")
+	}
+}
+
+// TestErrorExtendedInfos tests inspecting the extended infos of an error.
+func TestErrorExtendedInfos(t *testing.T) {
+	redfishError, ok := AsError(ConstructError(http.StatusBadRequest, []byte(extendedErrorBody)))
+	if !ok {
+		t.Fatal("Expected a Redfish error")
+	}
+
+	if infos := redfishError.ExtendedInfosByPrefix("Base."); len(infos) != 2 {
+		t.Errorf("Invalid Base extended infos: %+v", infos)
+	}
+	if infos := redfishError.ExtendedInfosByPrefix("IDRAC.2.8.SYS"); len(infos) != 1 || infos[0].MessageKey() != "SYS403" {
+		t.Errorf("Invalid iDRAC extended infos: %+v", infos)
+	}
+	if !redfishError.HasMessage("Base", "PropertyNotWritable") || redfishError.HasMessage("Base", "PropertyUnknown") {
+		t.Error("Invalid HasMessage")
+	}
+
+	type boot struct {
+		Target string `json:"BootSourceOverrideTarget,omitempty"`
+	}
+	type identified struct {
+		ID string `json:"Id"`
+	}
+	payload := struct {
+		identified
+		Boot *boot
+		Oem  map[string]interface{}
+	}{
+		Boot: &boot{Target: "Fast"},
+		Oem:  map[string]interface{}{"Vendor/Name": "x"},
+	}
+
+	if fields := redfishError.ExtendedInfos[0].RelatedFields(&payload); strings.Join(fields, ",") != "Boot.Target" {
+		t.Errorf("Invalid related fields: %v", fields)
+	}
+	if fields := redfishError.ExtendedInfos[1].RelatedFields(payload); strings.Join(fields, ",") != "ID,Oem.Vendor/Name" {
+		t.Errorf("Invalid related fields: %v", fields)
+	}
+	mapPayload := map[string]interface{}{"Boot": map[string]interface{}{"BootSourceOverrideTarget": "Fast"}}
+	if fields := redfishError.ExtendedInfos[0].RelatedFields(mapPayload); strings.Join(fields, ",") != "Boot.BootSourceOverrideTarget" {
+		t.Errorf("Invalid related fields of a map: %v", fields)
+	}
+	if fields := redfishError.ExtendedInfos[1].RelatedFields(mapPayload); strings.Join(fields, ",") != "#/Id,#/Oem/Vendor~1Name" {
+		t.Errorf("Invalid unmatched related fields: %v", fields)
+	}
+}
+
+// TestCollectionErrorAs tests inspecting the failures of a collection.
+func TestCollectionErrorAs(t *testing.T) {
+	collectionError := NewCollectionError()
+	collectionError.Failures["/redfish/v1/Systems/1"] = errors.New("timeout")
+	collectionError.Failures["/redfish/v1/Systems/2"] = ConstructError(http.StatusNotFound, []byte("{}"))
+	err := fmt.Errorf("listing systems: %w", collectionError)
+
+	redfishError, ok := AsError(err)
+	if !ok || redfishError.HTTPReturnedStatusCode != http.StatusNotFound {
+		t.Errorf("Expected the Redfish error of a failure: %v", redfishError)
+	}
+	if !IsNotFound(err) {
+		t.Error("Expected IsNotFound through a collection error")
+	}
+
+	var asCollection *CollectionError
+	if !errors.As(err, &asCollection) || len(asCollection.Failures) != 2 {
+		t.Error("Expected the collection error itself")
+	}
+	if !errors.Is(err, collectionError.Failures["/redfish/v1/Systems/1"]) {
+		t.Error("Expected errors.Is to match a failure")
+	}
+}
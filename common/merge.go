@@ -0,0 +1,166 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// maxMergeRetries is the number of times an update is merged and sent again
+// after its ETag did not match.
+const maxMergeRetries = 3
+
+// PropertyConflict is a property that an update changes and that was also
+// changed on the service since the entity was fetched.
+type PropertyConflict struct {
+	// Property is the RFC6901 JSON pointer of the property, such as
+	// '/Boot/BootSourceOverrideTarget'.
+	Property string
+	// Original is the value of the property when the entity was fetched.
+	Original interface{}
+	// Current is the value of the property on the service.
+	Current interface{}
+	// Requested is the value the update tried to set.
+	Requested interface{}
+}
+
+// ConflictError is returned by updates that could not be merged with the
+// changes made on the service since the entity was fetched. It wraps the
+// precondition failed error of the service.
+type ConflictError struct {
+	// URI is the URI of the entity.
+	URI string
+	// Conflicts are the properties changed both by the update and on the
+	// service.
+	Conflicts []PropertyConflict
+
+	err error
+}
+
+func (e *ConflictError) Error() string {
+	properties := make([]string, 0, len(e.Conflicts))
+	for _, conflict := range e.Conflicts {
+		properties = append(properties, fmt.Sprintf("%s (was %v, now %v, requested %v)",
+			conflict.Property, conflict.Original, conflict.Current, conflict.Requested))
+	}
+	return fmt.Sprintf("update of %s conflicts with changes made on the service: %s", e.URI, strings.Join(properties, ", "))
+}
+
+// Unwrap returns the precondition failed error of the service.
+func (e *ConflictError) Unwrap() error {
+	return e.err
+}
+
+// MergeOnEtagMismatch enables merging updates that the service rejects
+// because the entity changed since it was fetched. The entity is fetched
+// again and, if none of the properties being updated were changed on the
+// service, the update is sent again with the new etag. Otherwise a
+// ConflictError describing the competing changes is returned.
+func (e *Entity) MergeOnEtagMismatch(b bool) {
+	e.mergeOnEtagMismatch = b
+}
+
+// toJSONValue converts a value to its generic JSON representation so that it
+// can be compared to values decoded from the service.
+func toJSONValue(v interface{}) interface{} {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var result interface{}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return v
+	}
+	return result
+}
+
+// escapeJSONPointerToken escapes a reference token of a JSON pointer.
+func escapeJSONPointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// mergeConflicts compares the properties of an update with their original
+// values and the values currently on the service, and returns the
+// properties that were changed on the service to a value other than the
+// requested one.
+func mergeConflicts(pointer string, requested, original map[string]interface{}, current interface{}) []PropertyConflict {
+	currentObject, _ := current.(map[string]interface{})
+
+	names := make([]string, 0, len(requested))
+	for name := range requested {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var conflicts []PropertyConflict
+	for _, name := range names {
+		property := pointer + "/" + escapeJSONPointerToken(name)
+		currentValue, ok := currentObject[name]
+		if !ok {
+			// Nothing on the service would be overwritten.
+			continue
+		}
+
+		nestedRequested, isNested := requested[name].(map[string]interface{})
+		nestedOriginal, _ := original[name].(map[string]interface{})
+		if isNested && nestedOriginal != nil {
+			conflicts = append(conflicts, mergeConflicts(property, nestedRequested, nestedOriginal, currentValue)...)
+			continue
+		}
+
+		originalValue := toJSONValue(original[name])
+		requestedValue := toJSONValue(requested[name])
+		if !reflect.DeepEqual(currentValue, originalValue) && !reflect.DeepEqual(currentValue, requestedValue) {
+			conflicts = append(conflicts, PropertyConflict{
+				Property:  property,
+				Original:  originalValue,
+				Current:   currentValue,
+				Requested: requestedValue,
+			})
+		}
+	}
+	return conflicts
+}
+
+// refetch reads the current state and etag of the entity from the service.
+func (e *Entity) refetch() (map[string]interface{}, error) {
+	resp, err := e.client.Get(e.ODataID)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var current map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&current); err != nil {
+		return nil, err
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		e.etag = etag
+	}
+	return current, nil
+}
+
+// patchWithMerge sends an update and, while the service rejects it because
+// the etag does not match, merges it with the current state of the entity
+// and sends it again.
+func (e *Entity) patchWithMerge(payload, original map[string]interface{}) error {
+	err := e.Patch(e.ODataID, payload)
+	for attempt := 0; attempt < maxMergeRetries && IsPreconditionFailed(err); attempt++ {
+		current, fetchErr := e.refetch()
+		if fetchErr != nil {
+			return fmt.Errorf("%w; fetching %s again to merge the update failed: %v", err, e.ODataID, fetchErr)
+		}
+
+		if conflicts := mergeConflicts("", payload, original, current); len(conflicts) > 0 {
+			return &ConflictError{URI: e.ODataID, Conflicts: conflicts, err: err}
+		}
+		err = e.Patch(e.ODataID, payload)
+	}
+	return err
+}
@@ -0,0 +1,138 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package common
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+type mergeBoot struct {
+	Target  string `json:"BootSourceOverrideTarget"`
+	Enabled string `json:"BootSourceOverrideEnabled"`
+}
+
+type mergeEntity struct {
+	Entity
+	AssetTag     string
+	IndicatorLED string
+	Boot         mergeBoot
+}
+
+var mergeEntityBody = `{
+	"@odata.id": "/redfish/v1/Systems/1",
+	"Id": "1",
+	"AssetTag": "%s",
+	"IndicatorLED": "%s",
+	"Boot": {
+		"BootSourceOverrideTarget": "%s",
+		"BootSourceOverrideEnabled": "Once"
+	}
+}`
+
+func mergeEntityResponse(etag, assetTag, indicatorLED, bootTarget string) *http.Response {
+	resp := TestResponse(http.StatusOK, fmt.Sprintf(mergeEntityBody, assetTag, indicatorLED, bootTarget))
+	resp.Header.Set("ETag", etag)
+	return resp
+}
+
+func preconditionFailedResponse() *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusPreconditionFailed,
+		Body: io.NopCloser(bytes.NewBufferString(`{"error": {"code": "Base.1.16.0.PreconditionFailed",
+			"@Message.ExtendedInfo": [{"MessageId": "Base.1.16.0.PreconditionFailed"}]}}`)),
+		Header: make(http.Header),
+	}
+}
+
+// updateMergeEntity fetches the entity, applies the change and updates it
+// with merging enabled.
+func updateMergeEntity(t *testing.T, testClient *TestClient, change func(*mergeEntity)) error {
+	var original mergeEntity
+	if err := original.Get(testClient, "/redfish/v1/Systems/1", &original); err != nil {
+		t.Fatalf("Error making Get call: %s", err)
+	}
+
+	updated := original
+	updated.MergeOnEtagMismatch(true)
+	change(&updated)
+	return updated.Update(reflect.ValueOf(&original).Elem(), reflect.ValueOf(&updated).Elem(),
+		[]string{"AssetTag", "IndicatorLED", "Boot"})
+}
+
+// TestEntityUpdateMerge tests sending an update again after the entity was
+// changed on the service, when the changes do not conflict.
+func TestEntityUpdateMerge(t *testing.T) {
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {
+				mergeEntityResponse(`W/"1"`, "rack-1", "Off", "None"),
+				mergeEntityResponse(`W/"2"`, "rack-1", "Lit", "None"),
+			},
+			http.MethodPatch: {preconditionFailedResponse(), TestResponse(http.StatusOK, "")},
+		},
+	}
+
+	err := updateMergeEntity(t, testClient, func(entity *mergeEntity) {
+		entity.AssetTag = "rack-2"
+	})
+	if err != nil {
+		t.Fatalf("Error updating entity: %s", err)
+	}
+
+	calls := testClient.CapturedCalls()
+	if len(calls) != 4 {
+		t.Fatalf("Unexpected calls: %+v", calls)
+	}
+	if calls[1].CustomHeaders["If-Match"] != `W/"1"` || calls[3].CustomHeaders["If-Match"] != `W/"2"` {
+		t.Errorf("Unexpected If-Match headers: %v %v", calls[1].CustomHeaders, calls[3].CustomHeaders)
+	}
+	if calls[3].Payload != "map[AssetTag:rack-2]" {
+		t.Errorf("Unexpected merged payload: %s", calls[3].Payload)
+	}
+}
+
+// TestEntityUpdateConflict tests reporting the conflicting changes made on
+// the service.
+func TestEntityUpdateConflict(t *testing.T) {
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {
+				mergeEntityResponse(`W/"1"`, "rack-1", "Off", "None"),
+				mergeEntityResponse(`W/"2"`, "rack-1", "Off", "Hdd"),
+			},
+			http.MethodPatch: {preconditionFailedResponse()},
+		},
+	}
+
+	err := updateMergeEntity(t, testClient, func(entity *mergeEntity) {
+		entity.AssetTag = "rack-2"
+		entity.Boot.Target = "Pxe"
+	})
+
+	var conflictError *ConflictError
+	if !errors.As(err, &conflictError) {
+		t.Fatalf("Expected a conflict error, got %v", err)
+	}
+	if len(conflictError.Conflicts) != 1 {
+		t.Fatalf("Unexpected conflicts: %+v", conflictError.Conflicts)
+	}
+	conflict := conflictError.Conflicts[0]
+	if conflict.Property != "/Boot/BootSourceOverrideTarget" || conflict.Original != "None" ||
+		conflict.Current != "Hdd" || conflict.Requested != "Pxe" {
+		t.Errorf("Unexpected conflict: %+v", conflict)
+	}
+	if !IsPreconditionFailed(err) {
+		t.Error("Expected the conflict error to wrap the precondition failed error")
+	}
+	if len(testClient.CapturedCalls()) != 3 {
+		t.Errorf("Expected the update not to be sent again: %+v", testClient.CapturedCalls())
+	}
+}
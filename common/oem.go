@@ -0,0 +1,312 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// OEMResource is the OEM data of a resource passed to an OEM decoder.
+type OEMResource struct {
+	// Vendor is the vendor the decoder was registered for.
+	Vendor string
+	// Key is the key of the vendor in the Oem object of the resource, such
+	// as 'Dell' or 'Hpe'. It is empty if the resource has no Oem object for
+	// the vendor.
+	Key string
+	// ResourceType is the type of the resource, such as 'Chassis'.
+	ResourceType string
+	// ODataType is the @odata.type of the resource.
+	ODataType string
+	// Oem is the value of the vendor key in the Oem object of the resource.
+	Oem json.RawMessage
+	// Entity is the resource. Vendor extensions outside the Oem object can
+	// be decoded with Entity.As, which reuses the body OEMExtension read
+	// instead of fetching it again.
+	Entity *Entity
+}
+
+// OEMDecoder decodes the OEM extension of a resource into a vendor-specific
+// type.
+type OEMDecoder func(resource *OEMResource) (interface{}, error)
+
+// VendorProvider is implemented by clients that know the vendor of the
+// service they are connected to.
+type VendorProvider interface {
+	// Vendor returns the vendor of the service, such as 'Dell'.
+	Vendor() string
+}
+
+type oemRegistry struct {
+	mu sync.RWMutex
+	// decoders maps vendor and resource type to a decoder.
+	decoders map[string]map[string]OEMDecoder
+	// vendors maps lowercase Oem keys and service vendors to a vendor.
+	vendors map[string]string
+}
+
+var oemDecoders = &oemRegistry{
+	decoders: make(map[string]map[string]OEMDecoder),
+	vendors:  make(map[string]string),
+}
+
+// RegisterOEMVendor registers the keys a vendor uses in Oem objects and the
+// Vendor property of the service root, if they differ from the vendor name.
+func RegisterOEMVendor(vendor string, keys ...string) {
+	oemDecoders.mu.Lock()
+	defer oemDecoders.mu.Unlock()
+
+	oemDecoders.vendors[strings.ToLower(vendor)] = vendor
+	for _, key := range keys {
+		oemDecoders.vendors[strings.ToLower(key)] = vendor
+	}
+}
+
+// RegisterOEMDecoder registers the decoder of the OEM extension of a vendor
+// for a resource type, such as 'Chassis'. OEM packages register their
+// decoders when they are imported. A later registration replaces an earlier
+// one.
+func RegisterOEMDecoder(vendor, resourceType string, decoder OEMDecoder) {
+	oemDecoders.mu.Lock()
+	defer oemDecoders.mu.Unlock()
+
+	if _, ok := oemDecoders.vendors[strings.ToLower(vendor)]; !ok {
+		oemDecoders.vendors[strings.ToLower(vendor)] = vendor
+	}
+	if oemDecoders.decoders[vendor] == nil {
+		oemDecoders.decoders[vendor] = make(map[string]OEMDecoder)
+	}
+	oemDecoders.decoders[vendor][resourceType] = decoder
+}
+
+// lookup finds the vendor registered for an Oem key or service vendor and
+// its decoder for a resource type.
+func (registry *oemRegistry) lookup(key, resourceType string) (string, OEMDecoder) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	vendor, ok := registry.vendors[strings.ToLower(key)]
+	if !ok {
+		return "", nil
+	}
+	return vendor, registry.decoders[vendor][resourceType]
+}
+
+// ResourceTypeFromODataType returns the resource type of an @odata.type, such
+// as 'Chassis' for '#Chassis.v1_20_0.Chassis'.
+func ResourceTypeFromODataType(odataType string) string {
+	odataType = strings.TrimPrefix(odataType, "#")
+	if i := strings.LastIndex(odataType, "."); i >= 0 {
+		return odataType[i+1:]
+	}
+	return odataType
+}
+
+// OEMNotRegisteredError is returned if no OEM decoder is registered for the
+// vendors and type of a resource.
+type OEMNotRegisteredError struct {
+	// Vendors are the vendors found in the Oem object of the resource and
+	// the vendor of the service.
+	Vendors []string
+	// ResourceType is the type of the resource.
+	ResourceType string
+}
+
+func (e *OEMNotRegisteredError) Error() string {
+	if len(e.Vendors) == 0 {
+		return fmt.Sprintf("%s resource has no OEM extension", e.ResourceType)
+	}
+	return fmt.Sprintf("no OEM decoder is registered for %s resources of %s", e.ResourceType, strings.Join(e.Vendors, ", "))
+}
+
+// oemBody is the part of a resource that holds OEM extensions.
+type oemBody struct {
+	ODataType string `json:"@odata.type"`
+	Oem       map[string]json.RawMessage
+	Actions   struct {
+		Oem map[string]json.RawMessage
+	}
+}
+
+// OEMExtension decodes the OEM extension of the entity with the decoder
+// registered for its vendor and resource type. The vendor is taken from the
+// keys of the Oem object, or from the service if the client knows its
+// vendor. An *OEMNotRegisteredError is returned if no decoder is registered.
+func (e *Entity) OEMExtension() (interface{}, error) {
+	b, err := e.body()
+	if err != nil {
+		return nil, err
+	}
+	var body oemBody
+	if err := json.Unmarshal(b, &body); err != nil {
+		return nil, err
+	}
+	// The decoder is handed the body, so it is fetched at most once
+	entity := *e
+	entity.rawData = b
+	resourceType := ResourceTypeFromODataType(body.ODataType)
+
+	keys := make([]string, 0, len(body.Oem)+1)
+	for key := range body.Oem {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if provider, ok := e.client.(VendorProvider); ok && provider.Vendor() != "" {
+		keys = append(keys, provider.Vendor())
+	}
+
+	for _, key := range keys {
+		vendor, decoder := oemDecoders.lookup(key, resourceType)
+		if decoder == nil {
+			continue
+		}
+		resource := &OEMResource{
+			Vendor:       vendor,
+			ResourceType: resourceType,
+			ODataType:    body.ODataType,
+			Entity:       &entity,
+		}
+		if oem, ok := body.Oem[key]; ok {
+			resource.Key = key
+			resource.Oem = oem
+		}
+		return decoder(resource)
+	}
+
+	return nil, &OEMNotRegisteredError{Vendors: keys, ResourceType: resourceType}
+}
+
+// OEMAction is a vendor action advertised in the Oem object of the actions of
+// a resource.
+type OEMAction struct {
+	// Name is the name of the action, such as '#DellManager.ResetToDefaults'.
+	Name string
+	// Vendor is the key of the vendor the action is nested under, if any.
+	Vendor string
+	// Target is the URI to invoke the action.
+	Target string
+	// ActionInfo is the URI of the ActionInfo resource of the action.
+	ActionInfo string
+	// AllowableValues are the values allowed for each parameter, if the
+	// service advertises them.
+	AllowableValues map[string][]string
+
+	entity *Entity
+}
+
+const allowableValuesSuffix = "@Redfish.AllowableValues"
+
+func parseOEMAction(name, vendor string, b json.RawMessage, entity *Entity) (*OEMAction, error) {
+	var properties map[string]json.RawMessage
+	if err := json.Unmarshal(b, &properties); err != nil {
+		return nil, err
+	}
+
+	action := &OEMAction{Name: name, Vendor: vendor, entity: entity}
+	for key, value := range properties {
+		var err error
+		switch {
+		case key == "target":
+			err = json.Unmarshal(value, &action.Target)
+		case key == "@Redfish.ActionInfo":
+			err = json.Unmarshal(value, &action.ActionInfo)
+		case strings.HasSuffix(key, allowableValuesSuffix):
+			var values []string
+			err = json.Unmarshal(value, &values)
+			if action.AllowableValues == nil {
+				action.AllowableValues = make(map[string][]string)
+			}
+			action.AllowableValues[strings.TrimSuffix(key, allowableValuesSuffix)] = values
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid OEM action %s: %w", name, err)
+		}
+	}
+	return action, nil
+}
+
+// OEMActions returns the vendor actions of the entity, ordered by name.
+// Actions can be directly in the Oem object of the actions, or nested under
+// the key of a vendor.
+func (e *Entity) OEMActions() ([]*OEMAction, error) {
+	var body oemBody
+	if err := e.As(&body); err != nil {
+		return nil, err
+	}
+
+	var result []*OEMAction
+	for key, value := range body.Actions.Oem {
+		if strings.HasPrefix(key, "#") {
+			action, err := parseOEMAction(key, "", value, e)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, action)
+			continue
+		}
+
+		var nested map[string]json.RawMessage
+		if err := json.Unmarshal(value, &nested); err != nil {
+			continue
+		}
+		for name, actionValue := range nested {
+			if !strings.HasPrefix(name, "#") {
+				continue
+			}
+			action, err := parseOEMAction(name, key, actionValue, e)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, action)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// OEMAction finds a vendor action of the entity by its full name, such as
+// '#DellManager.ResetToDefaults', or by the part after the last period, such
+// as 'ResetToDefaults'.
+func (e *Entity) OEMAction(name string) (*OEMAction, error) {
+	actions, err := e.OEMActions()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, action := range actions {
+		if action.Name == name || action.Name[strings.LastIndex(action.Name, ".")+1:] == name {
+			return action, nil
+		}
+	}
+	return nil, fmt.Errorf("OEM action %s is not supported by %s", name, e.ODataID)
+}
+
+// Invoke invokes the vendor action with the given parameters.
+func (action *OEMAction) Invoke(parameters interface{}) error {
+	resp, err := action.InvokeWithResponse(parameters)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// InvokeWithResponse invokes the vendor action and returns the response,
+// which the caller must close.
+func (action *OEMAction) InvokeWithResponse(parameters interface{}) (*http.Response, error) {
+	if action.Target == "" {
+		return nil, errors.New("OEM action " + action.Name + " has no target")
+	}
+	if parameters == nil {
+		parameters = struct{}{}
+	}
+	return action.entity.PostWithResponse(action.Target, parameters)
+}
@@ -0,0 +1,158 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+var oemChassisBody = `{
+		"@odata.id": "/redfish/v1/Chassis/1",
+		"@odata.type": "#Chassis.v1_20_0.Chassis",
+		"Id": "1",
+		"Oem": {
+			"Acme": {"Rack": "R12"}
+		},
+		"Actions": {
+			"Oem": {
+				"#AcmeChassis.Blink": {
+					"target": "/redfish/v1/Chassis/1/Actions/Oem/AcmeChassis.Blink",
+					"Mode@Redfish.AllowableValues": ["Slow", "Fast"]
+				},
+				"Acme": {
+					"#AcmeChassis.Reseat": {
+						"target": "/redfish/v1/Chassis/1/Actions/Oem/Acme/AcmeChassis.Reseat",
+						"@Redfish.ActionInfo": "/redfish/v1/Chassis/1/ReseatActionInfo"
+					}
+				}
+			}
+		}
+	}`
+
+type acmeChassis struct {
+	Rack string
+}
+
+func getOEMEntity(t *testing.T, body string) (*rawDataEntity, *TestClient) {
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {TestResponse(http.StatusOK, body)},
+		},
+		RetainRawData: true,
+	}
+
+	var entity rawDataEntity
+	if err := entity.Get(testClient, "/redfish/v1/Chassis/1", &entity); err != nil {
+		t.Fatalf("Error making Get call: %s", err)
+	}
+	return &entity, testClient
+}
+
+// TestEntityOEMExtension tests decoding OEM extensions with registered decoders.
+func TestEntityOEMExtension(t *testing.T) {
+	RegisterOEMVendor("Acme", "AcmeCorp")
+	RegisterOEMDecoder("Acme", "Chassis", func(resource *OEMResource) (interface{}, error) {
+		var result acmeChassis
+		err := json.Unmarshal(resource.Oem, &result)
+		return &result, err
+	})
+
+	entity, _ := getOEMEntity(t, oemChassisBody)
+	extension, err := entity.OEMExtension()
+	if err != nil {
+		t.Fatalf("Error making OEMExtension call: %s", err)
+	}
+	if chassis, ok := extension.(*acmeChassis); !ok || chassis.Rack != "R12" {
+		t.Errorf("Unexpected OEM extension: %#v", extension)
+	}
+
+	// No decoder is registered for other resource types
+	entity, _ = getOEMEntity(t, strings.Replace(oemChassisBody, "Chassis.v1_20_0.Chassis", "Manager.v1_10_0.Manager", 1))
+	_, err = entity.OEMExtension()
+	var notRegistered *OEMNotRegisteredError
+	if !errors.As(err, &notRegistered) || notRegistered.ResourceType != "Manager" {
+		t.Errorf("Expected an OEMNotRegisteredError, got: %v", err)
+	}
+}
+
+// TestEntityOEMActions tests listing and invoking OEM actions.
+func TestEntityOEMActions(t *testing.T) {
+	entity, testClient := getOEMEntity(t, oemChassisBody)
+
+	actions, err := entity.OEMActions()
+	if err != nil {
+		t.Fatalf("Error making OEMActions call: %s", err)
+	}
+	if len(actions) != 2 {
+		t.Fatalf("Expected 2 OEM actions, got: %d", len(actions))
+	}
+	if actions[0].Name != "#AcmeChassis.Blink" || actions[0].Vendor != "" ||
+		strings.Join(actions[0].AllowableValues["Mode"], ",") != "Slow,Fast" {
+		t.Errorf("Unexpected action: %+v", actions[0])
+	}
+	if actions[1].Vendor != "Acme" || actions[1].ActionInfo != "/redfish/v1/Chassis/1/ReseatActionInfo" {
+		t.Errorf("Unexpected action: %+v", actions[1])
+	}
+
+	action, err := entity.OEMAction("Reseat")
+	if err != nil {
+		t.Fatalf("Error making OEMAction call: %s", err)
+	}
+	if err := action.Invoke(nil); err != nil {
+		t.Fatalf("Error invoking action: %s", err)
+	}
+
+	calls := testClient.CapturedCalls()
+	if calls[len(calls)-1].URL != "/redfish/v1/Chassis/1/Actions/Oem/Acme/AcmeChassis.Reseat" {
+		t.Errorf("Unexpected call: %+v", calls[len(calls)-1])
+	}
+
+	if _, err := entity.OEMAction("Unknown"); err == nil {
+		t.Error("Expected an unknown OEM action to fail")
+	}
+}
+
+// TestEntityOEMExtensionFetchedOnce tests that a decoder reading the entity
+// does not fetch it again if the client does not retain raw data.
+func TestEntityOEMExtensionFetchedOnce(t *testing.T) {
+	RegisterOEMDecoder("Acme", "Thermal", func(resource *OEMResource) (interface{}, error) {
+		var result struct {
+			Oem struct {
+				Acme acmeChassis
+			}
+		}
+		err := resource.Entity.As(&result)
+		return &result.Oem.Acme, err
+	})
+
+	body := strings.Replace(oemChassisBody, "Chassis.v1_20_0.Chassis", "Thermal.v1_7_0.Thermal", 1)
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {TestResponse(http.StatusOK, body), TestResponse(http.StatusOK, body)},
+		},
+	}
+	var entity rawDataEntity
+	if err := entity.Get(testClient, "/redfish/v1/Chassis/1/Thermal", &entity); err != nil {
+		t.Fatalf("Error making Get call: %s", err)
+	}
+
+	extension, err := entity.OEMExtension()
+	if err != nil {
+		t.Fatalf("Error making OEMExtension call: %s", err)
+	}
+	if thermal, ok := extension.(*acmeChassis); !ok || thermal.Rack != "R12" {
+		t.Errorf("Unexpected OEM extension: %#v", extension)
+	}
+	if calls := testClient.CapturedCalls(); len(calls) != 2 {
+		t.Errorf("Expected the entity to be fetched once more, captured: %v", calls)
+	}
+	if entity.RawData() != nil {
+		t.Error("Expected no raw data to be retained")
+	}
+}
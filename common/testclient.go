@@ -39,6 +39,25 @@ type TestClient struct {
 	// For each key it is possible to define a list of
 	// returns (in the order they should be returned).
 	CustomReturnForActions map[string][]interface{}
+	// RetainRawData makes entities fetched through the client keep their raw
+	// JSON body.
+	RetainRawData bool
+}
+
+// TestResponse creates a response with the status code and body, to use in
+// the CustomReturnForActions of a TestClient.
+func TestResponse(statusCode int, body string) *http.Response {
+	return &http.Response{
+		StatusCode:    statusCode,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Header:        make(http.Header),
+	}
+}
+
+// RetainsRawData reports whether entities keep their raw JSON body.
+func (c *TestClient) RetainsRawData() bool {
+	return c.RetainRawData
 }
 
 // CapturedCalls gets all calls that were made through this instance
@@ -68,6 +68,21 @@ func (l Links) ToStrings() []string {
 	return result
 }
 
+// IDRef is a reference to another resource as it is sent in request
+// payloads.
+type IDRef struct {
+	ODataID string `json:"@odata.id"`
+}
+
+// IDRefs converts a set of URIs to references to the resources.
+func IDRefs(uris []string) []IDRef {
+	refs := make([]IDRef, 0, len(uris))
+	for _, uri := range uris {
+		refs = append(refs, IDRef{ODataID: uri})
+	}
+	return refs
+}
+
 // LinksCollection contains links to other entities
 type LinksCollection struct {
 	ODataCount int   `json:"@odata.count"`
@@ -677,7 +692,6 @@ func (e *Error) Error() string {
 }
 
 // ErrExtendedInfo is for redfish ExtendedInfo error response
-// TODO: support RelatedProperties
 type ErrExtendedInfo struct {
 	// Indicating a specific error or message (not to be confused with the HTTP status code).
 	// This code can be used to access a detailed message from a message registry.
@@ -692,6 +706,9 @@ type ErrExtendedInfo struct {
 	Severity string
 	// An optional string describing recommended action(s) to take to resolve the error.
 	Resolution string
+	// An optional array of RFC6901 JSON pointers indicating the properties of the
+	// request body described by the message.
+	RelatedProperties []string `json:",omitempty"`
 }
 
 // ActionTarget is contains the target endpoint for object Actions.
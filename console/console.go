@@ -0,0 +1,305 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+// Package console connects to the serial console and command shell services
+// that a Redfish manager advertises. It is a separate module so the SSH and
+// WebSocket dependencies are only needed by programs that use it.
+package console
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/stmcginnis/gofish"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+// ErrResizeNotSupported is returned by Resize if the transport of a console
+// cannot change the size of the terminal.
+var ErrResizeNotSupported = errors.New("console: resize is not supported by this transport")
+
+// Transport is the protocol used to connect to a console.
+type Transport string
+
+const (
+	// SSHTransport connects with the Secure Shell protocol.
+	SSHTransport Transport = "SSH"
+	// WebSocketTransport connects to an OEM WebSocket endpoint that streams
+	// the console, such as the one of OpenBMC.
+	WebSocketTransport Transport = "WebSocket"
+)
+
+// Console is a connection to a console.
+type Console interface {
+	io.ReadWriteCloser
+	// Resize changes the size of the terminal in characters.
+	Resize(width, height int) error
+	// Transport returns the protocol of the connection.
+	Transport() Transport
+}
+
+// Options configures how a console is opened.
+type Options struct {
+	// Username and Password are the credentials used to log in.
+	Username string
+	Password string
+	// Host is the address of the manager. If empty, the host of the Redfish
+	// endpoint of the client is used.
+	Host string
+	// Port is the SSH port. If zero, the port advertised by the service is
+	// used, falling back to 22.
+	Port int
+	// HostKeyCallback verifies the SSH host key of the manager. It is required
+	// for SSH connections; use ssh.InsecureIgnoreHostKey to skip verification.
+	HostKeyCallback ssh.HostKeyCallback
+	// Transports are the transports to try, in order of preference. If empty,
+	// SSH is tried before WebSocket.
+	Transports []Transport
+	// WebSocketURL is the URL of an OEM WebSocket console, such as
+	// 'wss://bmc/console0'. If empty, a 'WebSocketURI' property in the OEM
+	// section of the manager's serial interfaces is used if advertised.
+	WebSocketURL string
+	// TLSConfig is the TLS configuration of WebSocket connections.
+	TLSConfig *tls.Config
+	// Header contains additional headers for WebSocket connections, such as
+	// an X-Auth-Token header of an existing session.
+	Header http.Header
+	// Term, Width and Height describe the terminal requested over SSH. The
+	// defaults are 'vt100', 80 and 24.
+	Term   string
+	Width  int
+	Height int
+	// Timeout limits the time to establish a connection.
+	Timeout time.Duration
+}
+
+// target describes a console service to connect to.
+type target struct {
+	manager *redfish.Manager
+	// service is the name of the console service for error messages.
+	service    string
+	transports map[Transport]bool
+	// port is the SSH port advertised for the console, or zero.
+	port int
+	// command is the command that enters the console from a shared CLI.
+	command string
+	// shell requests an interactive shell rather than a command.
+	shell bool
+}
+
+// OpenSerialConsole opens the serial console service of a manager.
+func OpenSerialConsole(manager *redfish.Manager, options *Options) (Console, error) {
+	if !manager.SerialConsole.ServiceEnabled {
+		return nil, fmt.Errorf("serial console is not enabled on manager %s", manager.ID)
+	}
+
+	t := &target{manager: manager, service: "serial console", transports: make(map[Transport]bool), shell: true}
+	for _, connectType := range manager.SerialConsole.ConnectTypesSupported {
+		t.addConnectType(string(connectType))
+	}
+	return t.open(options)
+}
+
+// OpenCommandShell opens the command shell service of a manager.
+func OpenCommandShell(manager *redfish.Manager, options *Options) (Console, error) {
+	if !manager.CommandShell.ServiceEnabled {
+		return nil, fmt.Errorf("command shell is not enabled on manager %s", manager.ID)
+	}
+
+	t := &target{manager: manager, service: "command shell", transports: make(map[Transport]bool), shell: true}
+	for _, connectType := range manager.CommandShell.ConnectTypesSupported {
+		t.addConnectType(string(connectType))
+	}
+	return t.open(options)
+}
+
+// OpenSystemSerialConsole opens the serial console of a computer system
+// through the manager that manages it. The SSH port and console entry command
+// advertised by the system take precedence over those of the manager.
+func OpenSystemSerialConsole(system *redfish.ComputerSystem, options *Options) (Console, error) {
+	managers, err := system.ManagedBy()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, manager := range managers {
+		if !manager.SerialConsole.ServiceEnabled && !system.SerialConsole.SSH.ServiceEnabled {
+			continue
+		}
+
+		t := &target{manager: manager, service: "serial console", transports: make(map[Transport]bool)}
+		for _, connectType := range manager.SerialConsole.ConnectTypesSupported {
+			t.addConnectType(string(connectType))
+		}
+
+		if ssh := system.SerialConsole.SSH; ssh.ServiceEnabled {
+			t.transports[SSHTransport] = true
+			t.port = ssh.Port
+			if ssh.SharedWithManagerCLI || ssh.ConsoleEntryCommand != "" {
+				t.command = ssh.ConsoleEntryCommand
+			}
+		}
+		t.shell = t.command == ""
+
+		return t.open(options)
+	}
+
+	return nil, fmt.Errorf("no manager of system %s provides a serial console", system.ID)
+}
+
+// OpenSystemSerialConsoleByID opens the serial console of the computer system
+// with the given ID.
+func OpenSystemSerialConsoleByID(service *gofish.Service, systemID string, options *Options) (Console, error) {
+	systems, err := service.Systems()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, system := range systems {
+		if system.ID == systemID {
+			return OpenSystemSerialConsole(system, options)
+		}
+	}
+	return nil, fmt.Errorf("system %s not found", systemID)
+}
+
+func (t *target) addConnectType(connectType string) {
+	switch connectType {
+	case string(redfish.SSHSerialConnectTypesSupported):
+		t.transports[SSHTransport] = true
+	case string(redfish.OemSerialConnectTypesSupported):
+		t.transports[WebSocketTransport] = true
+	}
+}
+
+// open connects with the first transport that the service supports and that
+// succeeds.
+func (t *target) open(options *Options) (Console, error) {
+	if options == nil {
+		options = &Options{}
+	}
+
+	transports := options.Transports
+	if len(transports) == 0 {
+		transports = []Transport{SSHTransport, WebSocketTransport}
+	}
+
+	var errs []string
+	for _, transport := range transports {
+		if !t.transports[transport] && !(transport == WebSocketTransport && options.WebSocketURL != "") {
+			continue
+		}
+
+		var console Console
+		var err error
+		switch transport {
+		case SSHTransport:
+			console, err = t.openSSH(options)
+		case WebSocketTransport:
+			console, err = t.openWebSocket(options)
+		default:
+			err = fmt.Errorf("unknown transport %s", transport)
+		}
+		if err == nil {
+			return console, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %s", transport, err))
+	}
+
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("manager %s does not support a %s transport that can be used", t.manager.ID, t.service)
+	}
+	return nil, fmt.Errorf("unable to open %s of manager %s: %s", t.service, t.manager.ID, strings.Join(errs, "; "))
+}
+
+// host returns the address of the manager. If keepPort is set, the port of
+// the Redfish endpoint is kept, for transports served from the same address
+// as the Redfish service.
+func (t *target) host(options *Options, keepPort bool) (string, error) {
+	if options.Host != "" {
+		return options.Host, nil
+	}
+
+	if client, ok := t.manager.GetClient().(interface{ Endpoint() string }); ok {
+		if endpoint, err := url.Parse(client.Endpoint()); err == nil && endpoint.Hostname() != "" {
+			if keepPort {
+				return endpoint.Host, nil
+			}
+			return endpoint.Hostname(), nil
+		}
+	}
+	return "", errors.New("the host of the manager is unknown")
+}
+
+// sshPort returns the SSH port of the console.
+func (t *target) sshPort(options *Options) int {
+	if options.Port != 0 {
+		return options.Port
+	}
+	if t.port != 0 {
+		return t.port
+	}
+	if protocol, err := t.manager.NetworkProtocol(); err == nil && protocol.SSH.Port != 0 {
+		return int(protocol.SSH.Port)
+	}
+	return 22
+}
+
+// webSocketURL returns the URL of the OEM WebSocket console.
+func (t *target) webSocketURL(options *Options) (string, error) {
+	if options.WebSocketURL != "" {
+		return options.WebSocketURL, nil
+	}
+
+	interfaces, err := t.manager.SerialInterfaces()
+	if err != nil {
+		return "", err
+	}
+	for _, serialInterface := range interfaces {
+		if uri := findWebSocketURI(serialInterface.Oem); uri != "" {
+			return uri, nil
+		}
+	}
+	return "", errors.New("no WebSocket console is advertised")
+}
+
+// findWebSocketURI looks for a WebSocketURI property in OEM data.
+func findWebSocketURI(oem json.RawMessage) string {
+	var values map[string]interface{}
+	if err := json.Unmarshal(oem, &values); err != nil {
+		return ""
+	}
+
+	var find func(values map[string]interface{}) string
+	find = func(values map[string]interface{}) string {
+		for key, value := range values {
+			switch v := value.(type) {
+			case string:
+				if strings.EqualFold(key, "WebSocketURI") {
+					return v
+				}
+			case map[string]interface{}:
+				if uri := find(v); uri != "" {
+					return uri
+				}
+			}
+		}
+		return ""
+	}
+	return find(values)
+}
+
+func (options *Options) dialer() *net.Dialer {
+	return &net.Dialer{Timeout: options.Timeout}
+}
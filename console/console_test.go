@@ -0,0 +1,355 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package console
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/net/websocket"
+
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+// sshServer is an in-process SSH server that echoes the input of a session.
+type sshServer struct {
+	listener net.Listener
+	hostKey  ssh.Signer
+
+	mu       sync.Mutex
+	requests []string
+	sizes    [][2]uint32
+}
+
+func newSSHServer(t *testing.T) *sshServer {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating host key: %s", err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("Error creating signer: %s", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error listening: %s", err)
+	}
+
+	server := &sshServer{listener: listener, hostKey: signer}
+	go server.serve()
+	return server
+}
+
+func (server *sshServer) port() int {
+	return server.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (server *sshServer) record(request string, size ...[2]uint32) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.requests = append(server.requests, request)
+	server.sizes = append(server.sizes, size...)
+}
+
+func (server *sshServer) serve() {
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == "admin" && string(password) == "secret" {
+				return nil, nil
+			}
+			return nil, io.ErrUnexpectedEOF
+		},
+	}
+	config.AddHostKey(server.hostKey)
+
+	for {
+		conn, err := server.listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			_, channels, requests, err := ssh.NewServerConn(conn, config)
+			if err != nil {
+				return
+			}
+			go ssh.DiscardRequests(requests)
+			for newChannel := range channels {
+				channel, requests, err := newChannel.Accept()
+				if err != nil {
+					return
+				}
+				go server.handleSession(channel, requests)
+			}
+		}()
+	}
+}
+
+func (server *sshServer) handleSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	for request := range requests {
+		switch request.Type {
+		case "pty-req":
+			// string term, uint32 width, uint32 height
+			termLength := binary.BigEndian.Uint32(request.Payload)
+			size := request.Payload[4+termLength:]
+			server.record(request.Type, [2]uint32{binary.BigEndian.Uint32(size), binary.BigEndian.Uint32(size[4:])})
+		case "window-change":
+			server.record(request.Type, [2]uint32{binary.BigEndian.Uint32(request.Payload), binary.BigEndian.Uint32(request.Payload[4:])})
+		case "exec":
+			server.record(request.Type + " " + string(request.Payload[4:]))
+			go func() { _, _ = io.Copy(channel, channel) }()
+		case "shell":
+			server.record(request.Type)
+			go func() { _, _ = io.Copy(channel, channel) }()
+		}
+		if request.WantReply {
+			_ = request.Reply(true, nil)
+		}
+	}
+}
+
+func (server *sshServer) recorded() ([]string, [][2]uint32) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	return append([]string{}, server.requests...), append([][2]uint32{}, server.sizes...)
+}
+
+func decodeManager(t *testing.T, body string) *redfish.Manager {
+	var manager redfish.Manager
+	if err := json.NewDecoder(strings.NewReader(body)).Decode(&manager); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	return &manager
+}
+
+func echo(t *testing.T, console Console, message string) {
+	if _, err := console.Write([]byte(message)); err != nil {
+		t.Fatalf("Error writing to console: %s", err)
+	}
+
+	buf := make([]byte, len(message))
+	if _, err := io.ReadFull(console, buf); err != nil {
+		t.Fatalf("Error reading from console: %s", err)
+	}
+	if string(buf) != message {
+		t.Errorf("Unexpected console output: %q", buf)
+	}
+}
+
+// TestOpenSerialConsoleSSH tests opening the serial console of a manager over SSH.
+func TestOpenSerialConsoleSSH(t *testing.T) {
+	server := newSSHServer(t)
+	defer server.listener.Close()
+
+	manager := decodeManager(t, `{
+		"@odata.id": "/redfish/v1/Managers/BMC",
+		"Id": "BMC",
+		"SerialConsole": {"ServiceEnabled": true, "ConnectTypesSupported": ["SSH", "IPMI"]}
+	}`)
+
+	console, err := OpenSerialConsole(manager, &Options{
+		Username:        "admin",
+		Password:        "secret",
+		Host:            "127.0.0.1",
+		Port:            server.port(),
+		HostKeyCallback: ssh.FixedHostKey(server.hostKey.PublicKey()),
+		Width:           120,
+		Height:          40,
+	})
+	if err != nil {
+		t.Fatalf("Error opening serial console: %s", err)
+	}
+	defer console.Close()
+
+	if console.Transport() != SSHTransport {
+		t.Errorf("Unexpected transport: %s", console.Transport())
+	}
+
+	echo(t, console, "hello\n")
+
+	if err := console.Resize(100, 30); err != nil {
+		t.Errorf("Error resizing console: %s", err)
+	}
+	// The window change has no reply, so make a round trip before checking
+	echo(t, console, "sync\n")
+
+	requests, sizes := server.recorded()
+	if len(requests) != 3 || requests[0] != "pty-req" || requests[1] != "shell" || requests[2] != "window-change" {
+		t.Errorf("Unexpected requests: %v", requests)
+	}
+	if len(sizes) != 2 || sizes[0] != [2]uint32{120, 40} || sizes[1] != [2]uint32{100, 30} {
+		t.Errorf("Unexpected terminal sizes: %v", sizes)
+	}
+
+	if _, err := OpenSerialConsole(manager, &Options{Host: "127.0.0.1", Port: server.port()}); err == nil {
+		t.Error("Expected SSH without a host key callback to fail")
+	}
+}
+
+// TestOpenSystemSerialConsole tests entering a system console shared with the manager CLI.
+func TestOpenSystemSerialConsole(t *testing.T) {
+	server := newSSHServer(t)
+	defer server.listener.Close()
+
+	managerBody := `{
+		"@odata.id": "/redfish/v1/Managers/BMC",
+		"Id": "BMC",
+		"SerialConsole": {"ServiceEnabled": true, "ConnectTypesSupported": ["SSH"]}
+	}`
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {&http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(managerBody)),
+				Header:     make(http.Header),
+			}},
+		},
+	}
+
+	var system redfish.ComputerSystem
+	err := json.NewDecoder(strings.NewReader(`{
+		"@odata.id": "/redfish/v1/Systems/1",
+		"Id": "1",
+		"SerialConsole": {
+			"SSH": {
+				"ServiceEnabled": true,
+				"Port": ` + strconv.Itoa(server.port()) + `,
+				"SharedWithManagerCLI": true,
+				"ConsoleEntryCommand": "console 1"
+			}
+		},
+		"Links": {"ManagedBy": [{"@odata.id": "/redfish/v1/Managers/BMC"}]}
+	}`)).Decode(&system)
+	if err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	system.SetClient(testClient)
+
+	console, err := OpenSystemSerialConsole(&system, &Options{
+		Username:        "admin",
+		Password:        "secret",
+		Host:            "127.0.0.1",
+		HostKeyCallback: ssh.FixedHostKey(server.hostKey.PublicKey()),
+	})
+	if err != nil {
+		t.Fatalf("Error opening system serial console: %s", err)
+	}
+	defer console.Close()
+
+	echo(t, console, "login\n")
+
+	requests, _ := server.recorded()
+	if len(requests) != 2 || requests[1] != "exec console 1" {
+		t.Errorf("Unexpected requests: %v", requests)
+	}
+}
+
+// TestOpenSerialConsoleWebSocket tests falling back to an OEM WebSocket console.
+func TestOpenSerialConsoleWebSocket(t *testing.T) {
+	var authorization string
+	ts := httptest.NewServer(websocket.Handler(func(conn *websocket.Conn) {
+		authorization = conn.Request().Header.Get("Authorization")
+		_, _ = io.Copy(conn, conn)
+	}))
+	defer ts.Close()
+
+	manager := decodeManager(t, `{
+		"@odata.id": "/redfish/v1/Managers/BMC",
+		"Id": "BMC",
+		"SerialConsole": {"ServiceEnabled": true, "ConnectTypesSupported": ["Oem"]}
+	}`)
+
+	console, err := OpenSerialConsole(manager, &Options{
+		Username:     "admin",
+		Password:     "secret",
+		WebSocketURL: "ws" + strings.TrimPrefix(ts.URL, "http") + "/console0",
+	})
+	if err != nil {
+		t.Fatalf("Error opening serial console: %s", err)
+	}
+	defer console.Close()
+
+	if console.Transport() != WebSocketTransport {
+		t.Errorf("Unexpected transport: %s", console.Transport())
+	}
+
+	echo(t, console, "hello\n")
+
+	if err := console.Resize(80, 24); err != ErrResizeNotSupported {
+		t.Errorf("Expected resize not to be supported, got: %v", err)
+	}
+
+	if authorization != "Basic YWRtaW46c2VjcmV0" {
+		t.Errorf("Unexpected authorization header: %s", authorization)
+	}
+}
+
+// endpointClient is a test client with a Redfish endpoint.
+type endpointClient struct {
+	*common.TestClient
+	endpoint string
+}
+
+func (c *endpointClient) Endpoint() string {
+	return c.endpoint
+}
+
+// TestOpenSerialConsoleWebSocketRelative tests a relative WebSocket URI is
+// opened on the address of the Redfish endpoint, including its port.
+func TestOpenSerialConsoleWebSocketRelative(t *testing.T) {
+	var path string
+	ts := httptest.NewTLSServer(websocket.Handler(func(conn *websocket.Conn) {
+		path = conn.Request().URL.Path
+		_, _ = io.Copy(conn, conn)
+	}))
+	defer ts.Close()
+
+	manager := decodeManager(t, `{
+		"@odata.id": "/redfish/v1/Managers/BMC",
+		"Id": "BMC",
+		"SerialConsole": {"ServiceEnabled": true, "ConnectTypesSupported": ["Oem"]}
+	}`)
+	manager.SetClient(&endpointClient{TestClient: &common.TestClient{}, endpoint: ts.URL})
+
+	console, err := OpenSerialConsole(manager, &Options{
+		WebSocketURL: "/console0",
+		TLSConfig:    ts.Client().Transport.(*http.Transport).TLSClientConfig,
+	})
+	if err != nil {
+		t.Fatalf("Error opening serial console: %s", err)
+	}
+	defer console.Close()
+
+	echo(t, console, "hello\n")
+
+	if path != "/console0" {
+		t.Errorf("Unexpected WebSocket path: %s", path)
+	}
+}
+
+// TestFindWebSocketURI tests discovering a WebSocket console in OEM data.
+func TestFindWebSocketURI(t *testing.T) {
+	uri := findWebSocketURI(json.RawMessage(`{"Vendor": {"Console": {"WebSocketUri": "/console0"}}}`))
+	if uri != "/console0" {
+		t.Errorf("Unexpected WebSocket URI: %s", uri)
+	}
+
+	if uri := findWebSocketURI(nil); uri != "" {
+		t.Errorf("Unexpected WebSocket URI: %s", uri)
+	}
+}
@@ -0,0 +1,11 @@
+module github.com/stmcginnis/gofish/console
+
+go 1.20
+
+require (
+	github.com/stmcginnis/gofish v0.20.0
+	golang.org/x/crypto v0.31.0
+	golang.org/x/net v0.33.0
+)
+
+require golang.org/x/sys v0.28.0 // indirect
@@ -0,0 +1,136 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package console
+
+import (
+	"errors"
+	"io"
+	"net"
+	"strconv"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// sshConsole is a console connected over SSH.
+type sshConsole struct {
+	client  *ssh.Client
+	session *ssh.Session
+	stdin   io.WriteCloser
+	output  *io.PipeReader
+}
+
+func (t *target) openSSH(options *Options) (Console, error) {
+	if options.HostKeyCallback == nil {
+		return nil, errors.New("a host key callback is required for SSH connections")
+	}
+
+	host, err := t.host(options, false)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := ssh.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(t.sshPort(options))), &ssh.ClientConfig{
+		User: options.Username,
+		Auth: []ssh.AuthMethod{
+			ssh.Password(options.Password),
+			ssh.KeyboardInteractive(func(_, _ string, questions []string, _ []bool) ([]string, error) {
+				answers := make([]string, len(questions))
+				for i := range answers {
+					answers[i] = options.Password
+				}
+				return answers, nil
+			}),
+		},
+		HostKeyCallback: options.HostKeyCallback,
+		Timeout:         options.Timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	console, err := startSSHSession(client, t, options)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return console, nil
+}
+
+func startSSHSession(client *ssh.Client, t *target, options *Options) (*sshConsole, error) {
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, err
+	}
+
+	term, width, height := options.Term, options.Width, options.Height
+	if term == "" {
+		term = "vt100"
+	}
+	if width == 0 || height == 0 {
+		width, height = 80, 24
+	}
+	if err := session.RequestPty(term, height, width, ssh.TerminalModes{ssh.ECHO: 1}); err != nil {
+		session.Close()
+		return nil, err
+	}
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+
+	// Errors of the console are part of what a user sees on a terminal
+	output, writer := io.Pipe()
+	session.Stdout = writer
+	session.Stderr = writer
+
+	if t.shell {
+		err = session.Shell()
+	} else {
+		err = session.Start(t.command)
+	}
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+
+	go func() {
+		err := session.Wait()
+		if err == nil {
+			err = io.EOF
+		}
+		writer.CloseWithError(err)
+	}()
+
+	return &sshConsole{client: client, session: session, stdin: stdin, output: output}, nil
+}
+
+// Read reads output of the console.
+func (console *sshConsole) Read(p []byte) (int, error) {
+	return console.output.Read(p)
+}
+
+// Write sends input to the console.
+func (console *sshConsole) Write(p []byte) (int, error) {
+	return console.stdin.Write(p)
+}
+
+// Close ends the session and closes the connection.
+func (console *sshConsole) Close() error {
+	console.session.Close()
+	console.output.Close()
+	return console.client.Close()
+}
+
+// Resize changes the window size of the terminal.
+func (console *sshConsole) Resize(width, height int) error {
+	return console.session.WindowChange(height, width)
+}
+
+// Transport returns SSHTransport.
+func (console *sshConsole) Transport() Transport {
+	return SSHTransport
+}
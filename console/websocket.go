@@ -0,0 +1,77 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package console
+
+import (
+	"encoding/base64"
+	"net/url"
+
+	"golang.org/x/net/websocket"
+)
+
+// webSocketConsole is a console connected to an OEM WebSocket endpoint. These
+// endpoints stream the raw serial line, so the terminal size cannot be set.
+type webSocketConsole struct {
+	*websocket.Conn
+}
+
+func (t *target) openWebSocket(options *Options) (Console, error) {
+	location, err := t.webSocketURL(options)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	if !endpoint.IsAbs() {
+		host, err := t.host(options, true)
+		if err != nil {
+			return nil, err
+		}
+		endpoint.Scheme = "wss"
+		endpoint.Host = host
+	}
+
+	origin := &url.URL{Scheme: "https", Host: endpoint.Host}
+	if endpoint.Scheme == "ws" {
+		origin.Scheme = "http"
+	}
+
+	config, err := websocket.NewConfig(endpoint.String(), origin.String())
+	if err != nil {
+		return nil, err
+	}
+	config.TlsConfig = options.TLSConfig
+	config.Dialer = options.dialer()
+	for name, values := range options.Header {
+		for _, value := range values {
+			config.Header.Add(name, value)
+		}
+	}
+	if options.Username != "" && config.Header.Get("Authorization") == "" && config.Header.Get("X-Auth-Token") == "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte(options.Username + ":" + options.Password))
+		config.Header.Set("Authorization", "Basic "+credentials)
+	}
+
+	conn, err := websocket.DialConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	conn.PayloadType = websocket.BinaryFrame
+
+	return &webSocketConsole{Conn: conn}, nil
+}
+
+// Resize returns ErrResizeNotSupported since serial lines have no window size.
+func (console *webSocketConsole) Resize(width, height int) error {
+	return ErrResizeNotSupported
+}
+
+// Transport returns WebSocketTransport.
+func (console *webSocketConsole) Transport() Transport {
+	return WebSocketTransport
+}
@@ -9,11 +9,23 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/stmcginnis/gofish/common"
 	"github.com/stmcginnis/gofish/redfish"
 )
 
 const eventContext string = "root"
 
+func init() {
+	common.RegisterOEMDecoder("Dell", "EventService", func(resource *common.OEMResource) (interface{}, error) {
+		var eventservice redfish.EventService
+		if err := resource.Entity.As(&eventservice); err != nil {
+			return nil, err
+		}
+		eventservice.SetClient(resource.Entity.GetClient())
+		return FromEventService(&eventservice)
+	})
+}
+
 type PayloadType struct {
 	Destination string                           `json:"Destination"`
 	EventTypes  string                           `json:"EventTypes"`
@@ -0,0 +1,243 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package dell
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+// JobState is the state of an iDRAC job.
+type JobState string
+
+const (
+	// NewJobState means the job was created.
+	NewJobState JobState = "New"
+	// ScheduledJobState means the job is scheduled to run.
+	ScheduledJobState JobState = "Scheduled"
+	// SchedulingJobState means the job is being scheduled.
+	SchedulingJobState JobState = "Scheduling"
+	// RunningJobState means the job is running.
+	RunningJobState JobState = "Running"
+	// DownloadingJobState means the job is downloading a payload.
+	DownloadingJobState JobState = "Downloading"
+	// DownloadedJobState means the payload of the job was downloaded.
+	DownloadedJobState JobState = "Downloaded"
+	// WaitingJobState means the job is waiting for another job.
+	WaitingJobState JobState = "Waiting"
+	// ReadyForExecutionJobState means the job runs at the next reboot.
+	ReadyForExecutionJobState JobState = "ReadyForExecution"
+	// PausedJobState means the job is paused.
+	PausedJobState JobState = "Paused"
+	// RebootPendingJobState means the job waits for the host to reboot.
+	RebootPendingJobState JobState = "RebootPending"
+	// RebootCompletedJobState means the host rebooted for the job.
+	RebootCompletedJobState JobState = "RebootCompleted"
+	// RebootFailedJobState means the host failed to reboot for the job.
+	RebootFailedJobState JobState = "RebootFailed"
+	// PendingActivationJobState means the job waits to be activated.
+	PendingActivationJobState JobState = "PendingActivation"
+	// CompletedJobState means the job completed successfully.
+	CompletedJobState JobState = "Completed"
+	// CompletedWithErrorsJobState means the job completed with errors.
+	CompletedWithErrorsJobState JobState = "CompletedWithErrors"
+	// FailedJobState means the job failed.
+	FailedJobState JobState = "Failed"
+)
+
+// IsFinished reports whether a job in this state will not change state again.
+func (state JobState) IsFinished() bool {
+	return state == CompletedJobState || state == CompletedWithErrorsJobState ||
+		state == FailedJobState || state == RebootFailedJobState
+}
+
+// Job is an entry in the job queue of an iDRAC.
+type Job struct {
+	common.Entity
+
+	// ODataType is the odata type.
+	ODataType string `json:"@odata.type"`
+	// CompletionTime is the time the job completed.
+	CompletionTime string
+	// Description is the description of the job.
+	Description string
+	// EndTime is the time by which the job must complete, or 'TIME_NA'.
+	EndTime string
+	// JobState is the state of the job.
+	JobState JobState
+	// JobType is the type of the job, such as 'ExportConfiguration'.
+	JobType string
+	// Message is the last message of the job.
+	Message string
+	// MessageArgs are the arguments of the message.
+	MessageArgs []string
+	// MessageID is the ID of the message in the message registry.
+	MessageID string `json:"MessageId"`
+	// PercentComplete is the progress of the job.
+	PercentComplete int
+	// StartTime is the time the job is scheduled to start, or 'TIME_NOW'.
+	StartTime string
+	// TargetSettingsURI is the URI of the settings the job applies.
+	TargetSettingsURI string
+}
+
+// GetJob will get a Job instance from the service.
+func GetJob(c common.Client, uri string) (*Job, error) {
+	var job Job
+	return &job, job.Get(c, uri, &job)
+}
+
+// ListReferencedJobs gets the collection of Job from
+// a provided reference.
+func ListReferencedJobs(c common.Client, link string) ([]*Job, error) {
+	var result []*Job
+	if link == "" {
+		return result, nil
+	}
+
+	type GetResult struct {
+		Item  *Job
+		Link  string
+		Error error
+	}
+
+	ch := make(chan GetResult)
+	collectionError := common.NewCollectionError()
+	get := func(link string) {
+		job, err := GetJob(c, link)
+		ch <- GetResult{Item: job, Link: link, Error: err}
+	}
+
+	go func() {
+		err := common.CollectList(get, c, link)
+		if err != nil {
+			collectionError.Failures[link] = err
+		}
+		close(ch)
+	}()
+
+	for r := range ch {
+		if r.Error != nil {
+			collectionError.Failures[r.Link] = r.Error
+		} else {
+			result = append(result, r.Item)
+		}
+	}
+
+	if collectionError.Empty() {
+		return result, nil
+	}
+
+	return result, collectionError
+}
+
+// The job IDs that clear the whole job queue.
+const (
+	clearAllJobID      = "JID_CLEARALL"
+	clearAllForceJobID = "JID_CLEARALL_FORCE"
+)
+
+// JobService is the DellJobService of an iDRAC, which manages its job queue.
+type JobService struct {
+	common.Entity
+
+	// ODataType is the odata type.
+	ODataType string `json:"@odata.type"`
+	// Description provides a description of this resource.
+	Description string
+
+	deleteJobQueueTarget string
+}
+
+// UnmarshalJSON unmarshals a JobService object from the raw JSON.
+func (jobservice *JobService) UnmarshalJSON(b []byte) error {
+	type temp JobService
+	var t struct {
+		temp
+		Actions struct {
+			DeleteJobQueue common.ActionTarget `json:"#DellJobService.DeleteJobQueue"`
+		}
+	}
+
+	err := json.Unmarshal(b, &t)
+	if err != nil {
+		return err
+	}
+
+	*jobservice = JobService(t.temp)
+	jobservice.deleteJobQueueTarget = t.Actions.DeleteJobQueue.Target
+
+	return nil
+}
+
+// GetJobService will get a JobService instance from the service.
+func GetJobService(c common.Client, uri string) (*JobService, error) {
+	var jobservice JobService
+	return &jobservice, jobservice.Get(c, uri, &jobservice)
+}
+
+func (jobservice *JobService) deleteJobQueue(jobID string) error {
+	if jobservice.deleteJobQueueTarget == "" {
+		return errors.New("DeleteJobQueue is not supported by this job service")
+	}
+
+	return jobservice.Post(jobservice.deleteJobQueueTarget, struct {
+		JobID string
+	}{JobID: jobID})
+}
+
+// DeleteJob deletes a job from the job queue.
+func (jobservice *JobService) DeleteJob(jobID string) error {
+	if jobID == "" {
+		return errors.New("job ID should not be empty")
+	}
+	return jobservice.deleteJobQueue(jobID)
+}
+
+// ClearJobQueue deletes all jobs from the job queue. If force is true, the
+// running jobs are stopped and the pending configuration is cleared as well,
+// which restarts the Lifecycle Controller.
+func (jobservice *JobService) ClearJobQueue(force bool) error {
+	if force {
+		return jobservice.deleteJobQueue(clearAllForceJobID)
+	}
+	return jobservice.deleteJobQueue(clearAllJobID)
+}
+
+// defaultJobPollInterval is the interval between reads of a job if none is
+// configured.
+const defaultJobPollInterval = 5 * time.Second
+
+// waitForJob polls a job until it is finished. The job is returned along with
+// an error if it did not complete successfully before the timeout.
+func waitForJob(c common.Client, uri string, timeout, interval time.Duration) (*Job, error) {
+	if interval <= 0 {
+		interval = defaultJobPollInterval
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		job, err := GetJob(c, uri)
+		if err != nil {
+			return nil, err
+		}
+
+		if job.JobState.IsFinished() {
+			if job.JobState != CompletedJobState {
+				return job, fmt.Errorf("job %s is %s: %s", job.ID, job.JobState, job.Message)
+			}
+			return job, nil
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			return job, fmt.Errorf("job %s is %s after %s", job.ID, job.JobState, timeout)
+		}
+		time.Sleep(interval)
+	}
+}
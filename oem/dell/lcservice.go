@@ -0,0 +1,148 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package dell
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+// readyStatus is the status of a remote service that accepts requests.
+const readyStatus = "Ready"
+
+// RemoteServicesAPIStatus is the status of the remote services of the
+// Lifecycle Controller.
+type RemoteServicesAPIStatus struct {
+	// LCStatus is the status of the Lifecycle Controller, such as 'Ready' or
+	// 'InUse'.
+	LCStatus string
+	// RTStatus is the status of the real-time configuration service.
+	RTStatus string
+	// ServerStatus is the status of the host, such as 'OutOfPOST' or
+	// 'InPOST'.
+	ServerStatus string
+	// Status is the overall status of the remote services, such as 'Ready'
+	// or 'NotReady'.
+	Status string
+	// TelemetryStatus is the status of the telemetry service.
+	TelemetryStatus string
+}
+
+// Ready reports whether the Lifecycle Controller accepts configuration
+// requests.
+func (status *RemoteServicesAPIStatus) Ready() bool {
+	return status.Status == readyStatus && status.LCStatus == readyStatus
+}
+
+// LCNotReadyError is returned if the Lifecycle Controller does not accept
+// requests.
+type LCNotReadyError struct {
+	// Status is the last status read from the Lifecycle Controller.
+	Status *RemoteServicesAPIStatus
+}
+
+func (e *LCNotReadyError) Error() string {
+	return fmt.Sprintf("lifecycle controller is not ready: status %s, LC status %s, server status %s",
+		e.Status.Status, e.Status.LCStatus, e.Status.ServerStatus)
+}
+
+// LCService is the DellLCService of an iDRAC, which reports the state of the
+// Lifecycle Controller.
+type LCService struct {
+	common.Entity
+
+	// ODataType is the odata type.
+	ODataType string `json:"@odata.type"`
+	// Description provides a description of this resource.
+	Description string
+
+	getRemoteServicesAPIStatusTarget string
+}
+
+// UnmarshalJSON unmarshals a LCService object from the raw JSON.
+func (lcservice *LCService) UnmarshalJSON(b []byte) error {
+	type temp LCService
+	var t struct {
+		temp
+		Actions struct {
+			GetRemoteServicesAPIStatus common.ActionTarget `json:"#DellLCService.GetRemoteServicesAPIStatus"`
+		}
+	}
+
+	err := json.Unmarshal(b, &t)
+	if err != nil {
+		return err
+	}
+
+	*lcservice = LCService(t.temp)
+	lcservice.getRemoteServicesAPIStatusTarget = t.Actions.GetRemoteServicesAPIStatus.Target
+
+	return nil
+}
+
+// GetLCService will get a LCService instance from the service.
+func GetLCService(c common.Client, uri string) (*LCService, error) {
+	var lcservice LCService
+	return &lcservice, lcservice.Get(c, uri, &lcservice)
+}
+
+// RemoteServicesAPIStatus gets the status of the remote services.
+func (lcservice *LCService) RemoteServicesAPIStatus() (*RemoteServicesAPIStatus, error) {
+	if lcservice.getRemoteServicesAPIStatusTarget == "" {
+		return nil, errors.New("GetRemoteServicesAPIStatus is not supported by this LC service")
+	}
+
+	resp, err := lcservice.PostWithResponse(lcservice.getRemoteServicesAPIStatusTarget, struct{}{})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var status RemoteServicesAPIStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// CheckReady returns an *LCNotReadyError if the Lifecycle Controller does not
+// accept requests.
+func (lcservice *LCService) CheckReady() error {
+	status, err := lcservice.RemoteServicesAPIStatus()
+	if err != nil {
+		return err
+	}
+	if !status.Ready() {
+		return &LCNotReadyError{Status: status}
+	}
+	return nil
+}
+
+// WaitForReady polls the remote services until the Lifecycle Controller
+// accepts requests. An *LCNotReadyError is returned if it is not ready
+// before the timeout.
+func (lcservice *LCService) WaitForReady(timeout, interval time.Duration) error {
+	if interval <= 0 {
+		interval = defaultJobPollInterval
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := lcservice.CheckReady()
+		var notReady *LCNotReadyError
+		if !errors.As(err, &notReady) {
+			return err
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			return err
+		}
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,315 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package dell
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+func init() {
+	common.RegisterOEMDecoder("Dell", "Manager", func(resource *common.OEMResource) (interface{}, error) {
+		var manager redfish.Manager
+		if err := resource.Entity.As(&manager); err != nil {
+			return nil, err
+		}
+		manager.SetClient(resource.Entity.GetClient())
+		return FromManager(&manager)
+	})
+}
+
+// SCPFormat is the file format of a Server Configuration Profile.
+type SCPFormat string
+
+const (
+	// XMLSCPFormat exports the profile as XML.
+	XMLSCPFormat SCPFormat = "XML"
+	// JSONSCPFormat exports the profile as JSON.
+	JSONSCPFormat SCPFormat = "JSON"
+)
+
+// ShareType is the location a Server Configuration Profile is exported to or
+// imported from.
+type ShareType string
+
+const (
+	// LocalShareType keeps the profile on the iDRAC, where it is read from
+	// the task of the export or passed in the import buffer.
+	LocalShareType ShareType = "Local"
+	// NFSShareType uses an NFS share.
+	NFSShareType ShareType = "NFS"
+	// CIFSShareType uses a CIFS share.
+	CIFSShareType ShareType = "CIFS"
+	// HTTPShareType uses an HTTP server.
+	HTTPShareType ShareType = "HTTP"
+	// HTTPSShareType uses an HTTPS server.
+	HTTPSShareType ShareType = "HTTPS"
+)
+
+// ShutdownType is how the host is shut down to apply an imported profile.
+type ShutdownType string
+
+const (
+	// GracefulShutdownType shuts the host down gracefully.
+	GracefulShutdownType ShutdownType = "Graceful"
+	// ForcedShutdownType powers the host off.
+	ForcedShutdownType ShutdownType = "Forced"
+	// NoRebootShutdownType applies the profile at the next reboot.
+	NoRebootShutdownType ShutdownType = "NoReboot"
+)
+
+// ShareParameters are the location and components of a Server Configuration
+// Profile.
+type ShareParameters struct {
+	// Target is the components to export or import, such as 'ALL' or
+	// 'BIOS,NIC'.
+	Target string `json:",omitempty"`
+	// ShareType is the type of the share. The default is Local.
+	ShareType ShareType `json:",omitempty"`
+	// IPAddress is the address of the share.
+	IPAddress string `json:",omitempty"`
+	// ShareName is the name of the share or the path on the server.
+	ShareName string `json:",omitempty"`
+	// FileName is the name of the profile file.
+	FileName string `json:",omitempty"`
+	// Username is the user name for the share.
+	Username string `json:",omitempty"`
+	// Password is the password for the share.
+	Password string `json:",omitempty"`
+	// IgnoreCertWarning ignores certificate errors of HTTPS shares, if set
+	// to 'On'.
+	IgnoreCertWarning string `json:",omitempty"`
+}
+
+// ExportSystemConfigurationRequest are the parameters of a Server
+// Configuration Profile export.
+type ExportSystemConfigurationRequest struct {
+	// ExportFormat is the format of the profile.
+	ExportFormat SCPFormat
+	// ExportUse is the intended use of the profile, such as 'Default',
+	// 'Clone' or 'Replace'.
+	ExportUse string `json:",omitempty"`
+	// IncludeInExport are extra attributes to export, such as
+	// 'IncludeReadOnly'.
+	IncludeInExport string `json:",omitempty"`
+	// ShareParameters are the location and components of the export.
+	ShareParameters ShareParameters
+}
+
+// ImportSystemConfigurationRequest are the parameters of a Server
+// Configuration Profile import.
+type ImportSystemConfigurationRequest struct {
+	// ImportBuffer is the profile to import if the share type is Local.
+	ImportBuffer string `json:",omitempty"`
+	// ShareParameters are the location and components of the import.
+	ShareParameters ShareParameters
+	// ShutdownType is how the host is shut down to apply the profile.
+	ShutdownType ShutdownType `json:",omitempty"`
+	// HostPowerState is the power state of the host after the import, 'On'
+	// or 'Off'.
+	HostPowerState string `json:",omitempty"`
+	// TimeToWait is the time in seconds to wait for a graceful shutdown
+	// before the import fails.
+	TimeToWait int `json:",omitempty"`
+}
+
+// Manager is the Dell-specific handler for an iDRAC manager.
+type Manager struct {
+	redfish.Manager
+
+	exportSystemConfigurationTarget string
+	importSystemConfigurationTarget string
+	jobService                      string
+	lcService                       string
+	jobs                            string
+}
+
+// FromManager converts a standard Manager object to the OEM implementation.
+func FromManager(manager *redfish.Manager) (*Manager, error) {
+	m := &Manager{Manager: *manager}
+
+	if len(manager.OemActions) > 0 {
+		var actions struct {
+			ExportSystemConfiguration common.ActionTarget `json:"#OemManager.ExportSystemConfiguration"`
+			ImportSystemConfiguration common.ActionTarget `json:"#OemManager.ImportSystemConfiguration"`
+		}
+		if err := json.Unmarshal(manager.OemActions, &actions); err != nil {
+			return nil, err
+		}
+		m.exportSystemConfigurationTarget = actions.ExportSystemConfiguration.Target
+		m.importSystemConfigurationTarget = actions.ImportSystemConfiguration.Target
+	}
+
+	if len(manager.OemLinks) > 0 {
+		var links struct {
+			Dell struct {
+				DellJobService common.Link
+				DellLCService  common.Link
+				Jobs           common.Link
+			}
+		}
+		if err := json.Unmarshal(manager.OemLinks, &links); err != nil {
+			return nil, err
+		}
+		m.jobService = links.Dell.DellJobService.String()
+		m.lcService = links.Dell.DellLCService.String()
+		m.jobs = links.Dell.Jobs.String()
+	}
+
+	m.SetClient(manager.GetClient())
+	return m, nil
+}
+
+// JobService gets the service managing the job queue of the iDRAC.
+func (manager *Manager) JobService() (*JobService, error) {
+	if manager.jobService == "" {
+		return nil, errors.New("DellJobService is not supported by this manager")
+	}
+	return GetJobService(manager.GetClient(), manager.jobService)
+}
+
+// LCService gets the service reporting the state of the Lifecycle
+// Controller.
+func (manager *Manager) LCService() (*LCService, error) {
+	if manager.lcService == "" {
+		return nil, errors.New("DellLCService is not supported by this manager")
+	}
+	return GetLCService(manager.GetClient(), manager.lcService)
+}
+
+// Jobs gets the jobs in the job queue of the iDRAC.
+func (manager *Manager) Jobs() ([]*Job, error) {
+	return ListReferencedJobs(manager.GetClient(), manager.jobs)
+}
+
+// Job gets a job in the job queue by its ID, such as 'JID_123456789012'.
+func (manager *Manager) Job(jobID string) (*Job, error) {
+	if manager.jobs == "" {
+		return nil, errors.New("job queue is not supported by this manager")
+	}
+	return GetJob(manager.GetClient(), strings.TrimSuffix(manager.jobs, "/")+"/"+jobID)
+}
+
+// WaitForJob polls a job until it is finished. The job is returned along with
+// an error if it did not complete successfully before the timeout. The ID of
+// the task returned by the Server Configuration Profile actions is the ID of
+// their job.
+func (manager *Manager) WaitForJob(jobID string, timeout, interval time.Duration) (*Job, error) {
+	if manager.jobs == "" {
+		return nil, errors.New("job queue is not supported by this manager")
+	}
+	return waitForJob(manager.GetClient(), strings.TrimSuffix(manager.jobs, "/")+"/"+jobID, timeout, interval)
+}
+
+// checkLCReady returns an *LCNotReadyError if the Lifecycle Controller does
+// not accept requests. Managers without a DellLCService are assumed to be
+// ready.
+func (manager *Manager) checkLCReady() error {
+	if manager.lcService == "" {
+		return nil
+	}
+
+	lcservice, err := manager.LCService()
+	if err != nil {
+		return err
+	}
+	return lcservice.CheckReady()
+}
+
+// postSCPAction checks that the Lifecycle Controller is ready and posts a
+// Server Configuration Profile action, returning the task tracking it.
+func (manager *Manager) postSCPAction(name, target string, payload interface{}) (*redfish.Task, error) {
+	if target == "" {
+		return nil, fmt.Errorf("%s is not supported by this manager", name)
+	}
+	if err := manager.checkLCReady(); err != nil {
+		return nil, err
+	}
+
+	resp, err := manager.PostWithResponse(target, payload)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	task, err := redfish.TaskFromResponse(manager.GetClient(), resp)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, fmt.Errorf("%s did not return a task", name)
+	}
+	return task, nil
+}
+
+// ExportSystemConfiguration exports the Server Configuration Profile of the
+// server. The export runs as a job; its progress can be followed with the
+// returned task or WaitForJob. For a local export, the profile is read with
+// ExportedSystemConfiguration once the job completed.
+func (manager *Manager) ExportSystemConfiguration(request *ExportSystemConfigurationRequest) (*redfish.Task, error) {
+	if request == nil || request.ExportFormat == "" {
+		return nil, errors.New("export format should not be empty")
+	}
+	return manager.postSCPAction("ExportSystemConfiguration", manager.exportSystemConfigurationTarget, request)
+}
+
+// ExportedSystemConfiguration reads the Server Configuration Profile of a
+// local export from its task. An error is returned if the export is not
+// finished.
+func (manager *Manager) ExportedSystemConfiguration(task *redfish.Task) ([]byte, error) {
+	uri := task.TaskMonitor
+	if uri == "" {
+		uri = task.ODataID
+	}
+
+	resp, err := manager.GetClient().Get(uri)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Until the export is finished, the task itself is returned
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var current struct {
+			ODataType string `json:"@odata.type"`
+			TaskState redfish.TaskState
+			Messages  []common.Message
+		}
+		if err := json.Unmarshal(trimmed, &current); err == nil && strings.HasPrefix(current.ODataType, "#Task.") {
+			if len(current.Messages) > 0 {
+				return nil, fmt.Errorf("system configuration export %s is %s: %s", task.ID, current.TaskState, current.Messages[0].Message)
+			}
+			return nil, fmt.Errorf("system configuration export %s is %s", task.ID, current.TaskState)
+		}
+	}
+	return body, nil
+}
+
+// ImportSystemConfiguration imports a Server Configuration Profile. The
+// import runs as a job; its progress can be followed with the returned task
+// or WaitForJob.
+func (manager *Manager) ImportSystemConfiguration(request *ImportSystemConfigurationRequest) (*redfish.Task, error) {
+	if request == nil {
+		return nil, errors.New("import request should not be empty")
+	}
+	if request.ImportBuffer == "" && (request.ShareParameters.ShareType == "" || request.ShareParameters.ShareType == LocalShareType) {
+		return nil, errors.New("import buffer should not be empty for a local import")
+	}
+	return manager.postSCPAction("ImportSystemConfiguration", manager.importSystemConfigurationTarget, request)
+}
@@ -0,0 +1,372 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package dell
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stmcginnis/gofish"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+const managerBody = `{
+    "@odata.context": "/redfish/v1/$metadata#Manager.Manager",
+    "@odata.id": "/redfish/v1/Managers/iDRAC.Embedded.1",
+    "@odata.type": "#Manager.v1_9_0.Manager",
+    "Actions": {
+        "#Manager.Reset": {
+            "ResetType@Redfish.AllowableValues": [
+                "GracefulRestart"
+            ],
+            "target": "/redfish/v1/Managers/iDRAC.Embedded.1/Actions/Manager.Reset"
+        },
+        "Oem": {
+            "#OemManager.ExportSystemConfiguration": {
+                "ExportFormat@Redfish.AllowableValues": [
+                    "XML",
+                    "JSON"
+                ],
+                "ExportUse@Redfish.AllowableValues": [
+                    "Default",
+                    "Clone",
+                    "Replace"
+                ],
+                "IncludeInExport@Redfish.AllowableValues": [
+                    "Default",
+                    "IncludeReadOnly",
+                    "IncludePasswordHashValues",
+                    "IncludeReadOnly,IncludePasswordHashValues"
+                ],
+                "ShareParameters": {
+                    "Target@Redfish.AllowableValues": [
+                        "ALL",
+                        "IDRAC",
+                        "BIOS",
+                        "NIC",
+                        "RAID"
+                    ]
+                },
+                "target": "/redfish/v1/Managers/iDRAC.Embedded.1/Actions/Oem/EID_674_Manager.ExportSystemConfiguration"
+            },
+            "#OemManager.ImportSystemConfiguration": {
+                "HostPowerState@Redfish.AllowableValues": [
+                    "On",
+                    "Off"
+                ],
+                "ShutdownType@Redfish.AllowableValues": [
+                    "Graceful",
+                    "Forced",
+                    "NoReboot"
+                ],
+                "target": "/redfish/v1/Managers/iDRAC.Embedded.1/Actions/Oem/EID_674_Manager.ImportSystemConfiguration"
+            }
+        }
+    },
+    "Description": "BMC",
+    "FirmwareVersion": "4.40.00.00",
+    "Id": "iDRAC.Embedded.1",
+    "Links": {
+        "Oem": {
+            "Dell": {
+                "@odata.type": "#DellOem.v1_1_0.DellOemLinks",
+                "DellJobService": {
+                    "@odata.id": "/redfish/v1/Dell/Managers/iDRAC.Embedded.1/DellJobService"
+                },
+                "DellLCService": {
+                    "@odata.id": "/redfish/v1/Dell/Managers/iDRAC.Embedded.1/DellLCService"
+                },
+                "Jobs": {
+                    "@odata.id": "/redfish/v1/Managers/iDRAC.Embedded.1/Jobs"
+                }
+            }
+        }
+    },
+    "ManagerType": "BMC",
+    "Model": "14G Monolithic",
+    "Name": "Manager",
+    "PowerState": "On",
+    "Status": {
+        "Health": "OK",
+        "State": "Enabled"
+    }
+}`
+
+const jobServiceBody = `{
+    "@odata.context": "/redfish/v1/$metadata#DellJobService.DellJobService",
+    "@odata.id": "/redfish/v1/Dell/Managers/iDRAC.Embedded.1/DellJobService",
+    "@odata.type": "#DellJobService.v1_1_0.DellJobService",
+    "Actions": {
+        "#DellJobService.DeleteJobQueue": {
+            "target": "/redfish/v1/Dell/Managers/iDRAC.Embedded.1/DellJobService/Actions/DellJobService.DeleteJobQueue"
+        },
+        "#DellJobService.SetupJobQueue": {
+            "target": "/redfish/v1/Dell/Managers/iDRAC.Embedded.1/DellJobService/Actions/DellJobService.SetupJobQueue"
+        }
+    },
+    "Description": "The DellJobService resource provides some actions to support Job management functionality.",
+    "Id": "Job Service",
+    "Name": "DellJobService"
+}`
+
+const lcServiceBody = `{
+    "@odata.context": "/redfish/v1/$metadata#DellLCService.DellLCService",
+    "@odata.id": "/redfish/v1/Dell/Managers/iDRAC.Embedded.1/DellLCService",
+    "@odata.type": "#DellLCService.v1_3_0.DellLCService",
+    "Actions": {
+        "#DellLCService.GetRemoteServicesAPIStatus": {
+            "target": "/redfish/v1/Dell/Managers/iDRAC.Embedded.1/DellLCService/Actions/DellLCService.GetRemoteServicesAPIStatus"
+        }
+    },
+    "Description": "The DellLCService resource provides some actions to support Lifecycle Controller functionality.",
+    "Id": "DellLCService",
+    "Name": "DellLCService"
+}`
+
+const jobsBody = `{
+    "@odata.context": "/redfish/v1/$metadata#DellJobCollection.DellJobCollection",
+    "@odata.id": "/redfish/v1/Managers/iDRAC.Embedded.1/Jobs",
+    "@odata.type": "#DellJobCollection.DellJobCollection",
+    "Members": [
+        {
+            "@odata.id": "/redfish/v1/Managers/iDRAC.Embedded.1/Jobs/JID_878659984891"
+        }
+    ],
+    "Members@odata.count": 1,
+    "Name": "JobQueue"
+}`
+
+const jobBody = `{
+    "@odata.context": "/redfish/v1/$metadata#DellJob.DellJob",
+    "@odata.id": "/redfish/v1/Managers/iDRAC.Embedded.1/Jobs/JID_878659984891",
+    "@odata.type": "#DellJob.v1_0_2.DellJob",
+    "CompletionTime": %s,
+    "Description": "Job Instance",
+    "EndTime": "TIME_NA",
+    "Id": "JID_878659984891",
+    "JobState": "%s",
+    "JobType": "ExportConfiguration",
+    "Message": "%s",
+    "MessageArgs": [],
+    "MessageId": "%s",
+    "Name": "Export: Server Configuration Profile",
+    "PercentComplete": %d,
+    "StartTime": "TIME_NOW",
+    "TargetSettingsURI": null
+}`
+
+const exportTaskBody = `{
+    "@odata.context": "/redfish/v1/$metadata#Task.Task",
+    "@odata.id": "/redfish/v1/TaskService/Tasks/JID_878659984891",
+    "@odata.type": "#Task.v1_4_2.Task",
+    "Description": "Server Configuration and other Tasks running on iDRAC are listed here",
+    "EndTime": "",
+    "Id": "JID_878659984891",
+    "Messages": [
+        {
+            "Message": "Exporting Server Configuration Profile.",
+            "MessageArgs": [],
+            "MessageId": "SYS057"
+        }
+    ],
+    "Name": "Export: Server Configuration Profile",
+    "PercentComplete": 20,
+    "TaskState": "Running",
+    "TaskStatus": "OK"
+}`
+
+const exportedProfile = `{"SystemConfiguration": {"Model": "PowerEdge R640", "ServiceTag": "0000000", "Components": []}}`
+
+// idracMock serves recorded iDRAC responses for the job queue, the Lifecycle
+// Controller and the Server Configuration Profile actions.
+type idracMock struct {
+	t *testing.T
+
+	mu       sync.Mutex
+	lcStatus string
+	jobReads int
+	requests []string
+	payloads map[string]map[string]interface{}
+}
+
+func (mock *idracMock) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+
+	path := req.URL.Path
+	mock.requests = append(mock.requests, req.Method+" "+path)
+	if req.Method == http.MethodPost {
+		var payload map[string]interface{}
+		_ = json.NewDecoder(req.Body).Decode(&payload)
+		mock.payloads[path] = payload
+	}
+
+	switch {
+	case path == "/redfish/v1/":
+		rw.Write([]byte(serviceRootBody)) //nolint:errcheck
+	case path == "/redfish/v1/Managers/iDRAC.Embedded.1":
+		rw.Write([]byte(managerBody)) //nolint:errcheck
+	case path == "/redfish/v1/Dell/Managers/iDRAC.Embedded.1/DellJobService":
+		rw.Write([]byte(jobServiceBody)) //nolint:errcheck
+	case path == "/redfish/v1/Dell/Managers/iDRAC.Embedded.1/DellLCService":
+		rw.Write([]byte(lcServiceBody)) //nolint:errcheck
+	case strings.HasSuffix(path, "DellLCService.GetRemoteServicesAPIStatus"):
+		rw.Write([]byte(`{"@Message.ExtendedInfo": [], "LCStatus": "` + mock.lcStatus + //nolint:errcheck
+			`", "RTStatus": "Ready", "ServerStatus": "OutOfPOST", "Status": "` + mock.lcStatus + `", "TelemetryStatus": "Ready"}`))
+	case strings.HasSuffix(path, "DellJobService.DeleteJobQueue"):
+		rw.Write([]byte(`{"@Message.ExtendedInfo": [{"Message": "Successfully Completed Request", "MessageId": "Base.1.5.Success"}]}`)) //nolint:errcheck
+	case strings.HasSuffix(path, "ExportSystemConfiguration") || strings.HasSuffix(path, "ImportSystemConfiguration"):
+		rw.Header().Set("Location", "/redfish/v1/TaskService/Tasks/JID_878659984891")
+		rw.WriteHeader(http.StatusAccepted)
+	case path == "/redfish/v1/TaskService/Tasks/JID_878659984891":
+		if mock.jobReads < 2 {
+			rw.Write([]byte(exportTaskBody)) //nolint:errcheck
+		} else {
+			rw.Write([]byte(exportedProfile)) //nolint:errcheck
+		}
+	case path == "/redfish/v1/Managers/iDRAC.Embedded.1/Jobs":
+		rw.Write([]byte(jobsBody)) //nolint:errcheck
+	case path == "/redfish/v1/Managers/iDRAC.Embedded.1/Jobs/JID_878659984891":
+		mock.jobReads++
+		if mock.jobReads < 2 {
+			fmt.Fprintf(rw, jobBody, "null", RunningJobState, "Exporting Server Configuration Profile.", "SYS057", 20)
+			return
+		}
+		fmt.Fprintf(rw, jobBody, `"2023-03-21T10:12:37"`, CompletedJobState, "Successfully exported Server Configuration Profile", "SYS043", 100)
+	default:
+		mock.t.Errorf("mock got unexpected %v request to path %v", req.Method, path)
+		rw.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func testIDRAC(t *testing.T, lcStatus string) (*Manager, *idracMock) {
+	mock := &idracMock{t: t, lcStatus: lcStatus, payloads: make(map[string]map[string]interface{})}
+	server := httptest.NewServer(mock)
+	t.Cleanup(server.Close)
+
+	c, err := gofish.Connect(gofish.ClientConfig{Endpoint: server.URL, HTTPClient: server.Client()})
+	if err != nil {
+		t.Fatalf("failed to establish client to mock http server due to: %v", err)
+	}
+
+	manager, err := redfish.GetManager(c, "/redfish/v1/Managers/iDRAC.Embedded.1")
+	if err != nil {
+		t.Fatalf("failed to get manager due to: %v", err)
+	}
+
+	dellManager, err := FromManager(manager)
+	if err != nil {
+		t.Fatalf("failed to get dell manager due to: %v", err)
+	}
+	return dellManager, mock
+}
+
+// TestDellExportSystemConfiguration tests exporting a Server Configuration
+// Profile and tracking its job.
+func TestDellExportSystemConfiguration(t *testing.T) {
+	manager, mock := testIDRAC(t, "Ready")
+
+	task, err := manager.ExportSystemConfiguration(&ExportSystemConfigurationRequest{
+		ExportFormat:    JSONSCPFormat,
+		ShareParameters: ShareParameters{Target: "ALL"},
+	})
+	if err != nil {
+		t.Fatalf("failed to export system configuration due to: %v", err)
+	}
+	if task.ID != "JID_878659984891" || task.TaskState != redfish.RunningTaskState {
+		t.Errorf("Unexpected task: %+v", task)
+	}
+
+	payload := mock.payloads["/redfish/v1/Managers/iDRAC.Embedded.1/Actions/Oem/EID_674_Manager.ExportSystemConfiguration"]
+	if payload["ExportFormat"] != "JSON" || payload["ShareParameters"].(map[string]interface{})["Target"] != "ALL" {
+		t.Errorf("Unexpected export payload: %v", payload)
+	}
+
+	if _, err := manager.ExportedSystemConfiguration(task); err == nil || !strings.Contains(err.Error(), "Exporting Server Configuration Profile") {
+		t.Errorf("Expected the running export to fail, got: %v", err)
+	}
+
+	job, err := manager.WaitForJob(task.ID, time.Second, time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed to wait for job due to: %v", err)
+	}
+	if job.JobState != CompletedJobState || job.PercentComplete != 100 || job.MessageID != "SYS043" {
+		t.Errorf("Unexpected job: %+v", job)
+	}
+
+	profile, err := manager.ExportedSystemConfiguration(task)
+	if err != nil {
+		t.Fatalf("failed to read exported profile due to: %v", err)
+	}
+	if string(profile) != exportedProfile {
+		t.Errorf("Unexpected profile: %s", profile)
+	}
+
+	if mock.requests[2] != "GET /redfish/v1/Dell/Managers/iDRAC.Embedded.1/DellLCService" {
+		t.Errorf("Expected the lifecycle controller to be checked before the export, got: %v", mock.requests)
+	}
+}
+
+// TestDellImportSystemConfigurationNotReady tests that an import is not sent
+// while the Lifecycle Controller is busy.
+func TestDellImportSystemConfigurationNotReady(t *testing.T) {
+	manager, mock := testIDRAC(t, "NotReady")
+
+	_, err := manager.ImportSystemConfiguration(&ImportSystemConfigurationRequest{
+		ImportBuffer:    exportedProfile,
+		ShareParameters: ShareParameters{Target: "ALL"},
+		ShutdownType:    GracefulShutdownType,
+	})
+	var notReady *LCNotReadyError
+	if !errors.As(err, &notReady) || notReady.Status.ServerStatus != "OutOfPOST" {
+		t.Fatalf("Expected an LCNotReadyError, got: %v", err)
+	}
+
+	for _, request := range mock.requests {
+		if strings.HasSuffix(request, "ImportSystemConfiguration") {
+			t.Errorf("Expected no import to be sent, got: %v", mock.requests)
+		}
+	}
+}
+
+// TestDellJobQueue tests listing, deleting and clearing jobs.
+func TestDellJobQueue(t *testing.T) {
+	manager, mock := testIDRAC(t, "Ready")
+
+	jobs, err := manager.Jobs()
+	if err != nil {
+		t.Fatalf("failed to list jobs due to: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].ID != "JID_878659984891" || jobs[0].JobType != "ExportConfiguration" {
+		t.Errorf("Unexpected jobs: %+v", jobs)
+	}
+
+	jobService, err := manager.JobService()
+	if err != nil {
+		t.Fatalf("failed to get job service due to: %v", err)
+	}
+
+	const target = "/redfish/v1/Dell/Managers/iDRAC.Embedded.1/DellJobService/Actions/DellJobService.DeleteJobQueue"
+	if err := jobService.DeleteJob("JID_878659984891"); err != nil {
+		t.Fatalf("failed to delete job due to: %v", err)
+	}
+	if mock.payloads[target]["JobID"] != "JID_878659984891" {
+		t.Errorf("Unexpected delete payload: %v", mock.payloads[target])
+	}
+
+	if err := jobService.ClearJobQueue(true); err != nil {
+		t.Fatalf("failed to clear job queue due to: %v", err)
+	}
+	if mock.payloads[target]["JobID"] != "JID_CLEARALL_FORCE" {
+		t.Errorf("Unexpected clear payload: %v", mock.payloads[target])
+	}
+}
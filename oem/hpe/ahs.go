@@ -0,0 +1,118 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package hpe
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+// ahsDateFormat is the format of the dates of an AHS log download.
+const ahsDateFormat = "2006-01-02"
+
+// ActiveHealthSystem is the Active Health System of an iLO, which records
+// the health of the server in AHS logs.
+type ActiveHealthSystem struct {
+	common.Entity
+
+	// ODataType is the odata type.
+	ODataType string `json:"@odata.type"`
+	// AHSEnabled indicates whether the Active Health System records data.
+	AHSEnabled bool
+
+	ahsLocation    string
+	clearLogTarget string
+}
+
+// UnmarshalJSON unmarshals an ActiveHealthSystem object from the raw JSON.
+func (ahs *ActiveHealthSystem) UnmarshalJSON(b []byte) error {
+	type temp ActiveHealthSystem
+	var t struct {
+		temp
+		Actions struct {
+			ClearLog common.ActionTarget `json:"#HpeiLOActiveHealthSystem.ClearLog"`
+		}
+		Links struct {
+			AHSLocation struct {
+				ExtRef string `json:"extref"`
+			}
+		}
+	}
+
+	err := json.Unmarshal(b, &t)
+	if err != nil {
+		return err
+	}
+
+	*ahs = ActiveHealthSystem(t.temp)
+	ahs.ahsLocation = t.Links.AHSLocation.ExtRef
+	ahs.clearLogTarget = t.Actions.ClearLog.Target
+
+	return nil
+}
+
+// GetActiveHealthSystem will get an ActiveHealthSystem instance from the
+// service.
+func GetActiveHealthSystem(c common.Client, uri string) (*ActiveHealthSystem, error) {
+	var ahs ActiveHealthSystem
+	return &ahs, ahs.Get(c, uri, &ahs)
+}
+
+// Download writes the AHS log of the days from from to to, inclusive, to w
+// and returns the number of bytes written. If both dates are zero, the whole
+// log is downloaded.
+func (ahs *ActiveHealthSystem) Download(w io.Writer, from, to time.Time) (int64, error) {
+	if ahs.ahsLocation == "" {
+		return 0, errors.New("AHS log location is not provided by this service")
+	}
+
+	query := url.Values{}
+	switch {
+	case from.IsZero() && to.IsZero():
+		query.Set("downloadAll", "1")
+	case from.IsZero() || to.IsZero():
+		return 0, errors.New("both dates of the AHS log should be set")
+	case to.Before(from):
+		return 0, fmt.Errorf("AHS log end date %s is before start date %s", to.Format(ahsDateFormat), from.Format(ahsDateFormat))
+	default:
+		query.Set("from", from.Format(ahsDateFormat))
+		query.Set("to", to.Format(ahsDateFormat))
+	}
+
+	separator := "?"
+	if strings.Contains(ahs.ahsLocation, "?") {
+		separator = "&"
+	}
+
+	resp, err := ahs.GetClient().Get(ahs.ahsLocation + separator + query.Encode())
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	written, err := io.Copy(w, resp.Body)
+	if err != nil {
+		return written, err
+	}
+	if resp.ContentLength >= 0 && written != resp.ContentLength {
+		return written, fmt.Errorf("AHS log download was truncated: got %d of %d bytes", written, resp.ContentLength)
+	}
+	return written, nil
+}
+
+// ClearLog clears the AHS log.
+func (ahs *ActiveHealthSystem) ClearLog() error {
+	if ahs.clearLogTarget == "" {
+		return errors.New("ClearLog is not supported by this active health system")
+	}
+	return ahs.Post(ahs.clearLogTarget, struct{}{})
+}
@@ -0,0 +1,83 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package hpe
+
+import (
+	"github.com/stmcginnis/gofish/common"
+)
+
+// License is a license installed on an iLO.
+type License struct {
+	common.Entity
+
+	// ODataType is the odata type.
+	ODataType string `json:"@odata.type"`
+	// License is the name of the license, such as 'iLO Advanced'.
+	License string
+	// LicenseClass is the class of the license, such as 'FQL'.
+	LicenseClass string
+	// LicenseExpire is the expiration date of the license, if any.
+	LicenseExpire string
+	// LicenseInstallDate is the date the license was installed.
+	LicenseInstallDate string
+	// LicenseKey is the license key, of which only the last group of
+	// characters is shown.
+	LicenseKey string
+	// LicenseTier is the tier of the license, such as 'ADV'.
+	LicenseTier string
+	// LicenseType is the type of the license, such as 'Perpetual' or
+	// 'Evaluation'.
+	LicenseType string
+}
+
+// GetLicense will get a License instance from the service.
+func GetLicense(c common.Client, uri string) (*License, error) {
+	var license License
+	return &license, license.Get(c, uri, &license)
+}
+
+// ListReferencedLicenses gets the collection of License from
+// a provided reference.
+func ListReferencedLicenses(c common.Client, link string) ([]*License, error) {
+	var result []*License
+	if link == "" {
+		return result, nil
+	}
+
+	type GetResult struct {
+		Item  *License
+		Link  string
+		Error error
+	}
+
+	ch := make(chan GetResult)
+	collectionError := common.NewCollectionError()
+	get := func(link string) {
+		license, err := GetLicense(c, link)
+		ch <- GetResult{Item: license, Link: link, Error: err}
+	}
+
+	go func() {
+		err := common.CollectList(get, c, link)
+		if err != nil {
+			collectionError.Failures[link] = err
+		}
+		close(ch)
+	}()
+
+	for r := range ch {
+		if r.Error != nil {
+			collectionError.Failures[r.Link] = r.Error
+		} else {
+			result = append(result, r.Item)
+		}
+	}
+
+	if collectionError.Empty() {
+		return result, nil
+	}
+
+	return result, collectionError
+}
@@ -0,0 +1,96 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package hpe
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+func init() {
+	common.RegisterOEMDecoder("HPE", "Manager", func(resource *common.OEMResource) (interface{}, error) {
+		var manager redfish.Manager
+		if err := resource.Entity.As(&manager); err != nil {
+			return nil, err
+		}
+		manager.SetClient(resource.Entity.GetClient())
+		result, err := FromManager(&manager)
+		if err != nil {
+			return nil, err
+		}
+		return &result, nil
+	})
+}
+
+type Manager struct {
+	redfish.Manager
+	Oem ManagerOem
+}
+
+type ManagerOem struct {
+	Hpe struct {
+		OdataContext string `json:"@odata.context"`
+		OdataType    string `json:"@odata.type"`
+		Firmware     struct {
+			Current struct {
+				Date          string `json:"Date"`
+				VersionString string `json:"VersionString"`
+			} `json:"Current"`
+		} `json:"Firmware"`
+		License struct {
+			LicenseKey    string `json:"LicenseKey"`
+			LicenseString string `json:"LicenseString"`
+			LicenseType   string `json:"LicenseType"`
+		} `json:"License"`
+		Links struct {
+			ActiveHealthSystem common.Link `json:"ActiveHealthSystem"`
+			LicenseService     common.Link `json:"LicenseService"`
+		} `json:"Links"`
+	} `json:"Hpe"`
+}
+
+func FromManager(manager *redfish.Manager) (Manager, error) {
+	oem := ManagerOem{}
+
+	_ = json.Unmarshal(manager.Oem, &oem)
+
+	return Manager{
+		Manager: *manager,
+		Oem:     oem,
+	}, nil
+}
+
+// ActiveHealthSystem gets the Active Health System of the iLO.
+func (manager *Manager) ActiveHealthSystem() (*ActiveHealthSystem, error) {
+	link := manager.Oem.Hpe.Links.ActiveHealthSystem.String()
+	if link == "" {
+		return nil, errors.New("active health system is not supported by this manager")
+	}
+	return GetActiveHealthSystem(manager.GetClient(), link)
+}
+
+// Licenses gets the licenses installed on the iLO.
+func (manager *Manager) Licenses() ([]*License, error) {
+	return ListReferencedLicenses(manager.GetClient(), manager.Oem.Hpe.Links.LicenseService.String())
+}
+
+// InstallLicense installs an iLO license key. The key replaces the installed
+// license.
+func (manager *Manager) InstallLicense(licenseKey string) error {
+	link := manager.Oem.Hpe.Links.LicenseService.String()
+	if link == "" {
+		return errors.New("license service is not supported by this manager")
+	}
+	if licenseKey == "" {
+		return errors.New("license key should not be empty")
+	}
+
+	return manager.Post(link, struct {
+		LicenseKey string
+	}{LicenseKey: licenseKey})
+}
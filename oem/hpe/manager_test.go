@@ -0,0 +1,209 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package hpe
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+var hpeManagerBody = `{
+    "@odata.context": "/redfish/v1/$metadata#Manager.Manager",
+    "@odata.etag": "W/\"2B1C5E8A\"",
+    "@odata.id": "/redfish/v1/Managers/1/",
+    "@odata.type": "#Manager.v1_5_1.Manager",
+    "Id": "1",
+    "FirmwareVersion": "iLO 5 v2.72",
+    "ManagerType": "BMC",
+    "Model": "iLO 5",
+    "Name": "Manager",
+    "Oem": {
+        "Hpe": {
+            "@odata.context": "/redfish/v1/$metadata#HpeiLO.HpeiLO",
+            "@odata.type": "#HpeiLO.v2_8_0.HpeiLO",
+            "Firmware": {
+                "Current": {
+                    "Date": "Sep 04 2022",
+                    "DebugBuild": false,
+                    "MajorVersion": 2,
+                    "MinorVersion": 72,
+                    "VersionString": "iLO 5 v2.72"
+                }
+            },
+            "License": {
+                "LicenseKey": "XXXXX-XXXXX-XXXXX-XXXXX-Q8RQC",
+                "LicenseString": "iLO Advanced limited-distribution test",
+                "LicenseType": "Internal"
+            },
+            "Links": {
+                "ActiveHealthSystem": {
+                    "@odata.id": "/redfish/v1/Managers/1/ActiveHealthSystem/"
+                },
+                "DateTimeService": {
+                    "@odata.id": "/redfish/v1/Managers/1/DateTime/"
+                },
+                "LicenseService": {
+                    "@odata.id": "/redfish/v1/Managers/1/LicenseService/"
+                },
+                "SecurityService": {
+                    "@odata.id": "/redfish/v1/Managers/1/SecurityService/"
+                }
+            }
+        }
+    },
+    "Status": {
+        "Health": "OK",
+        "State": "Enabled"
+    }
+}`
+
+var hpeAHSBody = `{
+    "@odata.context": "/redfish/v1/$metadata#HpeiLOActiveHealthSystem.HpeiLOActiveHealthSystem",
+    "@odata.etag": "W/\"E1E2A7A4\"",
+    "@odata.id": "/redfish/v1/Managers/1/ActiveHealthSystem/",
+    "@odata.type": "#HpeiLOActiveHealthSystem.v2_5_0.HpeiLOActiveHealthSystem",
+    "Id": "ActiveHealthSystem",
+    "Actions": {
+        "#HpeiLOActiveHealthSystem.ClearLog": {
+            "target": "/redfish/v1/Managers/1/ActiveHealthSystem/Actions/HpeiLOActiveHealthSystem.ClearLog/"
+        }
+    },
+    "AHSEnabled": true,
+    "Links": {
+        "AHSLocation": {
+            "extref": "/ahsdata/HPE_MXQ92102JK_20230321.ahs"
+        },
+        "LogServices": {
+            "@odata.id": "/redfish/v1/Managers/1/LogServices/"
+        }
+    },
+    "Name": "Active Health System"
+}`
+
+var hpeLicenseServiceBody = `{
+    "@odata.context": "/redfish/v1/$metadata#HpeiLOLicenseCollection.HpeiLOLicenseCollection",
+    "@odata.id": "/redfish/v1/Managers/1/LicenseService/",
+    "@odata.type": "#HpeiLOLicenseCollection.HpeiLOLicenseCollection",
+    "Description": "iLO License View",
+    "Members": [
+        {
+            "@odata.id": "/redfish/v1/Managers/1/LicenseService/1/"
+        }
+    ],
+    "Members@odata.count": 1,
+    "Name": "Licenses"
+}`
+
+var hpeLicenseBody = `{
+    "@odata.context": "/redfish/v1/$metadata#HpeiLOLicense.HpeiLOLicense",
+    "@odata.id": "/redfish/v1/Managers/1/LicenseService/1/",
+    "@odata.type": "#HpeiLOLicense.v2_3_0.HpeiLOLicense",
+    "Id": "1",
+    "License": "iLO Advanced",
+    "LicenseClass": "FQL",
+    "LicenseInstallDate": "2023-01-12T09:12:44Z",
+    "LicenseKey": "XXXXX-XXXXX-XXXXX-XXXXX-Q8RQC",
+    "LicenseTier": "ADV",
+    "LicenseType": "Perpetual",
+    "Name": "iLO License"
+}`
+
+func getHpeManager(t *testing.T, responses ...interface{}) (*Manager, *common.TestClient) {
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: append([]interface{}{common.TestResponse(http.StatusOK, hpeManagerBody)}, responses...),
+		},
+	}
+
+	manager, err := redfish.GetManager(testClient, "/redfish/v1/Managers/1/")
+	if err != nil {
+		t.Fatalf("Error getting manager: %s", err)
+	}
+
+	result, err := FromManager(manager)
+	if err != nil {
+		t.Fatalf("Error converting manager: %s", err)
+	}
+	return &result, testClient
+}
+
+// TestHpeManagerOem tests the parsing of the Oem data of an iLO.
+func TestHpeManagerOem(t *testing.T) {
+	manager, _ := getHpeManager(t)
+
+	if manager.Oem.Hpe.Firmware.Current.VersionString != "iLO 5 v2.72" {
+		t.Errorf("Unexpected firmware: %s", manager.Oem.Hpe.Firmware.Current.VersionString)
+	}
+	if manager.Oem.Hpe.License.LicenseType != "Internal" {
+		t.Errorf("Unexpected license type: %s", manager.Oem.Hpe.License.LicenseType)
+	}
+	if manager.Oem.Hpe.Links.LicenseService.String() != "/redfish/v1/Managers/1/LicenseService/" {
+		t.Errorf("Unexpected license service: %s", manager.Oem.Hpe.Links.LicenseService)
+	}
+}
+
+// TestHpeAHSDownload tests downloading the AHS log of a date range.
+func TestHpeAHSDownload(t *testing.T) {
+	ahsData := "AHS\x00\x01binary-log-data"
+	manager, testClient := getHpeManager(t, common.TestResponse(http.StatusOK, hpeAHSBody), common.TestResponse(http.StatusOK, ahsData))
+
+	ahs, err := manager.ActiveHealthSystem()
+	if err != nil {
+		t.Fatalf("Error getting active health system: %s", err)
+	}
+	if !ahs.AHSEnabled {
+		t.Error("Expected AHS to be enabled")
+	}
+
+	var buf bytes.Buffer
+	from := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2023, 3, 21, 0, 0, 0, 0, time.UTC)
+	written, err := ahs.Download(&buf, from, to)
+	if err != nil {
+		t.Fatalf("Error downloading AHS log: %s", err)
+	}
+	if written != int64(len(ahsData)) || buf.String() != ahsData {
+		t.Errorf("Unexpected AHS log: %q", buf.String())
+	}
+
+	calls := testClient.CapturedCalls()
+	if calls[2].URL != "/ahsdata/HPE_MXQ92102JK_20230321.ahs?from=2023-03-01&to=2023-03-21" {
+		t.Errorf("Unexpected download URL: %s", calls[2].URL)
+	}
+
+	if _, err := ahs.Download(&buf, to, from); err == nil {
+		t.Error("Expected a reversed date range to fail")
+	}
+}
+
+// TestHpeLicenses tests reading and installing iLO licenses.
+func TestHpeLicenses(t *testing.T) {
+	manager, testClient := getHpeManager(t, common.TestResponse(http.StatusOK, hpeLicenseServiceBody), common.TestResponse(http.StatusOK, hpeLicenseBody))
+
+	licenses, err := manager.Licenses()
+	if err != nil {
+		t.Fatalf("Error getting licenses: %s", err)
+	}
+	if len(licenses) != 1 || licenses[0].License != "iLO Advanced" || licenses[0].LicenseTier != "ADV" {
+		t.Errorf("Unexpected licenses: %+v", licenses)
+	}
+
+	if err := manager.InstallLicense("AAAAA-BBBBB-CCCCC-DDDDD-EEEEE"); err != nil {
+		t.Fatalf("Error installing license: %s", err)
+	}
+
+	calls := testClient.CapturedCalls()
+	call := calls[len(calls)-1]
+	if call.Action != http.MethodPost || call.URL != "/redfish/v1/Managers/1/LicenseService/" ||
+		!strings.Contains(call.Payload, "LicenseKey:AAAAA-BBBBB-CCCCC-DDDDD-EEEEE") {
+		t.Errorf("Unexpected install call: %+v", call)
+	}
+}
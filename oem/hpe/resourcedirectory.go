@@ -0,0 +1,91 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package hpe
+
+import (
+	"strings"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+// DefaultResourceDirectoryURI is the location of the resource directory of an
+// iLO.
+const DefaultResourceDirectoryURI = "/redfish/v1/ResourceDirectory/"
+
+// ResourceDirectoryInstance is a resource listed in the resource directory.
+type ResourceDirectoryInstance struct {
+	// ODataID is the location of the resource.
+	ODataID string `json:"@odata.id"`
+	// ODataType is the type of the resource.
+	ODataType string `json:"@odata.type"`
+	// ETag is the current ETag of the resource.
+	ETag string `json:"ETag"`
+	// HTTPMethods are the methods the resource supports.
+	HTTPMethods []string `json:"HttpMethods"`
+}
+
+// ResourceType returns the type of the resource, such as 'Chassis'.
+func (instance *ResourceDirectoryInstance) ResourceType() string {
+	return common.ResourceTypeFromODataType(instance.ODataType)
+}
+
+// ResourceDirectory is the HpeiLOResourceDirectory of an iLO, which lists
+// every resource of the service so they can be found without crawling.
+type ResourceDirectory struct {
+	common.Entity
+
+	// ODataType is the odata type.
+	ODataType string `json:"@odata.type"`
+	// Description provides a description of this resource.
+	Description string
+	// Instances are the resources of the service.
+	Instances []ResourceDirectoryInstance
+}
+
+// GetResourceDirectory will get a ResourceDirectory instance from the
+// service.
+func GetResourceDirectory(c common.Client, uri string) (*ResourceDirectory, error) {
+	var directory ResourceDirectory
+	return &directory, directory.Get(c, uri, &directory)
+}
+
+// Find returns the locations of the resources of a type, such as 'Chassis'
+// or 'SmartStorageConfig'. Settings objects, whose locations end in
+// 'settings', are left out.
+func (directory *ResourceDirectory) Find(resourceType string) []string {
+	var result []string
+	for i := range directory.Instances {
+		instance := &directory.Instances[i]
+		if instance.ResourceType() != resourceType {
+			continue
+		}
+		if strings.HasSuffix(strings.ToLower(strings.TrimSuffix(instance.ODataID, "/")), "/settings") {
+			continue
+		}
+		result = append(result, instance.ODataID)
+	}
+	return result
+}
+
+// SmartStorageConfigs gets the Smart Storage configurations of all Smart
+// Array controllers in the resource directory.
+func (directory *ResourceDirectory) SmartStorageConfigs() ([]*SmartStorageConfig, error) {
+	var result []*SmartStorageConfig
+	collectionError := common.NewCollectionError()
+	for _, uri := range directory.Find("SmartStorageConfig") {
+		config, err := GetSmartStorageConfig(directory.GetClient(), uri)
+		if err != nil {
+			collectionError.Failures[uri] = err
+		} else {
+			result = append(result, config)
+		}
+	}
+
+	if collectionError.Empty() {
+		return result, nil
+	}
+
+	return result, collectionError
+}
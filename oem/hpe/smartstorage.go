@@ -0,0 +1,161 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package hpe
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+// DataGuard is the level of protection of existing data when a Smart Storage
+// configuration is applied.
+type DataGuard string
+
+const (
+	// StrictDataGuard rejects changes that destroy existing data.
+	StrictDataGuard DataGuard = "Strict"
+	// PermissiveDataGuard allows destructive changes to the logical drives
+	// in the request only.
+	PermissiveDataGuard DataGuard = "Permissive"
+	// DisabledDataGuard allows destructive changes to all logical drives.
+	DisabledDataGuard DataGuard = "Disabled"
+)
+
+// SmartStorageLogicalDrive is a logical drive of a Smart Array controller.
+type SmartStorageLogicalDrive struct {
+	// Accelerator is the caching of the logical drive, such as
+	// 'ControllerCache'.
+	Accelerator string `json:",omitempty"`
+	// Actions are the actions to apply to the logical drive, such as a
+	// LogicalDriveDelete action.
+	Actions []SmartStorageAction `json:",omitempty"`
+	// CapacityGiB is the capacity of the logical drive. If zero, all space
+	// of the data drives is used.
+	CapacityGiB int `json:",omitempty"`
+	// DataDrives are the locations of the physical drives of the logical
+	// drive, such as '1I:1:1'.
+	DataDrives []string `json:",omitempty"`
+	// LogicalDriveName is the name of the logical drive.
+	LogicalDriveName string `json:",omitempty"`
+	// LogicalDriveNumber is the number of the logical drive.
+	LogicalDriveNumber int `json:",omitempty"`
+	// Raid is the RAID level of the logical drive, such as 'Raid1'.
+	Raid string `json:",omitempty"`
+	// StripSizeBytes is the strip size of the logical drive.
+	StripSizeBytes int `json:",omitempty"`
+	// VolumeUniqueIdentifier uniquely identifies the logical drive.
+	VolumeUniqueIdentifier string `json:",omitempty"`
+}
+
+// SmartStorageAction is an action applied to a logical drive.
+type SmartStorageAction struct {
+	// Action is the name of the action, such as 'LogicalDriveDelete'.
+	Action string
+}
+
+// SmartStorageConfig is the configuration of a Smart Array controller of
+// Gen10 servers, which predates the standard Storage resources. Changes are
+// applied at the next reboot of the server.
+type SmartStorageConfig struct {
+	common.Entity
+
+	// ODataType is the odata type.
+	ODataType string `json:"@odata.type"`
+	// DataGuard is the protection of existing data of the last change.
+	DataGuard DataGuard
+	// Location is the location of the controller, such as 'Slot 0'.
+	Location string
+	// LocationFormat is the format of the location, such as 'PCISlot'.
+	LocationFormat string
+	// LogicalDrives are the logical drives of the controller.
+	LogicalDrives []SmartStorageLogicalDrive
+
+	settingsObject string
+}
+
+// UnmarshalJSON unmarshals a SmartStorageConfig object from the raw JSON.
+func (config *SmartStorageConfig) UnmarshalJSON(b []byte) error {
+	type temp SmartStorageConfig
+	var t struct {
+		temp
+		Settings common.Settings `json:"@Redfish.Settings"`
+	}
+
+	err := json.Unmarshal(b, &t)
+	if err != nil {
+		return err
+	}
+
+	*config = SmartStorageConfig(t.temp)
+	config.settingsObject = t.Settings.SettingsObject.String()
+
+	return nil
+}
+
+// GetSmartStorageConfig will get a SmartStorageConfig instance from the
+// service.
+func GetSmartStorageConfig(c common.Client, uri string) (*SmartStorageConfig, error) {
+	var config SmartStorageConfig
+	return &config, config.Get(c, uri, &config)
+}
+
+// PendingSettings gets the configuration that is applied at the next reboot.
+func (config *SmartStorageConfig) PendingSettings() (*SmartStorageConfig, error) {
+	if config.settingsObject == "" {
+		return nil, errors.New("smart storage config has no settings object")
+	}
+	return GetSmartStorageConfig(config.GetClient(), config.settingsObject)
+}
+
+// ApplyLogicalDrives replaces the logical drives of the controller at the
+// next reboot. The data guard controls which existing logical drives may be
+// changed or deleted.
+func (config *SmartStorageConfig) ApplyLogicalDrives(drives []SmartStorageLogicalDrive, dataGuard DataGuard) error {
+	if config.settingsObject == "" {
+		return errors.New("smart storage config has no settings object")
+	}
+
+	payload := struct {
+		DataGuard     DataGuard
+		LogicalDrives []SmartStorageLogicalDrive
+	}{DataGuard: dataGuard, LogicalDrives: drives}
+	if payload.LogicalDrives == nil {
+		payload.LogicalDrives = []SmartStorageLogicalDrive{}
+	}
+
+	resp, err := config.GetClient().Put(config.settingsObject, payload)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// CreateLogicalDrive adds a logical drive to the controller at the next
+// reboot. Existing logical drives are kept.
+func (config *SmartStorageConfig) CreateLogicalDrive(drive *SmartStorageLogicalDrive) error {
+	if len(drive.DataDrives) == 0 || drive.Raid == "" {
+		return errors.New("logical drive should have a RAID level and data drives")
+	}
+
+	drives := make([]SmartStorageLogicalDrive, 0, len(config.LogicalDrives)+1)
+	drives = append(drives, config.LogicalDrives...)
+	drives = append(drives, *drive)
+	return config.ApplyLogicalDrives(drives, StrictDataGuard)
+}
+
+// DeleteLogicalDrive deletes a logical drive from the controller at the next
+// reboot.
+func (config *SmartStorageConfig) DeleteLogicalDrive(volumeUniqueIdentifier string) error {
+	if volumeUniqueIdentifier == "" {
+		return errors.New("volume unique identifier should not be empty")
+	}
+
+	return config.ApplyLogicalDrives([]SmartStorageLogicalDrive{{
+		Actions:                []SmartStorageAction{{Action: "LogicalDriveDelete"}},
+		VolumeUniqueIdentifier: volumeUniqueIdentifier,
+	}}, PermissiveDataGuard)
+}
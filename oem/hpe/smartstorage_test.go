@@ -0,0 +1,160 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package hpe
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+var hpeResourceDirectoryBody = `{
+    "@odata.context": "/redfish/v1/$metadata#HpeiLOResourceDirectory.HpeiLOResourceDirectory",
+    "@odata.id": "/redfish/v1/ResourceDirectory/",
+    "@odata.type": "#HpeiLOResourceDirectory.v2_0_0.HpeiLOResourceDirectory",
+    "Description": "iLO Resource Directory",
+    "Id": "ResourceDirectory",
+    "Instances": [
+        {
+            "@odata.id": "/redfish/v1/Chassis/1/",
+            "@odata.type": "#Chassis.v1_10_0.Chassis",
+            "ETag": "W/\"50540B90\"",
+            "HttpMethods": ["GET", "HEAD", "PATCH"]
+        },
+        {
+            "@odata.id": "/redfish/v1/systems/1/smartstorageconfig/",
+            "@odata.type": "#SmartStorageConfig.v2_0_0.SmartStorageConfig",
+            "ETag": "W/\"2F8C2D1A\"",
+            "HttpMethods": ["GET", "HEAD"]
+        },
+        {
+            "@odata.id": "/redfish/v1/systems/1/smartstorageconfig/settings/",
+            "@odata.type": "#SmartStorageConfig.v2_0_0.SmartStorageConfig",
+            "ETag": "W/\"9D1E8C01\"",
+            "HttpMethods": ["GET", "HEAD", "PUT", "PATCH"]
+        }
+    ],
+    "Name": "Resource Directory"
+}`
+
+var hpeSmartStorageConfigBody = `{
+    "@odata.context": "/redfish/v1/$metadata#SmartStorageConfig.SmartStorageConfig",
+    "@odata.etag": "W/\"2F8C2D1A\"",
+    "@odata.id": "/redfish/v1/systems/1/smartstorageconfig/",
+    "@odata.type": "#SmartStorageConfig.v2_0_0.SmartStorageConfig",
+    "Id": "smartstorageconfig",
+    "@Redfish.Settings": {
+        "@odata.type": "#Settings.v1_0_0.Settings",
+        "ETag": "DAB60D7F",
+        "Messages": [
+            {
+                "MessageId": "SmartArray.1.0.Success"
+            }
+        ],
+        "SettingsObject": {
+            "@odata.id": "/redfish/v1/systems/1/smartstorageconfig/settings/"
+        },
+        "Time": "2023-03-21T10:12:37Z"
+    },
+    "DataGuard": "Disabled",
+    "Location": "Slot 0",
+    "LocationFormat": "PCISlot",
+    "LogicalDrives": [
+        {
+            "Accelerator": "ControllerCache",
+            "CapacityGiB": 558,
+            "DataDrives": ["1I:1:1", "1I:1:2"],
+            "LogicalDriveName": "Boot",
+            "LogicalDriveNumber": 1,
+            "Raid": "Raid1",
+            "StripSizeBytes": 262144,
+            "VolumeUniqueIdentifier": "600508B1001C4B8F6A2D0D5A8DB1D3C4"
+        }
+    ],
+    "Name": "SmartStorageConfig"
+}`
+
+// TestHpeResourceDirectory tests finding resources in the resource directory.
+func TestHpeResourceDirectory(t *testing.T) {
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {common.TestResponse(http.StatusOK, hpeResourceDirectoryBody), common.TestResponse(http.StatusOK, hpeSmartStorageConfigBody)},
+		},
+	}
+
+	directory, err := GetResourceDirectory(testClient, DefaultResourceDirectoryURI)
+	if err != nil {
+		t.Fatalf("Error getting resource directory: %s", err)
+	}
+	if len(directory.Instances) != 3 || directory.Instances[0].HTTPMethods[2] != "PATCH" {
+		t.Errorf("Unexpected instances: %+v", directory.Instances)
+	}
+
+	if chassis := directory.Find("Chassis"); len(chassis) != 1 || chassis[0] != "/redfish/v1/Chassis/1/" {
+		t.Errorf("Unexpected chassis: %v", chassis)
+	}
+
+	configs, err := directory.SmartStorageConfigs()
+	if err != nil {
+		t.Fatalf("Error getting smart storage configs: %s", err)
+	}
+	if len(configs) != 1 || configs[0].Location != "Slot 0" {
+		t.Errorf("Unexpected smart storage configs: %+v", configs)
+	}
+}
+
+// TestHpeSmartStorageConfig tests changing the logical drives of a Smart
+// Array controller.
+func TestHpeSmartStorageConfig(t *testing.T) {
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {common.TestResponse(http.StatusOK, hpeSmartStorageConfigBody)},
+		},
+	}
+
+	config, err := GetSmartStorageConfig(testClient, "/redfish/v1/systems/1/smartstorageconfig/")
+	if err != nil {
+		t.Fatalf("Error getting smart storage config: %s", err)
+	}
+
+	drive := config.LogicalDrives[0]
+	if drive.Raid != "Raid1" || len(drive.DataDrives) != 2 || config.DataGuard != DisabledDataGuard {
+		t.Errorf("Unexpected config: %+v", config)
+	}
+
+	err = config.CreateLogicalDrive(&SmartStorageLogicalDrive{
+		LogicalDriveName: "Data",
+		Raid:             "Raid5",
+		DataDrives:       []string{"1I:1:3", "1I:1:4", "1I:1:5"},
+	})
+	if err != nil {
+		t.Fatalf("Error creating logical drive: %s", err)
+	}
+
+	err = config.DeleteLogicalDrive("600508B1001C4B8F6A2D0D5A8DB1D3C4")
+	if err != nil {
+		t.Fatalf("Error deleting logical drive: %s", err)
+	}
+
+	calls := testClient.CapturedCalls()
+	if len(calls) != 3 {
+		t.Fatalf("Expected 3 calls, captured: %v", calls)
+	}
+
+	create := calls[1]
+	if create.Action != http.MethodPut || create.URL != "/redfish/v1/systems/1/smartstorageconfig/settings/" ||
+		!strings.Contains(create.Payload, "DataGuard:Strict") ||
+		!strings.Contains(create.Payload, "LogicalDriveName:Boot") ||
+		!strings.Contains(create.Payload, "LogicalDriveName:Data") {
+		t.Errorf("Unexpected create call: %+v", create)
+	}
+
+	del := calls[2]
+	if !strings.Contains(del.Payload, "DataGuard:Permissive") || !strings.Contains(del.Payload, "Action:LogicalDriveDelete") {
+		t.Errorf("Unexpected delete call: %+v", del)
+	}
+}
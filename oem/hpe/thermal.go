@@ -7,9 +7,26 @@ package hpe
 import (
 	"encoding/json"
 
+	"github.com/stmcginnis/gofish/common"
 	"github.com/stmcginnis/gofish/redfish"
 )
 
+func init() {
+	common.RegisterOEMVendor("HPE", "Hpe", "Hp")
+	common.RegisterOEMDecoder("HPE", "Thermal", func(resource *common.OEMResource) (interface{}, error) {
+		var thermal redfish.Thermal
+		if err := resource.Entity.As(&thermal); err != nil {
+			return nil, err
+		}
+		thermal.SetClient(resource.Entity.GetClient())
+		result, err := FromThermal(&thermal)
+		if err != nil {
+			return nil, err
+		}
+		return &result, nil
+	})
+}
+
 type Fan struct {
 	redfish.ThermalFan
 	Oem FanOem
@@ -5,10 +5,14 @@
 package hpe
 
 import (
+	"bytes"
 	"encoding/json"
+	"io"
+	"net/http"
 	"strings"
 	"testing"
 
+	"github.com/stmcginnis/gofish/common"
 	"github.com/stmcginnis/gofish/redfish"
 )
 
@@ -103,3 +107,35 @@ func TestHpeThermalOem(t *testing.T) {
 		t.Errorf("Received invalid location: %s", hpeThermal.Fans[0].Oem.Hpe.Location)
 	}
 }
+
+// TestHpeThermalOEMExtension tests decoding a Thermal object through the OEM registry.
+func TestHpeThermalOEMExtension(t *testing.T) {
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {&http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(hpeThermalBody)),
+				Header:     make(http.Header),
+			}},
+		},
+		RetainRawData: true,
+	}
+
+	thermal, err := redfish.GetThermal(testClient, "/redfish/v1/Chassis/1/Thermal/")
+	if err != nil {
+		t.Fatalf("Error getting thermal: %s", err)
+	}
+
+	extension, err := thermal.OEMExtension()
+	if err != nil {
+		t.Fatalf("Error decoding OEM extension: %s", err)
+	}
+
+	hpeThermal, ok := extension.(*Thermal)
+	if !ok {
+		t.Fatalf("Unexpected OEM extension type: %T", extension)
+	}
+	if hpeThermal.Oem.Hpe.FanPercentMinimum != 15 || hpeThermal.ID != "Thermal" {
+		t.Errorf("Unexpected OEM extension: %+v", hpeThermal.Oem)
+	}
+}
@@ -0,0 +1,158 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package lenovo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+// FoDKey is a Features on Demand activation key installed on an XClarity
+// Controller.
+type FoDKey struct {
+	common.Entity
+
+	// ODataType is the odata type.
+	ODataType string `json:"@odata.type"`
+	// DescTypeCode is the feature type code of the key.
+	DescTypeCode int
+	// Expires is the expiration date of the key, or 'NEVER'.
+	Expires string
+	// FeatureDescription describes the feature the key enables, such as
+	// 'Lenovo XClarity Controller Enterprise Upgrade'.
+	FeatureDescription string
+	// Status is the status of the key.
+	Status common.Status
+	// UseCount is the number of times the key was used.
+	UseCount int
+	// UseLimit is the number of times the key can be used.
+	UseLimit int
+}
+
+// GetFoDKey will get a FoDKey instance from the service.
+func GetFoDKey(c common.Client, uri string) (*FoDKey, error) {
+	var key FoDKey
+	return &key, key.Get(c, uri, &key)
+}
+
+// ListReferencedFoDKeys gets the collection of FoDKey from
+// a provided reference.
+func ListReferencedFoDKeys(c common.Client, link string) ([]*FoDKey, error) {
+	var result []*FoDKey
+	if link == "" {
+		return result, nil
+	}
+
+	type GetResult struct {
+		Item  *FoDKey
+		Link  string
+		Error error
+	}
+
+	ch := make(chan GetResult)
+	collectionError := common.NewCollectionError()
+	get := func(link string) {
+		key, err := GetFoDKey(c, link)
+		ch <- GetResult{Item: key, Link: link, Error: err}
+	}
+
+	go func() {
+		err := common.CollectList(get, c, link)
+		if err != nil {
+			collectionError.Failures[link] = err
+		}
+		close(ch)
+	}()
+
+	for r := range ch {
+		if r.Error != nil {
+			collectionError.Failures[r.Link] = r.Error
+		} else {
+			result = append(result, r.Item)
+		}
+	}
+
+	if collectionError.Empty() {
+		return result, nil
+	}
+
+	return result, collectionError
+}
+
+// Delete removes the key from the controller.
+func (key *FoDKey) Delete() error {
+	_, err := redfish.DeleteEntity(key.GetClient(), &key.Entity)
+	return err
+}
+
+// FoD is the Features on Demand service of an XClarity Controller, which
+// manages the activation keys of optional features.
+type FoD struct {
+	common.Entity
+
+	// ODataType is the odata type.
+	ODataType string `json:"@odata.type"`
+	// Description provides a description of this resource.
+	Description string
+
+	keys string
+}
+
+// UnmarshalJSON unmarshals a FoD object from the raw JSON.
+func (fod *FoD) UnmarshalJSON(b []byte) error {
+	type temp FoD
+	var t struct {
+		temp
+		Keys common.Link
+	}
+
+	err := json.Unmarshal(b, &t)
+	if err != nil {
+		return err
+	}
+
+	*fod = FoD(t.temp)
+	fod.keys = t.Keys.String()
+
+	return nil
+}
+
+// GetFoD will get a FoD instance from the service.
+func GetFoD(c common.Client, uri string) (*FoD, error) {
+	var fod FoD
+	return &fod, fod.Get(c, uri, &fod)
+}
+
+// Keys gets the installed activation keys.
+func (fod *FoD) Keys() ([]*FoDKey, error) {
+	return ListReferencedFoDKeys(fod.GetClient(), fod.keys)
+}
+
+// InstallKey installs an activation key from the contents of its key file.
+func (fod *FoD) InstallKey(keyFile []byte) (*FoDKey, error) {
+	if fod.keys == "" {
+		return nil, errors.New("key collection is not provided by this service")
+	}
+	if len(keyFile) == 0 {
+		return nil, errors.New("key file should not be empty")
+	}
+
+	var key FoDKey
+	task, err := redfish.CreateResource(fod.GetClient(), fod.keys, struct {
+		Activationkey string
+	}{Activationkey: base64.StdEncoding.EncodeToString(keyFile)}, &key)
+	if err != nil {
+		return nil, err
+	}
+	if task != nil {
+		return nil, errors.New("service installed the key asynchronously")
+	}
+	key.SetClient(fod.GetClient())
+	return &key, nil
+}
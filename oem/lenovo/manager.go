@@ -0,0 +1,71 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package lenovo
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+func init() {
+	common.RegisterOEMVendor("Lenovo")
+	common.RegisterOEMDecoder("Lenovo", "Manager", func(resource *common.OEMResource) (interface{}, error) {
+		var manager redfish.Manager
+		if err := resource.Entity.As(&manager); err != nil {
+			return nil, err
+		}
+		manager.SetClient(resource.Entity.GetClient())
+		return FromManager(&manager)
+	})
+}
+
+// Manager is the Lenovo-specific handler for an XClarity Controller manager.
+type Manager struct {
+	redfish.Manager
+
+	fod         string
+	serviceData string
+}
+
+// FromManager converts a standard Manager object to the OEM implementation.
+func FromManager(manager *redfish.Manager) (*Manager, error) {
+	m := &Manager{Manager: *manager}
+
+	if len(manager.Oem) > 0 {
+		var oem struct {
+			Lenovo struct {
+				FoD         common.Link
+				ServiceData common.Link
+			}
+		}
+		if err := json.Unmarshal(manager.Oem, &oem); err != nil {
+			return nil, err
+		}
+		m.fod = oem.Lenovo.FoD.String()
+		m.serviceData = oem.Lenovo.ServiceData.String()
+	}
+
+	m.SetClient(manager.GetClient())
+	return m, nil
+}
+
+// ServiceData gets the service that collects service data of the server.
+func (manager *Manager) ServiceData() (*ServiceData, error) {
+	if manager.serviceData == "" {
+		return nil, errors.New("service data is not supported by this manager")
+	}
+	return GetServiceData(manager.GetClient(), manager.serviceData)
+}
+
+// FoD gets the Features on Demand service of the XClarity Controller.
+func (manager *Manager) FoD() (*FoD, error) {
+	if manager.fod == "" {
+		return nil, errors.New("features on demand are not supported by this manager")
+	}
+	return GetFoD(manager.GetClient(), manager.fod)
+}
@@ -0,0 +1,227 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package lenovo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+const managerBody = `{
+    "@odata.type": "#Manager.v1_10_0.Manager",
+    "@odata.id": "/redfish/v1/Managers/1",
+    "Id": "1",
+    "Name": "Manager",
+    "ManagerType": "BMC",
+    "Model": "Lenovo XClarity Controller",
+    "FirmwareVersion": "TEI392P 5.10",
+    "Oem": {
+        "Lenovo": {
+            "@odata.type": "#LenovoManager.v1_0_0.LenovoManagerProperties",
+            "FoD": {
+                "@odata.id": "/redfish/v1/Managers/1/Oem/Lenovo/FoD"
+            },
+            "ServiceData": {
+                "@odata.id": "/redfish/v1/Managers/1/Oem/Lenovo/ServiceData"
+            },
+            "Security": {
+                "@odata.id": "/redfish/v1/Managers/1/Oem/Lenovo/Security"
+            }
+        }
+    }
+}`
+
+const serviceDataBody = `{
+    "@odata.type": "#LenovoServiceData.v1_0_0.LenovoServiceData",
+    "@odata.id": "/redfish/v1/Managers/1/Oem/Lenovo/ServiceData",
+    "Id": "ServiceData",
+    "Name": "Service Data",
+    "Description": "This resource is used to represent service data for a Redfish implementation.",
+    "Actions": {
+        "#LenovoServiceData.ExportFFDCData": {
+            "target": "/redfish/v1/Managers/1/Oem/Lenovo/ServiceData/Actions/LenovoServiceData.ExportFFDCData",
+            "DataCollectionType@Redfish.AllowableValues": ["ServiceDataFile", "ProcessorDump", "BMCDump"],
+            "InitializationNeeded@Redfish.AllowableValues": [true, false]
+        }
+    }
+}`
+
+const ffdcTaskBody = `{
+    "@odata.type": "#Task.v1_4_3.Task",
+    "@odata.id": "/redfish/v1/TaskService/Tasks/FFDC_1",
+    "Id": "FFDC_1",
+    "Name": "Export FFDC data",
+    "TaskState": "%s",
+    "PercentComplete": %d,
+    "Oem": {
+        "Lenovo": {
+            "FFDCForDownloading": {
+                "DownloadURI": "/redfish/v1/Managers/1/Oem/Lenovo/ServiceData/J300ABCD_xcc_230321-101237.tzz",
+                "FFDCFileName": "J300ABCD_xcc_230321-101237.tzz"
+            }
+        }
+    }
+}`
+
+const fodBody = `{
+    "@odata.type": "#LenovoFoDService.v1_0_0.LenovoFoDService",
+    "@odata.id": "/redfish/v1/Managers/1/Oem/Lenovo/FoD",
+    "Id": "FoD",
+    "Name": "Feature on Demand",
+    "Keys": {
+        "@odata.id": "/redfish/v1/Managers/1/Oem/Lenovo/FoD/Keys"
+    }
+}`
+
+const fodKeysBody = `{
+    "@odata.type": "#LenovoFoDKeyCollection.LenovoFoDKeyCollection",
+    "@odata.id": "/redfish/v1/Managers/1/Oem/Lenovo/FoD/Keys",
+    "Name": "FoD Keys",
+    "Members": [
+        {
+            "@odata.id": "/redfish/v1/Managers/1/Oem/Lenovo/FoD/Keys/4C5D8E1A"
+        }
+    ],
+    "Members@odata.count": 1
+}`
+
+const fodKeyBody = `{
+    "@odata.type": "#LenovoFoDKey.v1_0_0.LenovoFoDKey",
+    "@odata.id": "/redfish/v1/Managers/1/Oem/Lenovo/FoD/Keys/4C5D8E1A",
+    "Id": "4C5D8E1A",
+    "Name": "FoD Key",
+    "DescTypeCode": 32772,
+    "Expires": "NEVER",
+    "FeatureDescription": "Lenovo XClarity Controller Enterprise Upgrade",
+    "Status": {
+        "Health": "OK",
+        "State": "Enabled"
+    },
+    "UseCount": 1,
+    "UseLimit": 1
+}`
+
+func getXCCManager(t *testing.T, testClient *common.TestClient) *Manager {
+	manager, err := redfish.GetManager(testClient, "/redfish/v1/Managers/1")
+	if err != nil {
+		t.Fatalf("Error getting manager: %s", err)
+	}
+
+	result, err := FromManager(manager)
+	if err != nil {
+		t.Fatalf("Error converting manager: %s", err)
+	}
+	return result
+}
+
+// TestLenovoServiceData tests collecting and downloading service data.
+func TestLenovoServiceData(t *testing.T) {
+	header := make(http.Header)
+	header.Set("Location", "/redfish/v1/TaskService/Tasks/FFDC_1")
+	ffdcData := "tzz\x00compressed-ffdc"
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {
+				common.TestResponse(http.StatusOK, managerBody),
+				common.TestResponse(http.StatusOK, serviceDataBody),
+				common.TestResponse(http.StatusOK, strings.NewReplacer(`"%s"`, `"Running"`, "%d", "10").Replace(ffdcTaskBody)),
+				common.TestResponse(http.StatusOK, strings.NewReplacer(`"%s"`, `"Completed"`, "%d", "100").Replace(ffdcTaskBody)),
+				common.TestResponse(http.StatusOK, ffdcData),
+			},
+			http.MethodPost: {&http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(bytes.NewBufferString("")), Header: header}},
+		},
+	}
+
+	serviceData, err := getXCCManager(t, testClient).ServiceData()
+	if err != nil {
+		t.Fatalf("Error getting service data: %s", err)
+	}
+
+	task, err := serviceData.ExportFFDCData(&ExportFFDCDataRequest{
+		DataCollectionType:   ServiceDataFileDataCollectionType,
+		InitializationNeeded: true,
+	})
+	if err != nil {
+		t.Fatalf("Error exporting FFDC data: %s", err)
+	}
+	if _, err := FFDCDataDownloadURI(task); err == nil {
+		t.Error("Expected a running collection to have no download")
+	}
+
+	var buf bytes.Buffer
+	written, err := serviceData.DownloadFFDCData(&buf, task)
+	if err != nil {
+		t.Fatalf("Error downloading FFDC data: %s", err)
+	}
+	if written != int64(len(ffdcData)) || buf.String() != ffdcData {
+		t.Errorf("Unexpected FFDC data: %q", buf.String())
+	}
+
+	calls := testClient.CapturedCalls()
+	if calls[2].Payload != "map[DataCollectionType:ServiceDataFile InitializationNeeded:true]" {
+		t.Errorf("Unexpected export payload: %s", calls[2].Payload)
+	}
+	if calls[len(calls)-1].URL != "/redfish/v1/Managers/1/Oem/Lenovo/ServiceData/J300ABCD_xcc_230321-101237.tzz" {
+		t.Errorf("Unexpected download call: %+v", calls[len(calls)-1])
+	}
+}
+
+// TestLenovoFoDKeys tests listing and installing Features on Demand keys.
+func TestLenovoFoDKeys(t *testing.T) {
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {
+				common.TestResponse(http.StatusOK, managerBody),
+				common.TestResponse(http.StatusOK, fodBody),
+				common.TestResponse(http.StatusOK, fodKeysBody),
+				common.TestResponse(http.StatusOK, fodKeyBody),
+			},
+			http.MethodPost: {
+				common.TestResponse(http.StatusCreated, fodKeyBody),
+				common.TestResponse(http.StatusCreated, ""),
+			},
+		},
+	}
+
+	fod, err := getXCCManager(t, testClient).FoD()
+	if err != nil {
+		t.Fatalf("Error getting FoD: %s", err)
+	}
+
+	keys, err := fod.Keys()
+	if err != nil {
+		t.Fatalf("Error getting FoD keys: %s", err)
+	}
+	if len(keys) != 1 || keys[0].Expires != "NEVER" || keys[0].Status.State != common.EnabledState {
+		t.Errorf("Unexpected keys: %+v", keys)
+	}
+
+	keyFile := []byte("activation-key-file")
+	key, err := fod.InstallKey(keyFile)
+	if err != nil {
+		t.Fatalf("Error installing FoD key: %s", err)
+	}
+	if key.ID != "4C5D8E1A" {
+		t.Errorf("Unexpected key: %+v", key)
+	}
+
+	calls := testClient.CapturedCalls()
+	install := calls[len(calls)-1]
+	if install.URL != "/redfish/v1/Managers/1/Oem/Lenovo/FoD/Keys" ||
+		install.Payload != "map[Activationkey:"+base64.StdEncoding.EncodeToString(keyFile)+"]" {
+		t.Errorf("Unexpected install call: %+v", install)
+	}
+
+	if key, err := fod.InstallKey(keyFile); err == nil {
+		t.Errorf("Expected an error when the installed key cannot be located, got %+v", key)
+	}
+}
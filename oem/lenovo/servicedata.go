@@ -0,0 +1,168 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package lenovo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+// DataCollectionType is the kind of service data to collect.
+type DataCollectionType string
+
+const (
+	// ServiceDataFileDataCollectionType collects the service data file of
+	// the XClarity Controller.
+	ServiceDataFileDataCollectionType DataCollectionType = "ServiceDataFile"
+	// ProcessorDumpDataCollectionType collects a dump of the host
+	// processors.
+	ProcessorDumpDataCollectionType DataCollectionType = "ProcessorDump"
+	// BMCDumpDataCollectionType collects a dump of the XClarity Controller.
+	BMCDumpDataCollectionType DataCollectionType = "BMCDump"
+)
+
+// ExportFFDCDataRequest are the parameters of a service data collection.
+type ExportFFDCDataRequest struct {
+	// DataCollectionType is the kind of service data to collect.
+	DataCollectionType DataCollectionType
+	// InitializationNeeded collects fresh data rather than returning the
+	// last collection.
+	InitializationNeeded bool
+	// ExportURI is the location the data is uploaded to, such as
+	// 'sftp://10.0.0.1/ffdc'. If empty, the data is kept on the controller
+	// and downloaded with DownloadFFDCData.
+	ExportURI string `json:",omitempty"`
+	// Username is the user name for the export location.
+	Username string `json:",omitempty"`
+	// Password is the password for the export location.
+	Password string `json:",omitempty"`
+}
+
+// ServiceData is the service data collection of an XClarity Controller,
+// which gathers First Failure Data Capture (FFDC) data for support cases.
+type ServiceData struct {
+	common.Entity
+
+	// ODataType is the odata type.
+	ODataType string `json:"@odata.type"`
+	// Description provides a description of this resource.
+	Description string
+
+	exportFFDCDataTarget string
+}
+
+// UnmarshalJSON unmarshals a ServiceData object from the raw JSON.
+func (servicedata *ServiceData) UnmarshalJSON(b []byte) error {
+	type temp ServiceData
+	var t struct {
+		temp
+		Actions struct {
+			ExportFFDCData common.ActionTarget `json:"#LenovoServiceData.ExportFFDCData"`
+		}
+	}
+
+	err := json.Unmarshal(b, &t)
+	if err != nil {
+		return err
+	}
+
+	*servicedata = ServiceData(t.temp)
+	servicedata.exportFFDCDataTarget = t.Actions.ExportFFDCData.Target
+
+	return nil
+}
+
+// GetServiceData will get a ServiceData instance from the service.
+func GetServiceData(c common.Client, uri string) (*ServiceData, error) {
+	var servicedata ServiceData
+	return &servicedata, servicedata.Get(c, uri, &servicedata)
+}
+
+// ExportFFDCData starts a service data collection. The collection can take
+// several minutes; its progress can be followed with the returned task.
+func (servicedata *ServiceData) ExportFFDCData(request *ExportFFDCDataRequest) (*redfish.Task, error) {
+	if servicedata.exportFFDCDataTarget == "" {
+		return nil, errors.New("ExportFFDCData is not supported by this service")
+	}
+	if request == nil || request.DataCollectionType == "" {
+		return nil, errors.New("data collection type should not be empty")
+	}
+
+	resp, err := servicedata.PostWithResponse(servicedata.exportFFDCDataTarget, request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	task, err := redfish.TaskFromResponse(servicedata.GetClient(), resp)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, errors.New("ExportFFDCData did not return a task")
+	}
+	return task, nil
+}
+
+// FFDCDataDownloadURI returns the location of the service data collected by a
+// completed task, if it was kept on the controller.
+func FFDCDataDownloadURI(task *redfish.Task) (string, error) {
+	if task.TaskState != redfish.CompletedTaskState {
+		return "", fmt.Errorf("service data collection %s is %s", task.ID, task.TaskState)
+	}
+
+	var oem struct {
+		Lenovo struct {
+			FFDCForDownloading struct {
+				DownloadURI  string
+				FFDCFileName string
+			}
+		}
+	}
+	if len(task.Oem) > 0 {
+		if err := json.Unmarshal(task.Oem, &oem); err != nil {
+			return "", err
+		}
+	}
+	if oem.Lenovo.FFDCForDownloading.DownloadURI == "" {
+		return "", fmt.Errorf("service data collection %s has no data to download", task.ID)
+	}
+	return oem.Lenovo.FFDCForDownloading.DownloadURI, nil
+}
+
+// DownloadFFDCData writes the service data collected by a completed task to w
+// and returns the number of bytes written. The task is read again to get its
+// current state.
+func (servicedata *ServiceData) DownloadFFDCData(w io.Writer, task *redfish.Task) (int64, error) {
+	current, err := redfish.GetTask(servicedata.GetClient(), task.ODataID)
+	if err != nil {
+		return 0, err
+	}
+
+	uri, err := FFDCDataDownloadURI(current)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := servicedata.GetClient().Get(uri)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	written, err := io.Copy(w, resp.Body)
+	if err != nil {
+		return written, err
+	}
+	if resp.ContentLength >= 0 && written != resp.ContentLength {
+		return written, fmt.Errorf("service data download was truncated: got %d of %d bytes", written, resp.ContentLength)
+	}
+	return written, nil
+}
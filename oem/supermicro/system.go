@@ -0,0 +1,165 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package supermicro
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+func init() {
+	common.RegisterOEMVendor("Supermicro", "SMC")
+	common.RegisterOEMDecoder("Supermicro", "ComputerSystem", func(resource *common.OEMResource) (interface{}, error) {
+		var system redfish.ComputerSystem
+		if err := resource.Entity.As(&system); err != nil {
+			return nil, err
+		}
+		system.SetClient(resource.Entity.GetClient())
+		return FromComputerSystem(&system)
+	})
+}
+
+// ComputerSystem is the Supermicro-specific handler for a ComputerSystem.
+type ComputerSystem struct {
+	redfish.ComputerSystem
+
+	fixedBootOrder string
+}
+
+// FromComputerSystem converts a standard ComputerSystem object to the OEM
+// implementation.
+func FromComputerSystem(system *redfish.ComputerSystem) (*ComputerSystem, error) {
+	cs := &ComputerSystem{ComputerSystem: *system}
+
+	if len(system.Oem) > 0 {
+		var oem struct {
+			Supermicro struct {
+				FixedBootOrder common.Link
+			}
+		}
+		if err := json.Unmarshal(system.Oem, &oem); err != nil {
+			return nil, err
+		}
+		cs.fixedBootOrder = oem.Supermicro.FixedBootOrder.String()
+	}
+
+	cs.SetClient(system.GetClient())
+	return cs, nil
+}
+
+// FixedBootOrder gets the fixed boot order of the BIOS.
+func (system *ComputerSystem) FixedBootOrder() (*FixedBootOrder, error) {
+	if system.fixedBootOrder == "" {
+		return nil, errors.New("fixed boot order is not supported by this system")
+	}
+	return GetFixedBootOrder(system.GetClient(), system.fixedBootOrder)
+}
+
+// FixedBootOrder is the boot order of a Supermicro BIOS, which is used
+// instead of the BootOrder of the system. Changes are applied at the next
+// boot.
+type FixedBootOrder struct {
+	common.Entity
+
+	// ODataType is the odata type.
+	ODataType string `json:"@odata.type"`
+	// BootModeSelected is the boot mode of the BIOS, such as 'UEFI' or
+	// 'Legacy'.
+	BootModeSelected string
+	// FixedBootOrder are the boot devices in boot order, such as
+	// 'UEFI Hard Disk:UEFI OS (Samsung SSD 860)'.
+	FixedBootOrder []string
+	// FixedBootOrderDisabledItem are the boot devices that are disabled.
+	FixedBootOrderDisabledItem []string
+	// UEFINetwork are the UEFI network boot devices in boot order.
+	UEFINetwork []string
+	// UEFIHardDisk are the UEFI hard disk boot devices in boot order.
+	UEFIHardDisk []string
+	// UEFIUSBKey are the UEFI USB key boot devices in boot order.
+	UEFIUSBKey []string
+	// rawData holds the original serialized JSON so we can compare updates.
+	rawData []byte
+}
+
+// UnmarshalJSON unmarshals a FixedBootOrder object from the raw JSON.
+func (fixedbootorder *FixedBootOrder) UnmarshalJSON(b []byte) error {
+	type temp FixedBootOrder
+	var t struct {
+		temp
+	}
+
+	err := json.Unmarshal(b, &t)
+	if err != nil {
+		return err
+	}
+
+	*fixedbootorder = FixedBootOrder(t.temp)
+
+	// This is a read/write object, so we need to save the raw object data for later
+	fixedbootorder.rawData = b
+
+	return nil
+}
+
+// Update commits updates to this object's properties to the running system.
+func (fixedbootorder *FixedBootOrder) Update() error {
+	// Get a representation of the object's original state so we can find what
+	// to update.
+	original := new(FixedBootOrder)
+	err := original.UnmarshalJSON(fixedbootorder.rawData)
+	if err != nil {
+		return err
+	}
+
+	readWriteFields := []string{
+		"FixedBootOrder",
+		"UEFINetwork",
+		"UEFIHardDisk",
+		"UEFIUSBKey",
+	}
+
+	originalElement := reflect.ValueOf(original).Elem()
+	currentElement := reflect.ValueOf(fixedbootorder).Elem()
+
+	return fixedbootorder.Entity.Update(originalElement, currentElement, readWriteFields)
+}
+
+// GetFixedBootOrder will get a FixedBootOrder instance from the service.
+func GetFixedBootOrder(c common.Client, uri string) (*FixedBootOrder, error) {
+	var fixedbootorder FixedBootOrder
+	return &fixedbootorder, fixedbootorder.Get(c, uri, &fixedbootorder)
+}
+
+// BootFirst moves a boot device to the front of the fixed boot order and
+// commits the change. The device is matched by its full name or by its prefix,
+// such as 'UEFI Network'.
+func (fixedbootorder *FixedBootOrder) BootFirst(device string) error {
+	index := -1
+	for i, entry := range fixedbootorder.FixedBootOrder {
+		if entry == device {
+			index = i
+			break
+		}
+		if index < 0 && strings.HasPrefix(entry, device) {
+			index = i
+		}
+	}
+	if index < 0 {
+		return errors.New("boot device " + device + " is not in the fixed boot order")
+	}
+
+	order := make([]string, 0, len(fixedbootorder.FixedBootOrder))
+	order = append(order, fixedbootorder.FixedBootOrder[index])
+	order = append(order, fixedbootorder.FixedBootOrder[:index]...)
+	order = append(order, fixedbootorder.FixedBootOrder[index+1:]...)
+	fixedbootorder.FixedBootOrder = order
+
+	return fixedbootorder.Update()
+}
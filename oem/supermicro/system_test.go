@@ -0,0 +1,107 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package supermicro
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+const systemBody = `{
+    "@odata.type": "#ComputerSystem.v1_16_0.ComputerSystem",
+    "@odata.id": "/redfish/v1/Systems/1",
+    "Id": "1",
+    "Name": "System",
+    "Manufacturer": "Supermicro",
+    "Model": "SYS-120U-TNR",
+    "PowerState": "On",
+    "Actions": {
+        "#ComputerSystem.Reset": {
+            "target": "/redfish/v1/Systems/1/Actions/ComputerSystem.Reset",
+            "@Redfish.ActionInfo": "/redfish/v1/Systems/1/ResetActionInfo"
+        }
+    },
+    "Oem": {
+        "Supermicro": {
+            "@odata.type": "#SmcSystemExtensions.v1_0_0.ComputerSystem",
+            "FixedBootOrder": {
+                "@odata.id": "/redfish/v1/Systems/1/Oem/Supermicro/FixedBootOrder"
+            },
+            "NodeManager": {
+                "@odata.id": "/redfish/v1/Managers/1/Oem/Supermicro/NodeManager"
+            }
+        }
+    }
+}`
+
+const fixedBootOrderBody = `{
+    "@odata.type": "#SmcFixedBootOrder.v1_0_0.SmcFixedBootOrder",
+    "@odata.id": "/redfish/v1/Systems/1/Oem/Supermicro/FixedBootOrder",
+    "Id": "FixedBootOrder",
+    "Name": "Fixed Boot Order",
+    "BootModeSelected": "UEFI",
+    "FixedBootOrder": [
+        "UEFI Hard Disk:UEFI OS (SAMSUNG MZ1LB960HAJQ-00007)",
+        "UEFI USB Key",
+        "UEFI Network:UEFI: PXE IPv4 Intel(R) Ethernet Controller X550",
+        "Disabled"
+    ],
+    "FixedBootOrderDisabledItem": [
+        "UEFI CD/DVD",
+        "UEFI USB Hard Disk"
+    ],
+    "UEFINetwork": [
+        "UEFI: PXE IPv4 Intel(R) Ethernet Controller X550"
+    ]
+}`
+
+// TestSupermicroFixedBootOrder tests reading and changing the fixed boot order.
+func TestSupermicroFixedBootOrder(t *testing.T) {
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {common.TestResponse(http.StatusOK, systemBody), common.TestResponse(http.StatusOK, fixedBootOrderBody)},
+		},
+	}
+
+	system, err := redfish.GetComputerSystem(testClient, "/redfish/v1/Systems/1")
+	if err != nil {
+		t.Fatalf("Error getting system: %s", err)
+	}
+
+	smcSystem, err := FromComputerSystem(system)
+	if err != nil {
+		t.Fatalf("Error converting system: %s", err)
+	}
+
+	bootOrder, err := smcSystem.FixedBootOrder()
+	if err != nil {
+		t.Fatalf("Error getting fixed boot order: %s", err)
+	}
+	if bootOrder.BootModeSelected != "UEFI" || len(bootOrder.FixedBootOrder) != 4 || len(bootOrder.FixedBootOrderDisabledItem) != 2 {
+		t.Errorf("Unexpected fixed boot order: %+v", bootOrder)
+	}
+
+	if err := bootOrder.BootFirst("UEFI Network"); err != nil {
+		t.Fatalf("Error changing fixed boot order: %s", err)
+	}
+	if !strings.HasPrefix(bootOrder.FixedBootOrder[0], "UEFI Network") || !strings.HasPrefix(bootOrder.FixedBootOrder[1], "UEFI Hard Disk") {
+		t.Errorf("Unexpected fixed boot order: %v", bootOrder.FixedBootOrder)
+	}
+
+	calls := testClient.CapturedCalls()
+	call := calls[len(calls)-1]
+	if call.Action != http.MethodPatch || call.URL != "/redfish/v1/Systems/1/Oem/Supermicro/FixedBootOrder" ||
+		!strings.HasPrefix(call.Payload, "map[FixedBootOrder:[UEFI Network") {
+		t.Errorf("Unexpected update call: %+v", call)
+	}
+
+	if err := bootOrder.BootFirst("UEFI CD/DVD"); err == nil {
+		t.Error("Expected a disabled boot device to fail")
+	}
+}
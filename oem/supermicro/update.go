@@ -0,0 +1,155 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package supermicro
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+func init() {
+	common.RegisterOEMDecoder("Supermicro", "UpdateService", func(resource *common.OEMResource) (interface{}, error) {
+		var updateService redfish.UpdateService
+		if err := resource.Entity.As(&updateService); err != nil {
+			return nil, err
+		}
+		updateService.SetClient(resource.Entity.GetClient())
+		return FromUpdateService(&updateService)
+	})
+}
+
+// The default targets and upload location of Supermicro BMCs.
+const (
+	defaultBMCTarget  = "/redfish/v1/Managers/1"
+	defaultBIOSTarget = "/redfish/v1/Systems/1/Bios"
+	defaultUploadURI  = "/redfish/v1/UpdateService/upload"
+)
+
+// BMCUpdateOptions are the Supermicro options of a BMC firmware update.
+type BMCUpdateOptions struct {
+	// Target is the manager to update. The default is /redfish/v1/Managers/1.
+	Target string `json:"-"`
+	// PreserveCfg keeps the BMC configuration.
+	PreserveCfg bool
+	// PreserveSdr keeps the sensor data records.
+	PreserveSdr bool
+	// PreserveSsl keeps the SSL certificate.
+	PreserveSsl bool
+	// BackupBMC also updates the backup BMC image.
+	BackupBMC bool
+}
+
+// BIOSUpdateOptions are the Supermicro options of a BIOS firmware update.
+type BIOSUpdateOptions struct {
+	// Target is the BIOS to update. The default is /redfish/v1/Systems/1/Bios.
+	Target string `json:"-"`
+	// PreserveME keeps the Management Engine region.
+	PreserveME bool
+	// PreserveNVRAM keeps the BIOS settings.
+	PreserveNVRAM bool
+	// PreserveSMBIOS keeps the SMBIOS data.
+	PreserveSMBIOS bool
+	// PreserveBOOTCONF keeps the boot configuration.
+	PreserveBOOTCONF bool
+	// BackupBIOS also updates the backup BIOS image.
+	BackupBIOS bool
+}
+
+// updateParameters are the UpdateParameters part of a multipart firmware
+// update.
+type updateParameters struct {
+	Targets        []string
+	OperationApply string `json:"@Redfish.OperationApplyTime"`
+	Oem            struct {
+		Supermicro map[string]interface{}
+	}
+}
+
+// UpdateService is the Supermicro-specific handler for the UpdateService.
+type UpdateService struct {
+	redfish.UpdateService
+}
+
+// FromUpdateService converts a standard UpdateService object to the OEM
+// implementation.
+func FromUpdateService(updateService *redfish.UpdateService) (*UpdateService, error) {
+	us := &UpdateService{UpdateService: *updateService}
+	us.SetClient(updateService.GetClient())
+	return us, nil
+}
+
+// UpdateBMC uploads a BMC firmware image and starts the update. The BMC
+// restarts once the update is applied; its progress can be followed with the
+// returned task.
+func (updateService *UpdateService) UpdateBMC(image *os.File, options *BMCUpdateOptions) (*redfish.Task, error) {
+	if options == nil {
+		options = &BMCUpdateOptions{PreserveCfg: true, PreserveSdr: true, PreserveSsl: true}
+	}
+	target := options.Target
+	if target == "" {
+		target = defaultBMCTarget
+	}
+	return updateService.upload(image, target, "BMC", options)
+}
+
+// UpdateBIOS uploads a BIOS firmware image and starts the update. The
+// update is applied right away, but only takes effect after the next reboot
+// of the system.
+func (updateService *UpdateService) UpdateBIOS(image *os.File, options *BIOSUpdateOptions) (*redfish.Task, error) {
+	if options == nil {
+		options = &BIOSUpdateOptions{PreserveME: true, PreserveNVRAM: true, PreserveSMBIOS: true, PreserveBOOTCONF: true}
+	}
+	target := options.Target
+	if target == "" {
+		target = defaultBIOSTarget
+	}
+	return updateService.upload(image, target, "BIOS", options)
+}
+
+// upload posts a firmware image with the Supermicro options of a component.
+func (updateService *UpdateService) upload(image *os.File, target, component string, options interface{}) (*redfish.Task, error) {
+	if image == nil {
+		return nil, errors.New("firmware image should not be empty")
+	}
+
+	parameters := updateParameters{
+		Targets:        []string{target},
+		OperationApply: "Immediate",
+	}
+	parameters.Oem.Supermicro = map[string]interface{}{component: options}
+	b, err := json.Marshal(parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	uri := updateService.MultipartHTTPPushURI
+	if uri == "" {
+		uri = defaultUploadURI
+	}
+
+	resp, err := updateService.GetClient().PostMultipart(uri, map[string]io.Reader{
+		"UpdateParameters": strings.NewReader(string(b)),
+		"UpdateFile":       image,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	task, err := redfish.TaskFromResponse(updateService.GetClient(), resp)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, errors.New("firmware update did not return a task")
+	}
+	return task, nil
+}
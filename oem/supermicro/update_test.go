@@ -0,0 +1,123 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package supermicro
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+const updateServiceBody = `{
+    "@odata.type": "#UpdateService.v1_8_4.UpdateService",
+    "@odata.id": "/redfish/v1/UpdateService",
+    "Id": "UpdateService",
+    "Name": "Update Service",
+    "ServiceEnabled": true,
+    "MultipartHttpPushUri": "/redfish/v1/UpdateService/upload",
+    "FirmwareInventory": {
+        "@odata.id": "/redfish/v1/UpdateService/FirmwareInventory"
+    }
+}`
+
+const updateTaskBody = `{
+    "@odata.type": "#Task.v1_4_3.Task",
+    "@odata.id": "/redfish/v1/TaskService/Tasks/1",
+    "Id": "1",
+    "Name": "BMC Update",
+    "TaskState": "Running",
+    "PercentComplete": 0
+}`
+
+// multipartClient records the parts of multipart requests.
+type multipartClient struct {
+	*common.TestClient
+	url   string
+	parts map[string]string
+}
+
+func (c *multipartClient) PostMultipart(url string, payload map[string]io.Reader) (*http.Response, error) {
+	c.url = url
+	c.parts = make(map[string]string)
+	for key, reader := range payload {
+		b, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		c.parts[key] = string(b)
+	}
+
+	header := make(http.Header)
+	header.Set("Location", "/redfish/v1/TaskService/Tasks/1")
+	return &http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(bytes.NewBufferString("")), Header: header}, nil
+}
+
+// TestSupermicroUpdateBMC tests uploading a BMC firmware image with the
+// Supermicro update options.
+func TestSupermicroUpdateBMC(t *testing.T) {
+	testClient := &multipartClient{TestClient: &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {common.TestResponse(http.StatusOK, updateServiceBody), common.TestResponse(http.StatusOK, updateTaskBody)},
+		},
+	}}
+
+	updateService, err := redfish.GetUpdateService(testClient, "/redfish/v1/UpdateService")
+	if err != nil {
+		t.Fatalf("Error getting update service: %s", err)
+	}
+	smcUpdateService, err := FromUpdateService(updateService)
+	if err != nil {
+		t.Fatalf("Error converting update service: %s", err)
+	}
+
+	image, err := os.CreateTemp(t.TempDir(), "BMC_X12AST2600-ROT-5201MS_20230321_01.14.20_STDsp.bin")
+	if err != nil {
+		t.Fatalf("Error creating image: %s", err)
+	}
+	defer image.Close()
+	if _, err := image.WriteString("firmware"); err != nil {
+		t.Fatalf("Error writing image: %s", err)
+	}
+	if _, err := image.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Error seeking image: %s", err)
+	}
+
+	task, err := smcUpdateService.UpdateBMC(image, &BMCUpdateOptions{PreserveCfg: true, PreserveSdr: true, BackupBMC: true})
+	if err != nil {
+		t.Fatalf("Error updating BMC: %s", err)
+	}
+	if task.ID != "1" || task.TaskState != redfish.RunningTaskState {
+		t.Errorf("Unexpected task: %+v", task)
+	}
+
+	if testClient.url != "/redfish/v1/UpdateService/upload" || testClient.parts["UpdateFile"] != "firmware" {
+		t.Errorf("Unexpected upload: %s %v", testClient.url, testClient.parts)
+	}
+
+	var parameters struct {
+		Targets        []string
+		OperationApply string `json:"@Redfish.OperationApplyTime"`
+		Oem            struct {
+			Supermicro struct {
+				BMC BMCUpdateOptions
+			}
+		}
+	}
+	if err := json.Unmarshal([]byte(testClient.parts["UpdateParameters"]), &parameters); err != nil {
+		t.Fatalf("Error decoding update parameters: %s", err)
+	}
+	bmc := parameters.Oem.Supermicro.BMC
+	if strings.Join(parameters.Targets, ",") != "/redfish/v1/Managers/1" || parameters.OperationApply != "Immediate" ||
+		!bmc.PreserveCfg || !bmc.PreserveSdr || bmc.PreserveSsl || !bmc.BackupBMC {
+		t.Errorf("Unexpected update parameters: %s", testClient.parts["UpdateParameters"])
+	}
+}
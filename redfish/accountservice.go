@@ -6,6 +6,8 @@ package redfish
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"reflect"
 
 	"github.com/stmcginnis/gofish/common"
@@ -521,3 +523,105 @@ func (accountservice *AccountService) CreateAccount(userName, password, roleID s
 	err = json.NewDecoder(resp.Body).Decode(&result)
 	return &result, err
 }
+
+// DeleteAccount deletes a user account. The request is conditional on the etag
+// of the account, so it fails if the account was modified since it was
+// fetched.
+func (accountservice *AccountService) DeleteAccount(account *ManagerAccount) error {
+	_, err := DeleteEntity(accountservice.GetClient(), &account.Entity)
+	return err
+}
+
+// CreateRole creates a custom role with the given Redfish and OEM privileges.
+func (accountservice *AccountService) CreateRole(roleID string, privileges []PrivilegeType, oemPrivileges []string) (*Role, error) {
+	if accountservice.roles == "" {
+		return nil, errors.New("roles are not supported by this account service")
+	}
+
+	payload := struct {
+		RoleID             string `json:"RoleId"`
+		AssignedPrivileges []PrivilegeType
+		OemPrivileges      []string `json:",omitempty"`
+	}{
+		RoleID:             roleID,
+		AssignedPrivileges: privileges,
+		OemPrivileges:      oemPrivileges,
+	}
+
+	var role Role
+	task, err := CreateResource(accountservice.GetClient(), accountservice.roles, &payload, &role)
+	if err != nil {
+		return nil, err
+	}
+	if task != nil {
+		return nil, errors.New("service created the role asynchronously")
+	}
+	role.SetClient(accountservice.GetClient())
+	return &role, nil
+}
+
+// UpdateRole replaces the Redfish and OEM privileges of a custom role.
+// Predefined roles cannot be modified.
+func (accountservice *AccountService) UpdateRole(role *Role, privileges []PrivilegeType, oemPrivileges []string) error {
+	if role.IsPredefined {
+		return fmt.Errorf("role %s is predefined and cannot be modified", role.RoleID)
+	}
+
+	assignedPrivileges, assignedOemPrivileges := role.AssignedPrivileges, role.OemPrivileges
+	role.AssignedPrivileges = privileges
+	role.OemPrivileges = oemPrivileges
+	if err := role.Update(); err != nil {
+		role.AssignedPrivileges, role.OemPrivileges = assignedPrivileges, assignedOemPrivileges
+		return err
+	}
+	return nil
+}
+
+// DeleteRole deletes a custom role. Predefined roles cannot be deleted.
+func (accountservice *AccountService) DeleteRole(role *Role) error {
+	if role.IsPredefined {
+		return fmt.Errorf("role %s is predefined and cannot be deleted", role.RoleID)
+	}
+	_, err := DeleteEntity(accountservice.GetClient(), &role.Entity)
+	return err
+}
+
+// AccountRole gets the role of an account, looking it up by its RoleId if the
+// account does not link to it.
+func (accountservice *AccountService) AccountRole(account *ManagerAccount) (*Role, error) {
+	role, err := account.Role()
+	if err != nil || role != nil {
+		return role, err
+	}
+
+	roles, err := accountservice.Roles()
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		if role.RoleID == account.RoleID || role.ID == account.RoleID {
+			return role, nil
+		}
+	}
+	return nil, fmt.Errorf("role %s of account %s not found", account.RoleID, account.UserName)
+}
+
+// CheckPrivilege evaluates whether an account may perform an operation,
+// according to the privilege map of this account service and the privileges
+// of the account's role.
+func (accountservice *AccountService) CheckPrivilege(account *ManagerAccount, request *PrivilegeRequest) (*PrivilegeDecision, error) {
+	registry, err := accountservice.PrivilegeMap()
+	if err != nil {
+		return nil, err
+	}
+	if registry == nil {
+		return nil, errors.New("privilege map is not supported by this account service")
+	}
+
+	role, err := accountservice.AccountRole(account)
+	if err != nil {
+		return nil, err
+	}
+
+	return registry.CheckRole(role, request)
+}
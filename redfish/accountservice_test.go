@@ -6,6 +6,7 @@ package redfish
 
 import (
 	"encoding/json"
+	"net/http"
 	"strings"
 	"testing"
 
@@ -327,3 +328,64 @@ func TestAccountServiceUpdate(t *testing.T) {
 		t.Errorf("Unexpected update payload: %s", calls[0].Payload)
 	}
 }
+
+// TestAccountServiceRoles tests creating and deleting custom roles.
+func TestAccountServiceRoles(t *testing.T) {
+	var result AccountService
+	err := json.NewDecoder(strings.NewReader(accountServiceBody)).Decode(&result)
+	if err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodPost: {getCall(`{
+				"@odata.id": "/redfish/v1/AccountService/Roles/Auditor",
+				"Id": "Auditor",
+				"RoleId": "Auditor",
+				"AssignedPrivileges": ["Login"],
+				"IsPredefined": false
+			}`)},
+		},
+	}
+	result.SetClient(testClient)
+
+	role, err := result.CreateRole("Auditor", []PrivilegeType{LoginPrivilegeType}, nil)
+	if err != nil {
+		t.Fatalf("Error making CreateRole call: %s", err)
+	}
+
+	if err := result.UpdateRole(role, []PrivilegeType{LoginPrivilegeType, ConfigureSelfPrivilegeType}, nil); err != nil {
+		t.Errorf("Error making UpdateRole call: %s", err)
+	}
+
+	if err := result.UpdateRole(&Role{RoleID: "Administrator", IsPredefined: true}, nil, nil); err == nil {
+		t.Error("Expected modifying a predefined role to fail")
+	}
+
+	if err := result.DeleteRole(role); err != nil {
+		t.Errorf("Error making DeleteRole call: %s", err)
+	}
+
+	if err := result.DeleteRole(&Role{RoleID: "Administrator", IsPredefined: true}); err == nil {
+		t.Error("Expected deleting a predefined role to fail")
+	}
+
+	calls := testClient.CapturedCalls()
+	if len(calls) != 3 {
+		t.Fatalf("Expected three calls to be made, captured: %v", calls)
+	}
+
+	if calls[0].URL != "/redfish/v1/AccountService/Roles" || !strings.Contains(calls[0].Payload, "RoleId:Auditor") {
+		t.Errorf("Unexpected CreateRole call: %v", calls[0])
+	}
+
+	if calls[1].Action != http.MethodPatch || calls[1].URL != "/redfish/v1/AccountService/Roles/Auditor" ||
+		calls[1].Payload != "map[AssignedPrivileges:[Login ConfigureSelf]]" {
+		t.Errorf("Unexpected UpdateRole call: %v", calls[1])
+	}
+
+	if calls[2].Action != http.MethodDelete || calls[2].URL != "/redfish/v1/AccountService/Roles/Auditor" {
+		t.Errorf("Unexpected DeleteRole call: %v", calls[2])
+	}
+}
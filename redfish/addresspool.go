@@ -79,20 +79,8 @@ func (addresspool *AddressPool) UnmarshalJSON(b []byte) error {
 
 // GetAddressPool will get a AddressPool instance from the service.
 func GetAddressPool(c common.Client, uri string) (*AddressPool, error) {
-	resp, err := c.Get(uri)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
 	var addresspool AddressPool
-	err = json.NewDecoder(resp.Body).Decode(&addresspool)
-	if err != nil {
-		return nil, err
-	}
-
-	addresspool.SetClient(c)
-	return &addresspool, nil
+	return &addresspool, addresspool.Get(c, uri, &addresspool)
 }
 
 // ListReferencedAddressPools gets the collection of AddressPool from
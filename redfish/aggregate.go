@@ -134,20 +134,8 @@ func (aggregate *Aggregate) SetDefaultBootOrder() error {
 
 // GetAggregate will get a Aggregate instance from the service.
 func GetAggregate(c common.Client, uri string) (*Aggregate, error) {
-	resp, err := c.Get(uri)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
 	var aggregate Aggregate
-	err = json.NewDecoder(resp.Body).Decode(&aggregate)
-	if err != nil {
-		return nil, err
-	}
-
-	aggregate.SetClient(c)
-	return &aggregate, nil
+	return &aggregate, aggregate.Get(c, uri, &aggregate)
 }
 
 // ListReferencedAggregates gets the collection of Aggregate from
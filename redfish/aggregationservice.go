@@ -6,7 +6,9 @@ package redfish
 
 import (
 	"encoding/json"
+	"errors"
 	"reflect"
+	"sort"
 
 	"github.com/stmcginnis/gofish/common"
 )
@@ -125,6 +127,160 @@ func (aggregationservice *AggregationService) ConnectionMethods() ([]*Connection
 	return ListReferencedConnectionMethods(aggregationservice.GetClient(), aggregationservice.connectionMethods)
 }
 
+// AggregationSourceRequest describes an aggregation source to create.
+type AggregationSourceRequest struct {
+	// Name is the name of the aggregation source.
+	Name string `json:",omitempty"`
+	// HostName is the URI of the system to aggregate, such as
+	// 'https://bmc1.example.com'.
+	HostName string
+	// UserName and Password are the credentials used to access the source.
+	UserName string `json:",omitempty"`
+	Password string `json:",omitempty"`
+	// AggregationType is the type of aggregation. Services default to full
+	// aggregation if not set.
+	AggregationType AggregationType `json:",omitempty"`
+	// SNMP contains the SNMP settings of a source accessed with SNMP.
+	SNMP *SNMPSettings `json:",omitempty"`
+	// ConnectionMethod is the URI of the connection method used to access
+	// the source.
+	ConnectionMethod string `json:"-"`
+}
+
+// CreateAggregationSource adds a source to aggregate. Services that connect
+// to the source asynchronously return a task, in which case the source is
+// nil.
+func (aggregationservice *AggregationService) CreateAggregationSource(request *AggregationSourceRequest) (*AggregationSource, *Task, error) {
+	if aggregationservice.aggregationSources == "" {
+		return nil, nil, errors.New("aggregation sources are not supported by this aggregation service")
+	}
+	if request.HostName == "" {
+		return nil, nil, errors.New("host name of the aggregation source should not be empty")
+	}
+
+	payload := struct {
+		*AggregationSourceRequest
+		Links *struct {
+			ConnectionMethod common.IDRef
+		} `json:",omitempty"`
+	}{AggregationSourceRequest: request}
+	if request.ConnectionMethod != "" {
+		payload.Links = &struct {
+			ConnectionMethod common.IDRef
+		}{ConnectionMethod: common.IDRef{ODataID: request.ConnectionMethod}}
+	}
+
+	var source AggregationSource
+	task, err := CreateResource(aggregationservice.GetClient(), aggregationservice.aggregationSources, &payload, &source)
+	if err != nil || task != nil {
+		return nil, task, err
+	}
+	source.SetClient(aggregationservice.GetClient())
+	return &source, nil, nil
+}
+
+// DeleteAggregationSource removes an aggregation source, which removes the
+// resources added through it from the service.
+func (aggregationservice *AggregationService) DeleteAggregationSource(source *AggregationSource) (*Task, error) {
+	return DeleteEntity(aggregationservice.GetClient(), &source.Entity)
+}
+
+// CreateAggregate creates an aggregate of the resources with the given URIs.
+func (aggregationservice *AggregationService) CreateAggregate(name string, elements []string) (*Aggregate, error) {
+	if aggregationservice.aggregates == "" {
+		return nil, errors.New("aggregates are not supported by this aggregation service")
+	}
+
+	payload := struct {
+		Name     string `json:",omitempty"`
+		Elements []common.IDRef
+	}{
+		Name:     name,
+		Elements: common.IDRefs(elements),
+	}
+
+	var aggregate Aggregate
+	task, err := CreateResource(aggregationservice.GetClient(), aggregationservice.aggregates, &payload, &aggregate)
+	if err != nil {
+		return nil, err
+	}
+	if task != nil {
+		return nil, errors.New("service created the aggregate asynchronously")
+	}
+	aggregate.SetClient(aggregationservice.GetClient())
+	return &aggregate, nil
+}
+
+// DeleteAggregate deletes an aggregate. The resources in it are not affected.
+func (aggregationservice *AggregationService) DeleteAggregate(aggregate *Aggregate) error {
+	_, err := DeleteEntity(aggregationservice.GetClient(), &aggregate.Entity)
+	return err
+}
+
+// AggregationSourceHealth is the reachability of an aggregation source.
+type AggregationSourceHealth struct {
+	// URI is the URI of the aggregation source.
+	URI string
+	// Source is the aggregation source, or nil if it could not be read.
+	Source *AggregationSource
+	// Reachable is true if the service reports it can reach the source.
+	Reachable bool
+	// Reason describes why the source is not reachable.
+	Reason string
+}
+
+// AggregationSourcesHealth reports the reachability of every aggregation
+// source, ordered by URI. Sources that cannot be read are reported as
+// unreachable rather than failing the whole report.
+func (aggregationservice *AggregationService) AggregationSourcesHealth() ([]AggregationSourceHealth, error) {
+	sources, err := aggregationservice.AggregationSources()
+
+	var collectionError *common.CollectionError
+	if err != nil && !errors.As(err, &collectionError) {
+		return nil, err
+	}
+
+	result := make([]AggregationSourceHealth, 0, len(sources))
+	for _, source := range sources {
+		reachable, reason := source.Reachable()
+		result = append(result, AggregationSourceHealth{
+			URI:       source.ODataID,
+			Source:    source,
+			Reachable: reachable,
+			Reason:    reason,
+		})
+	}
+	if collectionError != nil {
+		for uri, failure := range collectionError.Failures {
+			if uri == aggregationservice.aggregationSources {
+				// The collection itself could not be read
+				return nil, failure
+			}
+			result = append(result, AggregationSourceHealth{URI: uri, Reason: failure.Error()})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].URI < result[j].URI })
+	return result, nil
+}
+
+// UnreachableAggregationSources reports the aggregation sources the service
+// cannot reach.
+func (aggregationservice *AggregationService) UnreachableAggregationSources() ([]AggregationSourceHealth, error) {
+	health, err := aggregationservice.AggregationSourcesHealth()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []AggregationSourceHealth
+	for i := range health {
+		if !health[i].Reachable {
+			result = append(result, health[i])
+		}
+	}
+	return result, nil
+}
+
 // Update commits updates to this object's properties to the running system.
 func (aggregationservice *AggregationService) Update() error {
 	// Get a representation of the object's original state so we can find what
@@ -144,20 +300,8 @@ func (aggregationservice *AggregationService) Update() error {
 
 // GetAggregationService will get a AggregationService instance from the service.
 func GetAggregationService(c common.Client, uri string) (*AggregationService, error) {
-	resp, err := c.Get(uri)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
 	var aggregationservice AggregationService
-	err = json.NewDecoder(resp.Body).Decode(&aggregationservice)
-	if err != nil {
-		return nil, err
-	}
-
-	aggregationservice.SetClient(c)
-	return &aggregationservice, nil
+	return &aggregationservice, aggregationservice.Get(c, uri, &aggregationservice)
 }
 
 // ListReferencedAggregationServices gets the collection of AggregationService from
@@ -6,6 +6,7 @@ package redfish
 
 import (
 	"encoding/json"
+	"net/http"
 	"strings"
 	"testing"
 
@@ -141,3 +142,126 @@ func TestAggregationServiceSetDefaultBootOrder(t *testing.T) {
 		t.Errorf("Target system not found in payload: %s", calls[0].Payload)
 	}
 }
+
+// TestAggregationServiceCreateAggregationSource tests adding and removing an aggregation source.
+func TestAggregationServiceCreateAggregationSource(t *testing.T) {
+	var result AggregationService
+	err := json.NewDecoder(strings.NewReader(aggregationServiceBody)).Decode(&result)
+	if err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodPost: {
+				getCall(`{"@odata.id": "/redfish/v1/AggregationService/AggregationSources/BMC1",
+					"@odata.etag": "\"1\"", "Id": "BMC1", "HostName": "https://bmc1.example.com"}`),
+			},
+		},
+	}
+	result.SetClient(testClient)
+
+	source, task, err := result.CreateAggregationSource(&AggregationSourceRequest{
+		HostName:         "https://bmc1.example.com",
+		UserName:         "admin",
+		Password:         "secret",
+		ConnectionMethod: "/redfish/v1/AggregationService/ConnectionMethods/Redfish",
+	})
+	if err != nil {
+		t.Fatalf("Error making CreateAggregationSource call: %s", err)
+	}
+	if task != nil || source.ID != "BMC1" {
+		t.Errorf("Unexpected aggregation source: %+v %+v", source, task)
+	}
+
+	if _, err := result.DeleteAggregationSource(source); err != nil {
+		t.Errorf("Error making DeleteAggregationSource call: %s", err)
+	}
+
+	calls := testClient.CapturedCalls()
+	if len(calls) != 2 {
+		t.Fatalf("Expected two calls to be made, captured: %v", calls)
+	}
+	if calls[0].URL != "/redfish/v1/AggregationService/AggregationSources" ||
+		!strings.Contains(calls[0].Payload, "HostName:https://bmc1.example.com") ||
+		!strings.Contains(calls[0].Payload, "Links:map[ConnectionMethod:map[@odata.id:/redfish/v1/AggregationService/ConnectionMethods/Redfish]]") {
+		t.Errorf("Unexpected create call: %+v", calls[0])
+	}
+
+	// The etag of a created source is only known once it is fetched
+	if calls[1].Action != http.MethodDelete || calls[1].URL != "/redfish/v1/AggregationService/AggregationSources/BMC1" {
+		t.Errorf("Unexpected delete call: %+v", calls[1])
+	}
+}
+
+// TestAggregationServiceCreateAggregate tests creating an aggregate.
+func TestAggregationServiceCreateAggregate(t *testing.T) {
+	var result AggregationService
+	err := json.NewDecoder(strings.NewReader(aggregationServiceBody)).Decode(&result)
+	if err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodPost: {
+				getCall(`{"@odata.id": "/redfish/v1/AggregationService/Aggregates/Rack1", "Id": "Rack1",
+					"Elements": [{"@odata.id": "/redfish/v1/Systems/1"}]}`),
+			},
+		},
+	}
+	result.SetClient(testClient)
+
+	aggregate, err := result.CreateAggregate("Rack1", []string{"/redfish/v1/Systems/1"})
+	if err != nil {
+		t.Fatalf("Error making CreateAggregate call: %s", err)
+	}
+	assertEquals(t, "Rack1", aggregate.ID)
+
+	if err := result.DeleteAggregate(aggregate); err != nil {
+		t.Errorf("Error making DeleteAggregate call: %s", err)
+	}
+
+	calls := testClient.CapturedCalls()
+	if !strings.Contains(calls[0].Payload, "Elements:[map[@odata.id:/redfish/v1/Systems/1]]") {
+		t.Errorf("Unexpected create payload: %s", calls[0].Payload)
+	}
+	if calls[1].URL != "/redfish/v1/AggregationService/Aggregates/Rack1" {
+		t.Errorf("Unexpected delete call: %+v", calls[1])
+	}
+}
+
+// TestAggregationServiceUnreachableAggregationSources tests the health view of aggregation sources.
+func TestAggregationServiceUnreachableAggregationSources(t *testing.T) {
+	var result AggregationService
+	err := json.NewDecoder(strings.NewReader(aggregationServiceBody)).Decode(&result)
+	if err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {
+				getCall(`{"Members": [{"@odata.id": "/redfish/v1/AggregationService/AggregationSources/BMC2"}]}`),
+				getCall(`{"@odata.id": "/redfish/v1/AggregationService/AggregationSources/BMC2", "Id": "BMC2",
+					"Status": {"State": "UnavailableOffline", "Health": "Critical"}}`),
+			},
+		},
+	}
+	result.SetClient(testClient)
+
+	unreachable, err := result.UnreachableAggregationSources()
+	if err != nil {
+		t.Fatalf("Error making UnreachableAggregationSources call: %s", err)
+	}
+	if len(unreachable) != 1 {
+		t.Fatalf("Expected one unreachable source, got: %+v", unreachable)
+	}
+	assertEquals(t, "/redfish/v1/AggregationService/AggregationSources/BMC2", unreachable[0].URI)
+	assertEquals(t, "state is UnavailableOffline", unreachable[0].Reason)
+
+	source := AggregationSource{Status: common.Status{State: common.EnabledState, Health: common.WarningHealth}}
+	if reachable, _ := source.Reachable(); !reachable {
+		t.Error("Expected a source with warnings to be reachable")
+	}
+}
@@ -6,6 +6,7 @@ package redfish
 
 import (
 	"encoding/json"
+	"fmt"
 	"reflect"
 
 	"github.com/stmcginnis/gofish/common"
@@ -188,22 +189,57 @@ func (aggregationsource *AggregationSource) Update() error {
 	return aggregationsource.Entity.Update(originalElement, currentElement, readWriteFields)
 }
 
-// GetAggregationSource will get a AggregationSource instance from the service.
-func GetAggregationSource(c common.Client, uri string) (*AggregationSource, error) {
-	resp, err := c.Get(uri)
-	if err != nil {
-		return nil, err
+// ConnectionMethod gets the connection method used to access the aggregation
+// source. The result is nil if the source does not link to one.
+func (aggregationsource *AggregationSource) ConnectionMethod() (*ConnectionMethod, error) {
+	if aggregationsource.connectionMethod == "" {
+		return nil, nil
 	}
-	defer resp.Body.Close()
+	return GetConnectionMethod(aggregationsource.GetClient(), aggregationsource.connectionMethod)
+}
 
-	var aggregationsource AggregationSource
-	err = json.NewDecoder(resp.Body).Decode(&aggregationsource)
-	if err != nil {
-		return nil, err
+// ResourcesAccessed gets the resources added to the service through the
+// aggregation source.
+func (aggregationsource *AggregationSource) ResourcesAccessed() ([]*Resource, error) {
+	var result []*Resource
+
+	collectionError := common.NewCollectionError()
+	for _, uri := range aggregationsource.resourcesAccessed {
+		resource, err := GetResource(aggregationsource.GetClient(), uri)
+		if err != nil {
+			collectionError.Failures[uri] = err
+		} else {
+			result = append(result, resource)
+		}
 	}
 
-	aggregationsource.SetClient(c)
-	return &aggregationsource, nil
+	if collectionError.Empty() {
+		return result, nil
+	}
+
+	return result, collectionError
+}
+
+// Reachable reports whether the service can reach the aggregation source,
+// according to its status. If it cannot, the reason describes the status.
+func (aggregationsource *AggregationSource) Reachable() (reachable bool, reason string) {
+	status := aggregationsource.Status
+	switch status.State {
+	case common.UnavailableOfflineState, common.AbsentState:
+		return false, fmt.Sprintf("state is %s", status.State)
+	case common.DisabledState:
+		return false, "aggregation source is disabled"
+	}
+	if status.Health == common.CriticalHealth {
+		return false, fmt.Sprintf("health is %s", status.Health)
+	}
+	return true, ""
+}
+
+// GetAggregationSource will get a AggregationSource instance from the service.
+func GetAggregationSource(c common.Client, uri string) (*AggregationSource, error) {
+	var aggregationsource AggregationSource
+	return &aggregationsource, aggregationsource.Get(c, uri, &aggregationsource)
 }
 
 // ListReferencedAggregationSources gets the collection of AggregationSource from
@@ -0,0 +1,536 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+// ParseCertificateString parses a PEM encoded certificate or certificate
+// chain. The first certificate returned is the leaf certificate.
+func ParseCertificateString(certificateString string) ([]*x509.Certificate, error) {
+	var result []*x509.Certificate
+
+	rest := []byte(certificateString)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, certificate)
+	}
+
+	if len(result) == 0 {
+		return nil, errors.New("no PEM encoded certificate found")
+	}
+	return result, nil
+}
+
+// X509 parses the certificate string of this certificate. If the certificate
+// is a chain, the leaf certificate is returned.
+func (certificate *Certificate) X509() (*x509.Certificate, error) {
+	if certificate.CertificateType == PKCS7CertificateType {
+		return nil, errors.New("parsing of PKCS7 certificates is not supported")
+	}
+
+	chain, err := ParseCertificateString(certificate.CertificateString)
+	if err != nil {
+		return nil, err
+	}
+	return chain[0], nil
+}
+
+// CertificateInfo is a certificate installed on the service along with its
+// parsed contents.
+type CertificateInfo struct {
+	// Certificate is the certificate resource.
+	Certificate *Certificate
+	// Chain is the parsed certificate string, starting with the leaf
+	// certificate.
+	Chain []*x509.Certificate
+	// Error is set if the certificate string could not be parsed.
+	Error error
+}
+
+// Leaf returns the leaf certificate, or nil if it could not be parsed.
+func (info *CertificateInfo) Leaf() *x509.Certificate {
+	if len(info.Chain) == 0 {
+		return nil
+	}
+	return info.Chain[0]
+}
+
+// NotAfter returns when the certificate expires.
+func (info *CertificateInfo) NotAfter() time.Time {
+	if leaf := info.Leaf(); leaf != nil {
+		return leaf.NotAfter
+	}
+	return time.Time{}
+}
+
+// ExpiresWithin reports whether the certificate expires before now plus the
+// given duration. Certificates that could not be parsed are reported as
+// expiring so they are not overlooked.
+func (info *CertificateInfo) ExpiresWithin(d time.Duration) bool {
+	leaf := info.Leaf()
+	return leaf == nil || time.Now().Add(d).After(leaf.NotAfter)
+}
+
+// SubjectAlternativeNames returns the DNS names and IP addresses the
+// certificate is valid for.
+func (info *CertificateInfo) SubjectAlternativeNames() []string {
+	leaf := info.Leaf()
+	if leaf == nil {
+		return nil
+	}
+
+	result := append([]string{}, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		result = append(result, ip.String())
+	}
+	return result
+}
+
+// Issuer returns the issuer of the certificate.
+func (info *CertificateInfo) Issuer() pkix.Name {
+	if leaf := info.Leaf(); leaf != nil {
+		return leaf.Issuer
+	}
+	return pkix.Name{}
+}
+
+// NewCertificateInfo parses a certificate resource.
+func NewCertificateInfo(certificate *Certificate) *CertificateInfo {
+	info := &CertificateInfo{Certificate: certificate}
+	if certificate.CertificateType == PKCS7CertificateType {
+		info.Error = errors.New("parsing of PKCS7 certificates is not supported")
+		return info
+	}
+	info.Chain, info.Error = ParseCertificateString(certificate.CertificateString)
+	return info
+}
+
+// CertificateInventory gets every certificate installed on the service and
+// parses their contents.
+func (certificateservice *CertificateService) CertificateInventory() ([]*CertificateInfo, error) {
+	locations, err := certificateservice.CertificateLocations()
+	if err != nil {
+		return nil, err
+	}
+	if locations == nil {
+		return nil, errors.New("certificate locations are not supported by this certificate service")
+	}
+
+	certificates, err := locations.Certificates()
+	result := make([]*CertificateInfo, 0, len(certificates))
+	for _, certificate := range certificates {
+		result = append(result, NewCertificateInfo(certificate))
+	}
+	return result, err
+}
+
+// CertificateSigner signs certificate signing requests.
+type CertificateSigner interface {
+	// SignCSR signs a parsed certificate signing request and returns the PEM
+	// encoded certificate, optionally followed by its issuing chain.
+	SignCSR(csr *x509.CertificateRequest) (string, error)
+}
+
+// LocalCA is a certificate authority that signs requests with a key held in
+// memory. It is intended for tests and lab environments without access to a
+// real certificate authority.
+type LocalCA struct {
+	// Certificate is the certificate of the CA.
+	Certificate *x509.Certificate
+	// Validity is how long issued certificates are valid for.
+	Validity time.Duration
+
+	key    crypto.Signer
+	mu     sync.Mutex
+	serial *big.Int
+}
+
+// NewLocalCA creates a self-signed certificate authority.
+func NewLocalCA(commonName string) (*LocalCA, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, err
+	}
+
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LocalCA{
+		Certificate: certificate,
+		Validity:    365 * 24 * time.Hour,
+		key:         key,
+		serial:      big.NewInt(1),
+	}, nil
+}
+
+// CertificatePEM returns the PEM encoded certificate of the CA.
+func (ca *LocalCA) CertificatePEM() string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Certificate.Raw}))
+}
+
+// SignCSR signs a certificate signing request for server authentication.
+func (ca *LocalCA) SignCSR(csr *x509.CertificateRequest) (string, error) {
+	if err := csr.CheckSignature(); err != nil {
+		return "", err
+	}
+
+	ca.mu.Lock()
+	ca.serial.Add(ca.serial, big.NewInt(1))
+	serial := new(big.Int).Set(ca.serial)
+	ca.mu.Unlock()
+
+	template := &x509.Certificate{
+		SerialNumber:   serial,
+		Subject:        csr.Subject,
+		DNSNames:       csr.DNSNames,
+		IPAddresses:    csr.IPAddresses,
+		EmailAddresses: csr.EmailAddresses,
+		NotBefore:      time.Now().Add(-time.Hour),
+		NotAfter:       time.Now().Add(ca.Validity),
+		KeyUsage:       x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Certificate, csr.PublicKey, ca.key)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})), nil
+}
+
+// parseCSR parses a PEM encoded certificate signing request and checks its
+// signature.
+func parseCSR(csrString string) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode([]byte(csrString))
+	if block == nil {
+		return nil, errors.New("no PEM encoded certificate signing request found")
+	}
+
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	return csr, csr.CheckSignature()
+}
+
+// CertificateRotation is the result of rotating a certificate.
+type CertificateRotation struct {
+	// CSR is the certificate signing request generated by the service.
+	CSR *x509.CertificateRequest
+	// CertificateString is the signed certificate that was installed.
+	CertificateString string
+	// Certificate is the parsed leaf of the installed certificate.
+	Certificate *x509.Certificate
+	// Location is the URI of the installed certificate, if the service
+	// returned it.
+	Location string
+	// Verified is set if the installed certificate was seen being served.
+	Verified bool
+}
+
+// defaultServedCertificateTimeout is how long RotateCertificate waits for the
+// installed certificate to be served if no timeout is configured.
+const defaultServedCertificateTimeout = 5 * time.Minute
+
+// defaultServedCertificateInterval is the interval between checks of the
+// served certificate if none is configured.
+const defaultServedCertificateInterval = 10 * time.Second
+
+// ServedCertificateCheck configures how RotateCertificate verifies that the
+// installed certificate is served.
+type ServedCertificateCheck struct {
+	// Address is the host and port of the TLS endpoint that serves the
+	// certificate. If empty and the certificate is installed in the HTTPS
+	// certificates of a manager, the address of the Redfish service is used
+	// if the client exposes it.
+	Address string
+	// Timeout is how long to wait for the certificate to be served, as
+	// services commonly restart their web server to apply it. It defaults to
+	// 5 minutes.
+	Timeout time.Duration
+	// Interval is the interval between checks. It defaults to 10 seconds.
+	Interval time.Duration
+}
+
+// servedCertificateAddress returns the address of the TLS endpoint serving
+// the certificates of the collection, or an empty string if it is unknown.
+func servedCertificateAddress(c interface{}, collection string) string {
+	if !strings.Contains(collection, "/NetworkProtocol/HTTPS/Certificates") {
+		return ""
+	}
+	client, ok := c.(interface{ Endpoint() string })
+	if !ok {
+		return ""
+	}
+
+	endpoint, err := url.Parse(client.Endpoint())
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return ""
+	}
+	if endpoint.Port() == "" {
+		return net.JoinHostPort(endpoint.Hostname(), "443")
+	}
+	return endpoint.Host
+}
+
+// generateRotationCSR has the service generate a certificate signing request
+// and parses it. Unlike GenerateCSR, the certificate collection is sent as a
+// link, as required by the schema.
+func (certificateservice *CertificateService) generateRotationCSR(request *GenerateCSRRequest) (*x509.CertificateRequest, error) {
+	type temp GenerateCSRRequest
+	payload := struct {
+		*temp
+		CertificateCollection common.IDRef
+	}{
+		temp:                  (*temp)(request),
+		CertificateCollection: common.IDRef{ODataID: request.CertificateCollection},
+	}
+
+	resp, err := certificateservice.PostWithResponse(certificateservice.generateCSRTarget, &payload)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var csrResponse GenerateCSRResponse
+	if err := json.NewDecoder(resp.Body).Decode(&csrResponse); err != nil {
+		return nil, err
+	}
+
+	csr, err := parseCSR(csrResponse.CSRString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate signing request returned by service: %w", err)
+	}
+	return csr, nil
+}
+
+// signRotation signs a certificate signing request and parses the signed
+// certificate.
+func signRotation(csr *x509.CertificateRequest, signer CertificateSigner) (*CertificateRotation, CertificateType, error) {
+	certificateString, err := signer.SignCSR(csr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	chain, err := ParseCertificateString(certificateString)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid certificate returned by signer: %w", err)
+	}
+
+	certificateType := PEMCertificateType
+	if len(chain) > 1 {
+		certificateType = PEMChainCertificateType
+	}
+
+	return &CertificateRotation{
+		CSR:               csr,
+		CertificateString: certificateString,
+		Certificate:       chain[0],
+	}, certificateType, nil
+}
+
+// installRotation installs a signed certificate, either replacing the given
+// certificate or adding it to the collection, and records its location.
+// Unlike ReplaceCertificate, the replaced certificate is sent as a link, as
+// required by the schema.
+func (certificateservice *CertificateService) installRotation(certificate *Certificate, collection string, rotation *CertificateRotation, certificateType CertificateType) error {
+	if certificate == nil {
+		payload := struct {
+			CertificateString string
+			CertificateType   CertificateType
+		}{
+			CertificateString: rotation.CertificateString,
+			CertificateType:   certificateType,
+		}
+
+		resp, err := certificateservice.GetClient().Post(collection, payload)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		rotation.Location = locationFromResponse(resp)
+		return nil
+	}
+
+	if certificateservice.replaceCertificateTarget == "" {
+		return errors.New("ReplaceCertificate is not supported by this certificate service")
+	}
+
+	payload := struct {
+		CertificateString string
+		CertificateType   CertificateType
+		CertificateURI    common.IDRef `json:"CertificateUri"`
+	}{
+		CertificateString: rotation.CertificateString,
+		CertificateType:   certificateType,
+		CertificateURI:    common.IDRef{ODataID: certificate.ODataID},
+	}
+	if err := certificateservice.Post(certificateservice.replaceCertificateTarget, payload); err != nil {
+		return err
+	}
+	rotation.Location = certificate.ODataID
+	return nil
+}
+
+// verifyRotation waits for the installed certificate to be served by the TLS
+// endpoint of the check.
+func (certificateservice *CertificateService) verifyRotation(check ServedCertificateCheck, collection string, rotation *CertificateRotation) error {
+	if check.Address == "" {
+		check.Address = servedCertificateAddress(certificateservice.GetClient(), collection)
+	}
+	if check.Address == "" {
+		return errors.New("the address serving the certificate is unknown")
+	}
+	if check.Timeout == 0 {
+		check.Timeout = defaultServedCertificateTimeout
+	}
+	if check.Interval == 0 {
+		check.Interval = defaultServedCertificateInterval
+	}
+
+	if err := WaitForServedCertificate(check.Address, rotation.Certificate, check.Timeout, check.Interval); err != nil {
+		return err
+	}
+	rotation.Verified = true
+	return nil
+}
+
+// RotateCertificate has the service generate a new key and certificate
+// signing request, signs the request with the signer and installs the signed
+// certificate.
+//
+// If `certificate` is given, it is replaced using the ReplaceCertificate
+// action and, unless set in the request, the certificate collection is the
+// one containing it. Otherwise the signed certificate is added to the
+// certificate collection of the request.
+//
+// If `check` is given, its TLS endpoint is then polled until it serves the
+// installed certificate. If the certificate is not served in time, the
+// rotation is returned along with the error.
+func (certificateservice *CertificateService) RotateCertificate(certificate *Certificate, request *GenerateCSRRequest, signer CertificateSigner, check *ServedCertificateCheck) (*CertificateRotation, error) {
+	if certificateservice.generateCSRTarget == "" {
+		return nil, errors.New("GenerateCSR is not supported by this certificate service")
+	}
+
+	csrRequest := *request
+	if csrRequest.CertificateCollection == "" && certificate != nil {
+		csrRequest.CertificateCollection = path.Dir(strings.TrimSuffix(certificate.ODataID, "/"))
+	}
+	if csrRequest.CertificateCollection == "" {
+		return nil, errors.New("a certificate collection is required to generate a CSR")
+	}
+
+	csr, err := certificateservice.generateRotationCSR(&csrRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	rotation, certificateType, err := signRotation(csr, signer)
+	if err != nil {
+		return nil, err
+	}
+
+	err = certificateservice.installRotation(certificate, csrRequest.CertificateCollection, rotation, certificateType)
+	if err != nil {
+		return nil, err
+	}
+
+	if check == nil {
+		return rotation, nil
+	}
+	return rotation, certificateservice.verifyRotation(*check, csrRequest.CertificateCollection, rotation)
+}
+
+// ServedCertificate connects to a TLS endpoint, such as the address of the
+// Redfish service, and returns the leaf certificate it presents. The
+// certificate is not verified.
+func ServedCertificate(address string, timeout time.Duration) (*x509.Certificate, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{
+		InsecureSkipVerify: true, //nolint:gosec
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	certificates := conn.ConnectionState().PeerCertificates
+	if len(certificates) == 0 {
+		return nil, errors.New("no certificate presented by " + address)
+	}
+	return certificates[0], nil
+}
+
+// WaitForServedCertificate polls a TLS endpoint until it presents the
+// expected certificate. Services commonly restart their web server to apply a
+// new certificate, so connection failures are retried until the timeout.
+func WaitForServedCertificate(address string, expected *x509.Certificate, timeout, interval time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		served, err := ServedCertificate(address, interval)
+		if err == nil && bytes.Equal(served.Raw, expected.Raw) {
+			return nil
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			if err != nil {
+				return fmt.Errorf("certificate served by %s could not be verified: %w", address, err)
+			}
+			return fmt.Errorf("%s is still serving certificate with serial %s", address, served.SerialNumber)
+		}
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,317 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+// testCSR creates a PEM encoded certificate signing request like a service
+// would return from GenerateCSR.
+func testCSR(t *testing.T, commonName string, dnsNames ...string) string {
+	csr, _ := testCSRWithKey(t, commonName, dnsNames...)
+	return csr
+}
+
+// testCSRWithKey creates a certificate signing request and returns it along
+// with its private key.
+func testCSRWithKey(t *testing.T, commonName string, dnsNames ...string) (string, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err)
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: commonName},
+		DNSNames: dnsNames,
+	}, key)
+	if err != nil {
+		t.Fatalf("Error creating CSR: %s", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})), key
+}
+
+// TestCertificateInfo tests parsing the certificate string of a certificate.
+func TestCertificateInfo(t *testing.T) {
+	ca, err := NewLocalCA("Test CA")
+	if err != nil {
+		t.Fatalf("Error creating CA: %s", err)
+	}
+	ca.Validity = 24 * time.Hour
+
+	csr, err := parseCSR(testCSR(t, "bmc.example.com", "bmc.example.com", "bmc"))
+	if err != nil {
+		t.Fatalf("Error parsing CSR: %s", err)
+	}
+
+	signed, err := ca.SignCSR(csr)
+	if err != nil {
+		t.Fatalf("Error signing CSR: %s", err)
+	}
+
+	info := NewCertificateInfo(&Certificate{
+		CertificateString: signed + ca.CertificatePEM(),
+		CertificateType:   PEMChainCertificateType,
+	})
+	if info.Error != nil {
+		t.Fatalf("Error parsing certificate: %s", info.Error)
+	}
+
+	if len(info.Chain) != 2 {
+		t.Errorf("Expected a chain of 2 certificates, got %d", len(info.Chain))
+	}
+
+	if info.Issuer().CommonName != "Test CA" {
+		t.Errorf("Unexpected issuer: %s", info.Issuer())
+	}
+
+	if names := info.SubjectAlternativeNames(); len(names) != 2 || names[1] != "bmc" {
+		t.Errorf("Unexpected SANs: %v", names)
+	}
+
+	if info.ExpiresWithin(time.Hour) {
+		t.Error("Certificate should not expire within an hour")
+	}
+
+	if !info.ExpiresWithin(48 * time.Hour) {
+		t.Error("Certificate should expire within two days")
+	}
+
+	invalid := NewCertificateInfo(&Certificate{CertificateString: "garbage"})
+	if invalid.Error == nil || !invalid.ExpiresWithin(0) {
+		t.Error("Unparseable certificate should be reported as an error and as expiring")
+	}
+}
+
+// TestRotateCertificate tests generating a CSR, signing it and replacing a certificate.
+func TestRotateCertificate(t *testing.T) {
+	var service CertificateService
+	if err := json.NewDecoder(strings.NewReader(serviceBody)).Decode(&service); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	csrResponse, _ := json.Marshal(map[string]interface{}{
+		"CSRString": testCSR(t, "bmc.example.com", "bmc.example.com"),
+		"CertificateCollection": map[string]string{
+			"@odata.id": "/redfish/v1/Managers/1/NetworkProtocol/HTTPS/Certificates",
+		},
+	})
+
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodPost: {getCall(string(csrResponse)), nil, getCall(string(csrResponse)), nil},
+		},
+	}
+	service.SetClient(testClient)
+
+	ca, err := NewLocalCA("Test CA")
+	if err != nil {
+		t.Fatalf("Error creating CA: %s", err)
+	}
+
+	current := &Certificate{}
+	current.ODataID = "/redfish/v1/Managers/1/NetworkProtocol/HTTPS/Certificates/1"
+
+	rotation, err := service.RotateCertificate(current, &GenerateCSRRequest{CommonName: "bmc.example.com"}, ca, nil)
+	if err != nil {
+		t.Fatalf("Error rotating certificate: %s", err)
+	}
+
+	if rotation.Certificate.Subject.CommonName != "bmc.example.com" {
+		t.Errorf("Unexpected subject: %s", rotation.Certificate.Subject)
+	}
+
+	if err := rotation.Certificate.CheckSignatureFrom(ca.Certificate); err != nil {
+		t.Errorf("Certificate not signed by CA: %s", err)
+	}
+
+	if rotation.Verified {
+		t.Error("Certificate should not be verified without a TLS endpoint")
+	}
+
+	calls := testClient.CapturedCalls()
+	if len(calls) != 2 {
+		t.Fatalf("Expected GenerateCSR and ReplaceCertificate calls, got: %v", calls)
+	}
+
+	if !strings.Contains(calls[0].Payload, "CertificateCollection:map[@odata.id:/redfish/v1/Managers/1/NetworkProtocol/HTTPS/Certificates]") {
+		t.Errorf("Unexpected GenerateCSR payload: %s", calls[0].Payload)
+	}
+
+	if calls[1].URL != "/redfish/v1/CertificateService/Actions/CertificateService.ReplaceCertificate" {
+		t.Errorf("Unexpected ReplaceCertificate URL: %s", calls[1].URL)
+	}
+
+	if !strings.Contains(calls[1].Payload, "BEGIN CERTIFICATE") ||
+		!strings.Contains(calls[1].Payload, "CertificateUri:map[@odata.id:/redfish/v1/Managers/1/NetworkProtocol/HTTPS/Certificates/1]") {
+		t.Errorf("Unexpected ReplaceCertificate payload: %s", calls[1].Payload)
+	}
+
+	// Checking the served certificate is opt-in and fails if the address
+	// serving it cannot be determined.
+	rotation, err = service.RotateCertificate(current, &GenerateCSRRequest{CommonName: "bmc.example.com"}, ca, &ServedCertificateCheck{})
+	if err == nil || rotation == nil || rotation.Verified {
+		t.Errorf("Expected the check of an unknown address to fail: %v %v", rotation, err)
+	}
+}
+
+// rotatingTLSServer is a TLS endpoint that starts serving the certificate
+// signed during a rotation once ReplaceCertificate has been called.
+type rotatingTLSServer struct {
+	mu       sync.Mutex
+	key      *ecdsa.PrivateKey
+	signed   []byte
+	replaced bool
+	listener net.Listener
+}
+
+func newRotatingTLSServer(t *testing.T, key *ecdsa.PrivateKey) *rotatingTLSServer {
+	server := &rotatingTLSServer{key: key}
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			server.mu.Lock()
+			defer server.mu.Unlock()
+			if !server.replaced {
+				return nil, errors.New("web server restarting")
+			}
+			return &tls.Certificate{Certificate: [][]byte{server.signed}, PrivateKey: server.key}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error listening: %s", err)
+	}
+	server.listener = listener
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+	return server
+}
+
+// sign signs the request with the CA and remembers the certificate to
+// serve.
+func (server *rotatingTLSServer) sign(ca *LocalCA, csr *x509.CertificateRequest) (string, error) {
+	signed, err := ca.SignCSR(csr)
+	if err != nil {
+		return "", err
+	}
+	chain, err := ParseCertificateString(signed)
+	if err != nil {
+		return "", err
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.signed = chain[0].Raw
+	return signed, nil
+}
+
+// signerFunc adapts a function to a CertificateSigner.
+type signerFunc func(csr *x509.CertificateRequest) (string, error)
+
+func (f signerFunc) SignCSR(csr *x509.CertificateRequest) (string, error) {
+	return f(csr)
+}
+
+// TestRotateCertificateVerify tests that rotating a certificate waits for the
+// new certificate to be served.
+func TestRotateCertificateVerify(t *testing.T) {
+	var service CertificateService
+	if err := json.NewDecoder(strings.NewReader(serviceBody)).Decode(&service); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	csr, key := testCSRWithKey(t, "bmc.example.com", "bmc.example.com")
+	csrResponse, _ := json.Marshal(map[string]interface{}{"CSRString": csr})
+	server := newRotatingTLSServer(t, key)
+	defer server.listener.Close()
+
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodPost: {getCall(string(csrResponse)), nil, getCall(string(csrResponse)), nil},
+		},
+	}
+	service.SetClient(testClient)
+
+	ca, err := NewLocalCA("Test CA")
+	if err != nil {
+		t.Fatalf("Error creating CA: %s", err)
+	}
+	signer := signerFunc(func(csr *x509.CertificateRequest) (string, error) {
+		return server.sign(ca, csr)
+	})
+
+	current := &Certificate{}
+	current.ODataID = "/redfish/v1/Managers/1/NetworkProtocol/HTTPS/Certificates/1"
+	check := &ServedCertificateCheck{
+		Address:  server.listener.Addr().String(),
+		Timeout:  300 * time.Millisecond,
+		Interval: 50 * time.Millisecond,
+	}
+
+	// The web server never picks up the new certificate.
+	rotation, err := service.RotateCertificate(current, &GenerateCSRRequest{CommonName: "bmc.example.com"}, signer, check)
+	if err == nil || rotation == nil || rotation.Verified {
+		t.Errorf("Expected the unserved certificate to fail verification: %v %v", rotation, err)
+	}
+
+	server.mu.Lock()
+	server.replaced = true
+	server.mu.Unlock()
+
+	rotation, err = service.RotateCertificate(current, &GenerateCSRRequest{CommonName: "bmc.example.com"}, signer, check)
+	if err != nil {
+		t.Fatalf("Error rotating certificate: %s", err)
+	}
+	if !rotation.Verified {
+		t.Error("Expected the served certificate to be verified")
+	}
+}
+
+// TestWaitForServedCertificate tests verifying the certificate served by an endpoint.
+func TestWaitForServedCertificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	address := strings.TrimPrefix(server.URL, "https://")
+
+	if err := WaitForServedCertificate(address, server.Certificate(), time.Second, 100*time.Millisecond); err != nil {
+		t.Errorf("Expected served certificate to match: %s", err)
+	}
+
+	ca, err := NewLocalCA("Other")
+	if err != nil {
+		t.Fatalf("Error creating CA: %s", err)
+	}
+
+	if err := WaitForServedCertificate(address, ca.Certificate, 200*time.Millisecond, 100*time.Millisecond); err == nil {
+		t.Error("Expected a different certificate to be detected")
+	}
+}
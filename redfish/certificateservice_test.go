@@ -6,8 +6,11 @@ package redfish
 
 import (
 	"encoding/json"
+	"net/http"
 	"strings"
 	"testing"
+
+	"github.com/stmcginnis/gofish/common"
 )
 
 var serviceBody = `{
@@ -53,3 +56,44 @@ func TestCertificateService(t *testing.T) {
 			result.certificateLocations)
 	}
 }
+
+// TestCertificateServiceActionPayloads tests that GenerateCSR and
+// ReplaceCertificate send the certificate collection and the certificate as
+// plain URIs.
+func TestCertificateServiceActionPayloads(t *testing.T) {
+	var result CertificateService
+	err := json.NewDecoder(strings.NewReader(serviceBody)).Decode(&result)
+
+	if err != nil {
+		t.Errorf("Error decoding JSON: %s", err)
+	}
+
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodPost: {getCall(`{"CSRString": ""}`), nil},
+		},
+	}
+	result.SetClient(testClient)
+
+	_, err = result.GenerateCSR(&GenerateCSRRequest{
+		CommonName:            "bmc.example.com",
+		CertificateCollection: "/redfish/v1/Managers/1/NetworkProtocol/HTTPS/Certificates",
+	})
+	if err != nil {
+		t.Errorf("Error making GenerateCSR call: %s", err)
+	}
+
+	err = result.ReplaceCertificate("cert", PEMCertificateType, "/redfish/v1/Managers/1/NetworkProtocol/HTTPS/Certificates/1")
+	if err != nil {
+		t.Errorf("Error making ReplaceCertificate call: %s", err)
+	}
+
+	calls := testClient.CapturedCalls()
+	if !strings.Contains(calls[0].Payload, "CertificateCollection:/redfish/v1/Managers/1/NetworkProtocol/HTTPS/Certificates ") ||
+		!strings.Contains(calls[0].Payload, "CommonName:bmc.example.com") {
+		t.Errorf("Unexpected GenerateCSR payload: %s", calls[0].Payload)
+	}
+	if !strings.Contains(calls[1].Payload, "CertificateUri:/redfish/v1/Managers/1/NetworkProtocol/HTTPS/Certificates/1") {
+		t.Errorf("Unexpected ReplaceCertificate payload: %s", calls[1].Payload)
+	}
+}
@@ -0,0 +1,651 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+)
+
+// MeasurementVerdict is the outcome of verifying the measurements of a
+// component against a reference manifest.
+type MeasurementVerdict string
+
+const (
+	// MatchMeasurementVerdict indicates the signed measurements match the
+	// reference manifest and were signed by a key that chains to one of the
+	// trusted roots.
+	MatchMeasurementVerdict MeasurementVerdict = "Match"
+	// UnanchoredMeasurementVerdict indicates the signed measurements match the
+	// reference manifest, but the signing key does not chain to a trusted
+	// root. The key was supplied by the service, so a compromised service
+	// could have forged the measurements.
+	UnanchoredMeasurementVerdict MeasurementVerdict = "Unanchored"
+	// MismatchMeasurementVerdict indicates at least one signed measurement
+	// differs from the reference manifest.
+	MismatchMeasurementVerdict MeasurementVerdict = "Mismatch"
+	// MissingMeasurementVerdict indicates a measurement listed in the
+	// reference manifest was not reported by the component.
+	MissingMeasurementVerdict MeasurementVerdict = "Missing"
+	// UnknownComponentMeasurementVerdict indicates the reference manifest has
+	// no entry for the component.
+	UnknownComponentMeasurementVerdict MeasurementVerdict = "UnknownComponent"
+	// UntrustedMeasurementVerdict indicates the measurements could not be
+	// trusted because the nonce, signature or certificate chain did not
+	// verify.
+	UntrustedMeasurementVerdict MeasurementVerdict = "Untrusted"
+)
+
+// ReferenceMeasurement is an expected SPDM measurement.
+type ReferenceMeasurement struct {
+	// Index is the index of the measurement block.
+	Index int
+	// Value is the hex-encoded expected measurement value.
+	Value string
+}
+
+// ReferenceComponent contains the expected measurements of a component.
+type ReferenceComponent struct {
+	// TargetComponentURI identifies the component, matching the
+	// TargetComponentURI property of its ComponentIntegrity resource.
+	TargetComponentURI string
+	// Measurements are the expected SPDM measurements.
+	Measurements []ReferenceMeasurement
+	// PCRDigest is the hex-encoded expected digest of the quoted PCRs of a
+	// TPM.
+	PCRDigest string
+}
+
+// ReferenceManifest contains the golden measurements of a set of components.
+type ReferenceManifest struct {
+	Components []ReferenceComponent
+}
+
+// LoadReferenceManifest reads a JSON encoded reference manifest.
+func LoadReferenceManifest(r io.Reader) (*ReferenceManifest, error) {
+	var manifest ReferenceManifest
+	if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// Component returns the reference measurements for a component, or nil if the
+// manifest has none.
+func (manifest *ReferenceManifest) Component(targetComponentURI string) *ReferenceComponent {
+	for i := range manifest.Components {
+		if manifest.Components[i].TargetComponentURI == targetComponentURI {
+			return &manifest.Components[i]
+		}
+	}
+	return nil
+}
+
+// MeasurementComparison is the comparison of one measurement against its
+// reference value.
+type MeasurementComparison struct {
+	// Index is the index of the SPDM measurement block, or -1 for a TPM PCR
+	// digest.
+	Index int
+	// Expected is the hex-encoded reference value.
+	Expected string
+	// Actual is the hex-encoded signed value, empty if it was not reported.
+	Actual string
+	// Verdict is the result of the comparison.
+	Verdict MeasurementVerdict
+}
+
+// MeasurementVerifyOptions controls how signed measurements are requested and
+// verified.
+type MeasurementVerifyOptions struct {
+	// Roots are the trusted root certificates. If nil, the certificate chain
+	// reported by the service is only checked for consistency, and
+	// measurements that match the manifest are reported as unanchored rather
+	// than as a match.
+	Roots *x509.CertPool
+	// TrustServicePublicKey accepts a bare public key reported by the service
+	// to verify SPDM signatures when no certificate is available. Such a key
+	// cannot be anchored, so matching measurements are reported as
+	// unanchored.
+	TrustServicePublicKey bool
+	// MeasurementIndices are the SPDM measurement blocks to request. If
+	// empty, all measurements are requested.
+	MeasurementIndices []int
+	// SlotID is the SPDM certificate slot used for signing.
+	SlotID int
+	// PCRSelection is the Base64-encoded TPML_PCR_SELECTION of the PCRs to
+	// quote from a TPM.
+	PCRSelection string
+	// Scheme is the Base64-encoded TPMT_SIG_SCHEME used by the TPM
+	// attestation key.
+	Scheme string
+}
+
+// ComponentVerification is the result of verifying the measurements of a
+// component.
+type ComponentVerification struct {
+	// Component is the verified component.
+	Component *ComponentIntegrity
+	// Verdict is the overall result for the component.
+	Verdict MeasurementVerdict
+	// Error describes why the measurements are untrusted.
+	Error error
+	// ChainAnchored indicates the signing certificate chains to one of the
+	// trusted roots.
+	ChainAnchored bool
+	// Measurements contains the comparison of each reference measurement.
+	Measurements []MeasurementComparison
+	// SPDM is the parsed SPDM transcript, if the component uses SPDM.
+	SPDM *SPDMMeasurements
+	// TPM is the parsed quote, if the component is a TPM.
+	TPM *TPMQuote
+}
+
+// anchor downgrades a match to unanchored if the signing key does not chain
+// to a trusted root.
+func (verification *ComponentVerification) anchor() *ComponentVerification {
+	if verification.Verdict == MatchMeasurementVerdict && !verification.ChainAnchored {
+		verification.Verdict = UnanchoredMeasurementVerdict
+	}
+	return verification
+}
+
+// untrusted marks the verification as untrusted.
+func (verification *ComponentVerification) untrusted(err error) *ComponentVerification {
+	verification.Verdict = UntrustedMeasurementVerdict
+	verification.Error = err
+	return verification
+}
+
+// GenerateMeasurementNonce generates a random hex-encoded nonce for requesting
+// signed measurements.
+func GenerateMeasurementNonce(size int) (string, error) {
+	nonce := make([]byte, size)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(nonce), nil
+}
+
+// spdmHash maps an SPDM BaseHashAlgo name to a hash function.
+func spdmHash(algorithm string) (crypto.Hash, error) {
+	switch algorithm {
+	case "TPM_ALG_SHA_256":
+		return crypto.SHA256, nil
+	case "TPM_ALG_SHA_384":
+		return crypto.SHA384, nil
+	case "TPM_ALG_SHA_512":
+		return crypto.SHA512, nil
+	}
+	return 0, fmt.Errorf("unsupported SPDM hashing algorithm '%s'", algorithm)
+}
+
+// spdmSignatureSize returns the size of signatures of an SPDM BaseAsymAlgo.
+func spdmSignatureSize(algorithm string) (int, error) {
+	switch algorithm {
+	case "TPM_ALG_RSASSA_2048", "TPM_ALG_RSAPSS_2048":
+		return 256, nil
+	case "TPM_ALG_RSASSA_3072", "TPM_ALG_RSAPSS_3072":
+		return 384, nil
+	case "TPM_ALG_RSASSA_4096", "TPM_ALG_RSAPSS_4096":
+		return 512, nil
+	case "TPM_ALG_ECDSA_ECC_NIST_P256", "EdDSA ed25519":
+		return 64, nil
+	case "TPM_ALG_ECDSA_ECC_NIST_P384":
+		return 96, nil
+	case "TPM_ALG_ECDSA_ECC_NIST_P521":
+		return 132, nil
+	}
+	return 0, fmt.Errorf("unsupported SPDM signing algorithm '%s'", algorithm)
+}
+
+// spdmSigningContext is the context of MEASUREMENTS signatures from SPDM 1.2.
+const spdmSigningContext = "responder-measurements signing"
+
+// spdmSignedMessage returns the message covered by the signature of a
+// transcript and whether it still needs to be hashed before verification.
+// SPDM 1.0 and 1.1 sign the transcript hash directly, while later versions
+// sign a prefixed message containing the transcript hash.
+func spdmSignedMessage(measurements *SPDMMeasurements, hash crypto.Hash) (message []byte, prehashed bool) {
+	h := hash.New()
+	h.Write(measurements.Signed)
+	digest := h.Sum(nil)
+
+	if measurements.Version < 0x12 {
+		return digest, true
+	}
+
+	prefix := fmt.Sprintf("dmtf-spdm-v%d.%d.*", measurements.Version>>4, measurements.Version&0x0F)
+	message = []byte(strings.Repeat(prefix, 4))
+	message = append(message, make([]byte, 36-len(spdmSigningContext))...)
+	message = append(message, spdmSigningContext...)
+	return append(message, digest...), false
+}
+
+// verifySignature verifies a signature with a public key. If the message is
+// not prehashed, it is hashed first, except for Ed25519 which signs the
+// message directly.
+func verifySignature(publicKey crypto.PublicKey, hash crypto.Hash, message []byte, prehashed, pss bool, signature []byte) error {
+	if key, ok := publicKey.(ed25519.PublicKey); ok {
+		if !ed25519.Verify(key, message, signature) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+
+	digest := message
+	if !prehashed {
+		h := hash.New()
+		h.Write(message)
+		digest = h.Sum(nil)
+	}
+
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		size := len(signature) / 2
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	case *rsa.PublicKey:
+		if pss {
+			return rsa.VerifyPSS(key, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto})
+		}
+		return rsa.VerifyPKCS1v15(key, hash, digest, signature)
+	}
+	return fmt.Errorf("unsupported public key type %T", publicKey)
+}
+
+// verifyCertificateChain finds the leaf certificate of a chain and verifies
+// it. If no roots are given, the self-signed certificates of the chain are
+// used as roots and the chain is reported as not anchored.
+func verifyCertificateChain(chain []*x509.Certificate, roots *x509.CertPool) (leaf *x509.Certificate, anchored bool, err error) {
+	// The leaf is the certificate that did not issue any other certificate
+	// of the chain, regardless of the order it was reported in.
+	for _, candidate := range chain {
+		issuer := false
+		for _, other := range chain {
+			if other != candidate && bytes.Equal(other.RawIssuer, candidate.RawSubject) {
+				issuer = true
+				break
+			}
+		}
+		if !issuer {
+			leaf = candidate
+			break
+		}
+	}
+	if leaf == nil {
+		return nil, false, errors.New("no leaf certificate found in chain")
+	}
+
+	anchored = roots != nil
+	intermediates := x509.NewCertPool()
+	if roots == nil {
+		roots = x509.NewCertPool()
+	}
+	for _, certificate := range chain {
+		if certificate == leaf {
+			continue
+		}
+		if !anchored && bytes.Equal(certificate.RawIssuer, certificate.RawSubject) {
+			roots.AddCert(certificate)
+		} else {
+			intermediates.AddCert(certificate)
+		}
+	}
+
+	if !anchored && len(chain) == 1 {
+		// A lone certificate can only be trusted by explicit roots.
+		return leaf, false, nil
+	}
+
+	_, err = leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return leaf, anchored, err
+}
+
+// signingCertificate gets the certificate chain used to sign measurements.
+func signingCertificate(certificate *Certificate, roots *x509.CertPool) (*x509.Certificate, bool, error) {
+	if certificate == nil {
+		return nil, false, errors.New("no certificate available to verify the signature")
+	}
+
+	chain, err := ParseCertificateString(certificate.CertificateString)
+	if err != nil {
+		return nil, false, err
+	}
+	return verifyCertificateChain(chain, roots)
+}
+
+// parsePEMPublicKey parses a PEM encoded public key.
+func parsePEMPublicKey(publicKey string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil {
+		return nil, errors.New("no PEM encoded public key found")
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+// parseSignedSPDMMeasurements decodes and parses the transcript of signed
+// SPDM measurements and checks it contains the requested nonce. The parsed
+// measurements are returned even if the nonce does not match.
+func parseSignedSPDMMeasurements(response *SPDMGetSignedMeasurementsResponse, nonce string) (*SPDMMeasurements, crypto.Hash, error) {
+	hash, err := spdmHash(response.HashingAlgorithm)
+	if err != nil {
+		return nil, 0, err
+	}
+	signatureSize, err := spdmSignatureSize(response.SigningAlgorithm)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	transcript, err := base64.StdEncoding.DecodeString(response.SignedMeasurements)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	measurements, err := ParseSPDMMeasurements(transcript, signatureSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if hex.EncodeToString(measurements.RequesterNonce) != nonce {
+		return measurements, hash, errors.New("signed measurements do not contain the requested nonce")
+	}
+	return measurements, hash, nil
+}
+
+// spdmPublicKey gets the key used to verify the signature of SPDM
+// measurements and whether it chains to one of the trusted roots. A bare
+// public key reported by the service is only used if the options allow it.
+func spdmPublicKey(certificate *Certificate, publicKey string, options *MeasurementVerifyOptions) (crypto.PublicKey, bool, error) {
+	switch {
+	case certificate != nil:
+		leaf, anchored, err := signingCertificate(certificate, options.Roots)
+		if err != nil {
+			return nil, false, err
+		}
+		return leaf.PublicKey, anchored, nil
+	case publicKey == "":
+		return nil, false, errors.New("no certificate or public key available to verify the signature")
+	case !options.TrustServicePublicKey:
+		return nil, false, errors.New("the service only reported a public key, which cannot be anchored to a trusted root")
+	}
+
+	key, err := parsePEMPublicKey(publicKey)
+	return key, false, err
+}
+
+// compareSPDMMeasurements compares the measurement blocks of a transcript
+// with the reference measurements and sets the verdict accordingly.
+func (verification *ComponentVerification) compareSPDMMeasurements(reference *ReferenceComponent, measurements *SPDMMeasurements) {
+	verification.Verdict = MatchMeasurementVerdict
+	for _, expected := range reference.Measurements {
+		comparison := MeasurementComparison{
+			Index:    expected.Index,
+			Expected: strings.ToLower(expected.Value),
+			Verdict:  MatchMeasurementVerdict,
+		}
+
+		block := measurements.Block(expected.Index)
+		switch {
+		case block == nil:
+			comparison.Verdict = MissingMeasurementVerdict
+		case hex.EncodeToString(block.Value) != comparison.Expected:
+			comparison.Actual = hex.EncodeToString(block.Value)
+			comparison.Verdict = MismatchMeasurementVerdict
+		default:
+			comparison.Actual = comparison.Expected
+		}
+		verification.Measurements = append(verification.Measurements, comparison)
+		verification.Verdict = worseMeasurementVerdict(verification.Verdict, comparison.Verdict)
+	}
+}
+
+// VerifySPDMMeasurements requests signed measurements from an SPDM Responder
+// with a fresh nonce, verifies the signature and certificate chain, and
+// compares the measurements with the reference manifest. The verdict is only
+// a match if the signing certificate chains to one of the trusted roots.
+//
+// An error is only returned if the measurements could not be retrieved.
+// Verification failures are reported in the verdict.
+func (componentintegrity *ComponentIntegrity) VerifySPDMMeasurements(manifest *ReferenceManifest, options *MeasurementVerifyOptions) (*ComponentVerification, error) {
+	if componentintegrity.spdmGetSignedMeasurementsTarget == "" {
+		return nil, errors.New("SPDMGetSignedMeasurements is not supported by this component")
+	}
+	if options == nil {
+		options = &MeasurementVerifyOptions{}
+	}
+
+	nonce, err := GenerateMeasurementNonce(spdmNonceSize)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := componentintegrity.SPDMGetSignedMeasurements(&SPDMGetSignedMeasurementsRequest{
+		MeasurementIndices: options.MeasurementIndices,
+		Nonce:              nonce,
+		SlotID:             options.SlotID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	verification := &ComponentVerification{Component: componentintegrity}
+
+	measurements, hash, err := parseSignedSPDMMeasurements(response, nonce)
+	verification.SPDM = measurements
+	if err != nil {
+		return verification.untrusted(err), nil
+	}
+
+	certificate, err := response.Certificate()
+	if err == nil && certificate == nil {
+		certificate, err = componentintegrity.SPDM.IdentityAuthentication.ResponderAuthentication.ComponentCertificate(componentintegrity.GetClient())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, anchored, err := spdmPublicKey(certificate, response.PublicKey, options)
+	if err != nil {
+		return verification.untrusted(err), nil
+	}
+	verification.ChainAnchored = anchored
+
+	message, prehashed := spdmSignedMessage(measurements, hash)
+	pss := strings.HasPrefix(response.SigningAlgorithm, "TPM_ALG_RSAPSS")
+	if err := verifySignature(publicKey, hash, message, prehashed, pss, measurements.Signature); err != nil {
+		return verification.untrusted(err), nil
+	}
+
+	reference := manifest.Component(componentintegrity.TargetComponentURI)
+	if reference == nil {
+		verification.Verdict = UnknownComponentMeasurementVerdict
+		return verification, nil
+	}
+
+	verification.compareSPDMMeasurements(reference, measurements)
+	return verification.anchor(), nil
+}
+
+// parseSignedTPMQuote decodes and parses a signed TPM quote and checks it
+// contains the requested nonce. The parsed quote is returned even if the
+// nonce does not match.
+func parseSignedTPMQuote(signedMeasurements, nonce string) (*TPMQuote, error) {
+	signed, err := base64.StdEncoding.DecodeString(signedMeasurements)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := ParseTPMQuote(signed)
+	if err != nil {
+		return nil, err
+	}
+
+	if hex.EncodeToString(quote.ExtraData) != nonce {
+		return quote, errors.New("quote does not contain the requested nonce")
+	}
+	return quote, nil
+}
+
+// verifyTPMQuoteSignature verifies the signature of a quote with the public
+// key of the attestation key certificate.
+func verifyTPMQuoteSignature(quote *TPMQuote, publicKey crypto.PublicKey) error {
+	hash, err := tpmHash(quote.SignatureHashAlgorithm)
+	if err != nil {
+		return err
+	}
+
+	signature := quote.Signature
+	if quote.SignatureAlgorithm == tpmAlgECDSA {
+		// Pad R and S to the same size for verifySignature
+		size := len(quote.R.Bytes())
+		if len(quote.S.Bytes()) > size {
+			size = len(quote.S.Bytes())
+		}
+		signature = append(quote.R.FillBytes(make([]byte, size)), quote.S.FillBytes(make([]byte, size))...)
+	}
+
+	return verifySignature(publicKey, hash, quote.Attested, false, quote.SignatureAlgorithm == tpmAlgRSAPSS, signature)
+}
+
+// compareTPMDigest compares the PCR digest of a quote with the reference
+// digest and sets the verdict accordingly.
+func (verification *ComponentVerification) compareTPMDigest(reference *ReferenceComponent, quote *TPMQuote) {
+	comparison := MeasurementComparison{
+		Index:    -1,
+		Expected: strings.ToLower(reference.PCRDigest),
+		Actual:   hex.EncodeToString(quote.PCRDigest),
+		Verdict:  MatchMeasurementVerdict,
+	}
+	if comparison.Actual != comparison.Expected {
+		comparison.Verdict = MismatchMeasurementVerdict
+	}
+	verification.Measurements = append(verification.Measurements, comparison)
+	verification.Verdict = comparison.Verdict
+}
+
+// VerifyTPMMeasurements requests a quote from a TPM with a fresh nonce,
+// verifies the signature against the attestation key certificate, and
+// compares the quoted PCR digest with the reference manifest. The verdict is
+// only a match if the certificate chains to one of the trusted roots.
+//
+// An error is only returned if the quote could not be retrieved. Verification
+// failures are reported in the verdict.
+func (componentintegrity *ComponentIntegrity) VerifyTPMMeasurements(manifest *ReferenceManifest, options *MeasurementVerifyOptions) (*ComponentVerification, error) {
+	if componentintegrity.tpmGetSignedMeasurementsTarget == "" {
+		return nil, errors.New("TPMGetSignedMeasurements is not supported by this component")
+	}
+	if options == nil || options.PCRSelection == "" || options.Scheme == "" {
+		return nil, errors.New("a PCR selection and signing scheme are required to request a TPM quote")
+	}
+
+	nonceSize := spdmNonceSize
+	if maximum := componentintegrity.TPM.NonceSizeBytesMaximum; maximum > 0 && maximum < nonceSize {
+		nonceSize = maximum
+	}
+	nonce, err := GenerateMeasurementNonce(nonceSize)
+	if err != nil {
+		return nil, err
+	}
+
+	certificateURI := componentintegrity.TPM.IdentityAuthentication.componentCertificate
+	response, err := componentintegrity.TPMGetSignedMeasurements(&TPMGetSignedMeasurementsRequest{
+		CertificateODataID: certificateURI,
+		Nonce:              nonce,
+		PCRSelection:       options.PCRSelection,
+		Scheme:             options.Scheme,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	certificate, err := componentintegrity.TPM.IdentityAuthentication.ComponentCertificate(componentintegrity.GetClient())
+	if err != nil {
+		return nil, err
+	}
+
+	verification := &ComponentVerification{Component: componentintegrity}
+
+	quote, err := parseSignedTPMQuote(response.SignedMeasurements, nonce)
+	verification.TPM = quote
+	if err != nil {
+		return verification.untrusted(err), nil
+	}
+
+	leaf, anchored, err := signingCertificate(certificate, options.Roots)
+	if err != nil {
+		return verification.untrusted(err), nil
+	}
+	verification.ChainAnchored = anchored
+
+	if err := verifyTPMQuoteSignature(quote, leaf.PublicKey); err != nil {
+		return verification.untrusted(err), nil
+	}
+
+	reference := manifest.Component(componentintegrity.TargetComponentURI)
+	if reference == nil || reference.PCRDigest == "" {
+		verification.Verdict = UnknownComponentMeasurementVerdict
+		return verification, nil
+	}
+
+	verification.compareTPMDigest(reference, quote)
+	return verification.anchor(), nil
+}
+
+// VerifyMeasurements verifies the signed measurements of a component using the
+// security protocol it reports.
+func (componentintegrity *ComponentIntegrity) VerifyMeasurements(manifest *ReferenceManifest, options *MeasurementVerifyOptions) (*ComponentVerification, error) {
+	switch componentintegrity.ComponentIntegrityType {
+	case SPDMComponentIntegrityType:
+		return componentintegrity.VerifySPDMMeasurements(manifest, options)
+	case TPMComponentIntegrityType:
+		return componentintegrity.VerifyTPMMeasurements(manifest, options)
+	}
+	return nil, fmt.Errorf("measurement verification is not supported for %s components", componentintegrity.ComponentIntegrityType)
+}
+
+// worseMeasurementVerdict returns the more severe of two verdicts.
+func worseMeasurementVerdict(a, b MeasurementVerdict) MeasurementVerdict {
+	rank := func(verdict MeasurementVerdict) int {
+		switch verdict {
+		case UnanchoredMeasurementVerdict:
+			return 1
+		case MissingMeasurementVerdict:
+			return 2
+		case MismatchMeasurementVerdict:
+			return 3
+		case UntrustedMeasurementVerdict:
+			return 4
+		}
+		return 0
+	}
+
+	if rank(b) > rank(a) {
+		return b
+	}
+	return a
+}
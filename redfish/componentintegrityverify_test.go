@@ -0,0 +1,406 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+var spdmComponentBody = `{
+		"@odata.id": "/redfish/v1/ComponentIntegrity/GPU0",
+		"Id": "GPU0",
+		"ComponentIntegrityType": "SPDM",
+		"ComponentIntegrityEnabled": true,
+		"TargetComponentURI": "/redfish/v1/Chassis/1/Processors/GPU0",
+		"Actions": {
+			"#ComponentIntegrity.SPDMGetSignedMeasurements": {
+				"target": "/redfish/v1/ComponentIntegrity/GPU0/Actions/ComponentIntegrity.SPDMGetSignedMeasurements"
+			}
+		}
+	}`
+
+var tpmComponentBody = `{
+		"@odata.id": "/redfish/v1/ComponentIntegrity/TPM",
+		"Id": "TPM",
+		"ComponentIntegrityType": "TPM",
+		"ComponentIntegrityEnabled": true,
+		"TargetComponentURI": "/redfish/v1/Systems/1#/TrustedModules/0",
+		"TPM": {
+			"IdentityAuthentication": {
+				"ComponentCertificate": {"@odata.id": "/redfish/v1/Systems/1/Certificates/AK"}
+			},
+			"NonceSizeBytesMaximum": 20
+		},
+		"Actions": {
+			"#ComponentIntegrity.TPMGetSignedMeasurements": {
+				"target": "/redfish/v1/ComponentIntegrity/TPM/Actions/ComponentIntegrity.TPMGetSignedMeasurements"
+			}
+		}
+	}`
+
+var measurementFirmware = bytes.Repeat([]byte{0xAB}, 32)
+
+// measurementResponder responds to signed measurement requests by signing the
+// requested nonce, like a component would.
+type measurementResponder struct {
+	*common.TestClient
+	respond func(payload interface{}) string
+}
+
+func (responder *measurementResponder) PostWithHeaders(url string, payload interface{}, customHeaders map[string]string) (*http.Response, error) {
+	_, _ = responder.TestClient.PostWithHeaders(url, payload, customHeaders)
+	return getCall(responder.respond(payload)), nil
+}
+
+// measurementSigner creates a device key and a certificate chain for it.
+func measurementSigner(t *testing.T) (key *ecdsa.PrivateKey, ca *LocalCA, chain string) {
+	ca, err := NewLocalCA("Device Root CA")
+	if err != nil {
+		t.Fatalf("Error creating CA: %s", err)
+	}
+
+	key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "Device"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}, ca.Certificate, key.Public(), ca.key)
+	if err != nil {
+		t.Fatalf("Error creating certificate: %s", err)
+	}
+
+	// SPDM certificate chains are ordered from the root to the leaf
+	chain = ca.CertificatePEM() + string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	return key, ca, chain
+}
+
+func signRaw(t *testing.T, key *ecdsa.PrivateKey, digest []byte) []byte {
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+	if err != nil {
+		t.Fatalf("Error signing: %s", err)
+	}
+	return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+}
+
+// spdmTranscript builds an SPDM 1.2 transcript signed by the key.
+func spdmTranscript(t *testing.T, key *ecdsa.PrivateKey, nonce []byte) string {
+	var transcript bytes.Buffer
+	transcript.Write([]byte{0x10, spdmGetVersion, 0, 0})
+	transcript.Write([]byte{0x10, spdmVersion, 0, 0, 0, 1, 0x00, 0x12})
+	transcript.Write(append([]byte{0x12, spdmGetCapabilities, 0, 0}, make([]byte, 16)...))
+	transcript.Write(append([]byte{0x12, spdmCapabilities, 0, 0}, make([]byte, 16)...))
+	transcript.Write(append([]byte{0x12, spdmNegotiateAlgorithms, 0, 0, 32, 0}, make([]byte, 26)...))
+	transcript.Write(append([]byte{0x12, spdmAlgorithms, 0, 0, 36, 0}, make([]byte, 30)...))
+
+	transcript.Write([]byte{0x12, spdmGetMeasurements, 0x01, 0xFF})
+	transcript.Write(nonce)
+	transcript.WriteByte(0)
+
+	var record bytes.Buffer
+	for index, value := range [][]byte{measurementFirmware, {0x01, 0x02}} {
+		record.Write([]byte{byte(index + 1), 0x01})
+		_ = binary.Write(&record, binary.LittleEndian, uint16(3+len(value)))
+		record.WriteByte(0x01)
+		_ = binary.Write(&record, binary.LittleEndian, uint16(len(value)))
+		record.Write(value)
+	}
+
+	transcript.Write([]byte{0x12, spdmMeasurements, 0, 0, 2, byte(record.Len()), 0, 0})
+	transcript.Write(record.Bytes())
+	transcript.Write(bytes.Repeat([]byte{0x55}, spdmNonceSize))
+	transcript.Write([]byte{0, 0})
+
+	measurements := &SPDMMeasurements{Version: 0x12, Signed: transcript.Bytes()}
+	message, _ := spdmSignedMessage(measurements, crypto.SHA256)
+	digest := sha256.Sum256(message)
+	transcript.Write(signRaw(t, key, digest[:]))
+
+	return base64.StdEncoding.EncodeToString(transcript.Bytes())
+}
+
+// TestVerifySPDMMeasurements tests verifying signed SPDM measurements against a manifest.
+func TestVerifySPDMMeasurements(t *testing.T) {
+	key, ca, chain := measurementSigner(t)
+
+	var component ComponentIntegrity
+	if err := json.NewDecoder(strings.NewReader(spdmComponentBody)).Decode(&component); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	certificate, _ := json.Marshal(map[string]string{
+		"@odata.id":         "/redfish/v1/Chassis/1/Certificates/0",
+		"CertificateString": chain,
+		"CertificateType":   "PEMChain",
+	})
+
+	replay := false
+	responder := &measurementResponder{
+		TestClient: &common.TestClient{
+			CustomReturnForActions: map[string][]interface{}{
+				http.MethodGet: {getCall(string(certificate)), getCall(string(certificate)), getCall(string(certificate)), getCall(string(certificate))},
+			},
+		},
+		respond: func(payload interface{}) string {
+			nonce, _ := hex.DecodeString(payload.(*SPDMGetSignedMeasurementsRequest).Nonce)
+			if replay {
+				nonce = make([]byte, spdmNonceSize)
+			}
+			response, _ := json.Marshal(map[string]interface{}{
+				"Certificate":        map[string]string{"@odata.id": "/redfish/v1/Chassis/1/Certificates/0"},
+				"HashingAlgorithm":   "TPM_ALG_SHA_256",
+				"SigningAlgorithm":   "TPM_ALG_ECDSA_ECC_NIST_P256",
+				"Version":            "1.2",
+				"SignedMeasurements": spdmTranscript(t, key, nonce),
+			})
+			return string(response)
+		},
+	}
+	component.SetClient(responder)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Certificate)
+
+	manifest, err := LoadReferenceManifest(strings.NewReader(`{
+		"Components": [{
+			"TargetComponentURI": "/redfish/v1/Chassis/1/Processors/GPU0",
+			"Measurements": [
+				{"Index": 1, "Value": "` + hex.EncodeToString(measurementFirmware) + `"},
+				{"Index": 2, "Value": "0102"}
+			]
+		}]
+	}`))
+	if err != nil {
+		t.Fatalf("Error loading manifest: %s", err)
+	}
+
+	result, err := component.VerifyMeasurements(manifest, &MeasurementVerifyOptions{Roots: roots})
+	if err != nil {
+		t.Fatalf("Error verifying measurements: %s", err)
+	}
+
+	if result.Verdict != MatchMeasurementVerdict || !result.ChainAnchored {
+		t.Errorf("Unexpected verdict %s (anchored %t): %v", result.Verdict, result.ChainAnchored, result.Error)
+	}
+
+	if block := result.SPDM.Block(1); block == nil || block.MeasurementType() != MutableFirmwareDMTFmeasurementTypes {
+		t.Errorf("Unexpected measurement block: %v", block)
+	}
+
+	result, err = component.VerifyMeasurements(manifest, nil)
+	if err != nil {
+		t.Fatalf("Error verifying measurements: %s", err)
+	}
+
+	if result.Verdict != UnanchoredMeasurementVerdict || result.ChainAnchored {
+		t.Errorf("Expected matching measurements without roots to be unanchored, got %s (anchored %t)", result.Verdict, result.ChainAnchored)
+	}
+
+	manifest.Components[0].Measurements[1].Value = "0103"
+	manifest.Components[0].Measurements = append(manifest.Components[0].Measurements, ReferenceMeasurement{Index: 3, Value: "00"})
+	result, err = component.VerifyMeasurements(manifest, nil)
+	if err != nil {
+		t.Fatalf("Error verifying measurements: %s", err)
+	}
+
+	if result.Verdict != MismatchMeasurementVerdict || result.ChainAnchored {
+		t.Errorf("Unexpected verdict %s (anchored %t): %v", result.Verdict, result.ChainAnchored, result.Error)
+	}
+
+	if result.Measurements[1].Verdict != MismatchMeasurementVerdict || result.Measurements[2].Verdict != MissingMeasurementVerdict {
+		t.Errorf("Unexpected measurement verdicts: %v", result.Measurements)
+	}
+
+	replay = true
+	result, err = component.VerifyMeasurements(manifest, nil)
+	if err != nil {
+		t.Fatalf("Error verifying measurements: %s", err)
+	}
+
+	if result.Verdict != UntrustedMeasurementVerdict {
+		t.Errorf("Expected replayed measurements to be untrusted, got %s", result.Verdict)
+	}
+}
+
+// TestVerifySPDMMeasurementsPublicKey tests that measurements signed by a
+// public key reported by the service are never reported as a match.
+func TestVerifySPDMMeasurementsPublicKey(t *testing.T) {
+	key, ca, _ := measurementSigner(t)
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatalf("Error marshaling public key: %s", err)
+	}
+	publicKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	var component ComponentIntegrity
+	if err := json.NewDecoder(strings.NewReader(spdmComponentBody)).Decode(&component); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	component.SetClient(&measurementResponder{
+		TestClient: &common.TestClient{},
+		respond: func(payload interface{}) string {
+			nonce, _ := hex.DecodeString(payload.(*SPDMGetSignedMeasurementsRequest).Nonce)
+			response, _ := json.Marshal(map[string]interface{}{
+				"PublicKey":          string(publicKey),
+				"HashingAlgorithm":   "TPM_ALG_SHA_256",
+				"SigningAlgorithm":   "TPM_ALG_ECDSA_ECC_NIST_P256",
+				"Version":            "1.2",
+				"SignedMeasurements": spdmTranscript(t, key, nonce),
+			})
+			return string(response)
+		},
+	})
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Certificate)
+	manifest := &ReferenceManifest{Components: []ReferenceComponent{{
+		TargetComponentURI: "/redfish/v1/Chassis/1/Processors/GPU0",
+		Measurements:       []ReferenceMeasurement{{Index: 1, Value: hex.EncodeToString(measurementFirmware)}},
+	}}}
+
+	result, err := component.VerifyMeasurements(manifest, &MeasurementVerifyOptions{Roots: roots})
+	if err != nil {
+		t.Fatalf("Error verifying measurements: %s", err)
+	}
+
+	if result.Verdict != UntrustedMeasurementVerdict || result.Error == nil {
+		t.Errorf("Expected a service public key to be rejected, got %s: %v", result.Verdict, result.Error)
+	}
+
+	result, err = component.VerifyMeasurements(manifest, &MeasurementVerifyOptions{Roots: roots, TrustServicePublicKey: true})
+	if err != nil {
+		t.Fatalf("Error verifying measurements: %s", err)
+	}
+
+	if result.Verdict != UnanchoredMeasurementVerdict || result.ChainAnchored {
+		t.Errorf("Expected a trusted service public key to be unanchored, got %s (anchored %t): %v",
+			result.Verdict, result.ChainAnchored, result.Error)
+	}
+}
+
+// tpmQuote builds a TPM2_Quote over PCR 0 signed by the key.
+func tpmQuote(t *testing.T, key *ecdsa.PrivateKey, nonce, pcrDigest []byte) string {
+	sized := func(b []byte) []byte {
+		return append(binary.BigEndian.AppendUint16(nil, uint16(len(b))), b...)
+	}
+
+	var attest []byte
+	attest = binary.BigEndian.AppendUint32(attest, tpmGeneratedValue)
+	attest = binary.BigEndian.AppendUint16(attest, tpmSTAttestQuote)
+	attest = append(attest, sized([]byte("signer"))...)
+	attest = append(attest, sized(nonce)...)
+	attest = append(attest, make([]byte, 17)...)
+	attest = binary.BigEndian.AppendUint64(attest, 7)
+	attest = binary.BigEndian.AppendUint32(attest, 1)
+	attest = binary.BigEndian.AppendUint16(attest, tpmAlgSHA256)
+	attest = append(attest, 3, 0x01, 0x00, 0x00)
+	attest = append(attest, sized(pcrDigest)...)
+
+	digest := sha256.Sum256(attest)
+	signature := signRaw(t, key, digest[:])
+
+	quote := sized(attest)
+	quote = binary.BigEndian.AppendUint16(quote, tpmAlgECDSA)
+	quote = binary.BigEndian.AppendUint16(quote, tpmAlgSHA256)
+	quote = append(quote, sized(signature[:32])...)
+	quote = append(quote, sized(signature[32:])...)
+
+	return base64.StdEncoding.EncodeToString(quote)
+}
+
+// TestVerifyTPMMeasurements tests verifying a TPM quote against a manifest.
+func TestVerifyTPMMeasurements(t *testing.T) {
+	key, ca, chain := measurementSigner(t)
+	pcrDigest := bytes.Repeat([]byte{0xCD}, 32)
+
+	var component ComponentIntegrity
+	if err := json.NewDecoder(strings.NewReader(tpmComponentBody)).Decode(&component); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	certificate, _ := json.Marshal(map[string]string{
+		"@odata.id":         "/redfish/v1/Systems/1/Certificates/AK",
+		"CertificateString": chain,
+		"CertificateType":   "PEMChain",
+	})
+
+	var nonceSize int
+	responder := &measurementResponder{
+		TestClient: &common.TestClient{
+			CustomReturnForActions: map[string][]interface{}{
+				http.MethodGet: {getCall(string(certificate)), getCall(string(certificate))},
+			},
+		},
+		respond: func(payload interface{}) string {
+			nonce, _ := hex.DecodeString(payload.(*TPMGetSignedMeasurementsRequest).Nonce)
+			nonceSize = len(nonce)
+			response, _ := json.Marshal(map[string]string{
+				"SignedMeasurements": tpmQuote(t, key, nonce, pcrDigest),
+			})
+			return string(response)
+		},
+	}
+	component.SetClient(responder)
+
+	manifest := &ReferenceManifest{Components: []ReferenceComponent{{
+		TargetComponentURI: "/redfish/v1/Systems/1#/TrustedModules/0",
+		PCRDigest:          hex.EncodeToString(pcrDigest),
+	}}}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Certificate)
+	options := &MeasurementVerifyOptions{Roots: roots, PCRSelection: "AAAAAQALAwEAAA==", Scheme: "ABgACw=="}
+
+	result, err := component.VerifyMeasurements(manifest, options)
+	if err != nil {
+		t.Fatalf("Error verifying measurements: %s", err)
+	}
+
+	if result.Verdict != MatchMeasurementVerdict || !result.ChainAnchored {
+		t.Errorf("Unexpected verdict %s (anchored %t): %v", result.Verdict, result.ChainAnchored, result.Error)
+	}
+
+	if nonceSize != 20 {
+		t.Errorf("Expected nonce to be limited to 20 bytes, got %d", nonceSize)
+	}
+
+	if len(result.TPM.PCRSelections) != 1 || result.TPM.PCRSelections[0].PCRs[0] != 0 {
+		t.Errorf("Unexpected PCR selection: %v", result.TPM.PCRSelections)
+	}
+
+	options.Roots = nil
+	result, err = component.VerifyMeasurements(manifest, options)
+	if err != nil {
+		t.Fatalf("Error verifying measurements: %s", err)
+	}
+
+	if result.Verdict != UnanchoredMeasurementVerdict {
+		t.Errorf("Expected a quote without roots to be unanchored, got %s: %v", result.Verdict, result.Error)
+	}
+}
@@ -0,0 +1,514 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+// composeSystemStanzaID is the identifier of the stanza that composes a system
+// in the manifests created by this package.
+const composeSystemStanzaID = "ComposeSystem"
+
+// CompositionRequirements describes the resources a composed system needs.
+type CompositionRequirements struct {
+	// Name is the name of the composed system.
+	Name string
+	// Description is the description of the manifest.
+	Description string
+	// Cores is the minimum number of processor cores.
+	Cores int
+	// MemoryGiB is the minimum amount of memory in GiB.
+	MemoryGiB int
+	// StorageBytes is the minimum capacity of the drives in bytes.
+	StorageBytes int64
+	// NetworkInterfaces is the minimum number of network and Ethernet
+	// interfaces.
+	NetworkInterfaces int
+}
+
+// ResourceBlockCapacity is what a resource block contributes to a composed
+// system. Only the resources that the type of the block provides are counted,
+// for example the drives of a compute block are not counted as storage.
+type ResourceBlockCapacity struct {
+	// ResourceBlock is the resource block, or nil for a total.
+	ResourceBlock *ResourceBlock
+	// Cores is the number of processor cores.
+	Cores int
+	// MemoryMiB is the amount of memory in MiB.
+	MemoryMiB int
+	// StorageBytes is the capacity of the drives in bytes.
+	StorageBytes int64
+	// NetworkInterfaces is the number of network and Ethernet interfaces.
+	NetworkInterfaces int
+}
+
+func (capacity *ResourceBlockCapacity) add(other *ResourceBlockCapacity) {
+	capacity.Cores += other.Cores
+	capacity.MemoryMiB += other.MemoryMiB
+	capacity.StorageBytes += other.StorageBytes
+	capacity.NetworkInterfaces += other.NetworkInterfaces
+}
+
+// shortfall returns the resources still needed to meet the requirements, or
+// an empty string if they are met.
+func (capacity *ResourceBlockCapacity) shortfall(requirements *CompositionRequirements) string {
+	var missing []string
+	if capacity.Cores < requirements.Cores {
+		missing = append(missing, fmt.Sprintf("%d cores", requirements.Cores-capacity.Cores))
+	}
+	if memory := requirements.MemoryGiB * 1024; capacity.MemoryMiB < memory {
+		missing = append(missing, fmt.Sprintf("%d MiB memory", memory-capacity.MemoryMiB))
+	}
+	if capacity.StorageBytes < requirements.StorageBytes {
+		missing = append(missing, fmt.Sprintf("%d bytes storage", requirements.StorageBytes-capacity.StorageBytes))
+	}
+	if capacity.NetworkInterfaces < requirements.NetworkInterfaces {
+		missing = append(missing, fmt.Sprintf("%d network interfaces", requirements.NetworkInterfaces-capacity.NetworkInterfaces))
+	}
+	return strings.Join(missing, ", ")
+}
+
+// providesType reports whether a resource block of the given types provides
+// resources of a type. Blocks without a type and expansion blocks provide
+// anything they contain.
+func providesType(types []ResourceBlockType, provided ...ResourceBlockType) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, blockType := range types {
+		if blockType == ExpansionResourceBlockType || blockType == ComputerSystemResourceBlockType {
+			return true
+		}
+		for _, p := range provided {
+			if blockType == p {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Capacity gets what this resource block contributes to a composed system.
+// The processors, memory and drives of the block are read from the service.
+func (resourceblock *ResourceBlock) Capacity() (*ResourceBlockCapacity, error) {
+	types := resourceblock.ResourceBlockType
+	capacity := &ResourceBlockCapacity{ResourceBlock: resourceblock}
+
+	if providesType(types, ComputeResourceBlockType, ProcessorResourceBlockType) {
+		processors, err := resourceblock.Processors()
+		if err != nil {
+			return nil, err
+		}
+		for _, processor := range processors {
+			capacity.Cores += processor.TotalCores
+		}
+	}
+
+	if providesType(types, ComputeResourceBlockType, MemoryResourceBlockType) {
+		memory, err := resourceblock.Memory()
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range memory {
+			capacity.MemoryMiB += m.CapacityMiB
+		}
+	}
+
+	if providesType(types, StorageResourceBlockType) {
+		drives, err := resourceblock.Drives()
+		if err != nil {
+			return nil, err
+		}
+		storage, err := resourceblock.Storage()
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range storage {
+			storageDrives, err := s.Drives()
+			if err != nil {
+				return nil, err
+			}
+			drives = append(drives, storageDrives...)
+		}
+		// Drives may be linked both directly and through a storage subsystem.
+		counted := make(map[string]bool)
+		for _, drive := range drives {
+			if drive.ODataID != "" && counted[drive.ODataID] {
+				continue
+			}
+			counted[drive.ODataID] = true
+			capacity.StorageBytes += drive.CapacityBytes
+		}
+	}
+
+	if providesType(types, NetworkResourceBlockType) {
+		capacity.NetworkInterfaces = len(resourceblock.ethernetInterfaces) + len(resourceblock.networkInterfaces)
+	}
+
+	// A computer system block may only describe its resources through the
+	// systems it contains
+	if capacity.Cores == 0 && capacity.MemoryMiB == 0 && len(resourceblock.computerSystems) > 0 {
+		systems, err := resourceblock.ComputerSystems()
+		if err != nil {
+			return nil, err
+		}
+		for _, system := range systems {
+			processors, err := system.Processors()
+			if err != nil {
+				return nil, err
+			}
+			for _, processor := range processors {
+				capacity.Cores += processor.TotalCores
+			}
+			capacity.MemoryMiB += int(system.MemorySummary.TotalSystemMemoryGiB * 1024)
+		}
+	}
+
+	return capacity, nil
+}
+
+// Available reports whether the resource block can be used in a new
+// composition: it is unused, or shared and able to take more compositions,
+// is not reserved and is not in a failed state.
+func (resourceblock *ResourceBlock) Available() bool {
+	status := resourceblock.CompositionStatus
+	if status.Reserved {
+		return false
+	}
+
+	switch status.CompositionState {
+	case UnusedCompositionState, "":
+	case ComposedAndAvailableCompositionState:
+		if !status.SharingEnabled ||
+			(status.MaxCompositions > 0 && status.NumberOfCompositions >= status.MaxCompositions) {
+			return false
+		}
+	default:
+		return false
+	}
+
+	switch resourceblock.Status.State {
+	case common.AbsentState, common.DisabledState, common.UnavailableOfflineState:
+		return false
+	}
+	return resourceblock.Status.Health != common.CriticalHealth
+}
+
+// InsufficientResourcesError is returned if the available resource blocks
+// cannot meet the requirements of a composition.
+type InsufficientResourcesError struct {
+	// Requirements are the requirements that could not be met.
+	Requirements CompositionRequirements
+	// Available is the total capacity of the available resource blocks.
+	Available ResourceBlockCapacity
+}
+
+func (e *InsufficientResourcesError) Error() string {
+	return fmt.Sprintf("available resource blocks are short of %s", e.Available.shortfall(&e.Requirements))
+}
+
+// CompositionSelection is a set of resource blocks selected to compose a
+// system.
+type CompositionSelection struct {
+	// Requirements are the requirements the selection meets.
+	Requirements CompositionRequirements
+	// ResourceBlocks are the selected resource blocks.
+	ResourceBlocks []*ResourceBlock
+	// Capacity is the total capacity of the selected resource blocks.
+	Capacity ResourceBlockCapacity
+}
+
+// selectResourceBlocks selects resource blocks until the requirements are met.
+// The candidates are considered in order of their ID so the selection is
+// stable, and candidates that add nothing to a requirement that is not yet
+// met are skipped.
+func selectResourceBlocks(candidates []*ResourceBlockCapacity, requirements *CompositionRequirements) (*CompositionSelection, error) {
+	sorted := append([]*ResourceBlockCapacity{}, candidates...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ResourceBlock.ID < sorted[j].ResourceBlock.ID
+	})
+
+	selection := &CompositionSelection{Requirements: *requirements}
+	var available ResourceBlockCapacity
+	for _, candidate := range sorted {
+		available.add(candidate)
+	}
+
+	total := &selection.Capacity
+	for _, candidate := range sorted {
+		if total.shortfall(requirements) == "" && len(selection.ResourceBlocks) > 0 {
+			break
+		}
+
+		contributes := (total.Cores < requirements.Cores && candidate.Cores > 0) ||
+			(total.MemoryMiB < requirements.MemoryGiB*1024 && candidate.MemoryMiB > 0) ||
+			(total.StorageBytes < requirements.StorageBytes && candidate.StorageBytes > 0) ||
+			(total.NetworkInterfaces < requirements.NetworkInterfaces && candidate.NetworkInterfaces > 0) ||
+			// A system cannot be composed without processors
+			(len(selection.ResourceBlocks) == 0 && candidate.Cores > 0)
+		if !contributes {
+			continue
+		}
+
+		selection.ResourceBlocks = append(selection.ResourceBlocks, candidate.ResourceBlock)
+		total.add(candidate)
+	}
+
+	if total.shortfall(requirements) != "" || len(selection.ResourceBlocks) == 0 {
+		if available.shortfall(requirements) == "" {
+			// Only possible if no block provides processors
+			return nil, errors.New("no available resource block provides processors")
+		}
+		return nil, &InsufficientResourcesError{Requirements: *requirements, Available: available}
+	}
+
+	return selection, nil
+}
+
+// SelectResourceBlocks selects resource blocks of the free pool that meet the
+// requirements. If the service has no free pool, the available blocks of the
+// resource block collection are used.
+func (compositionservice *CompositionService) SelectResourceBlocks(requirements *CompositionRequirements) (*CompositionSelection, error) {
+	var blocks []*ResourceBlock
+	var err error
+	if compositionservice.freePool != "" {
+		blocks, err = compositionservice.FreePool()
+	} else {
+		blocks, err = ListReferencedResourceBlocks(compositionservice.GetClient(), compositionservice.resourceBlocks)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []*ResourceBlockCapacity
+	for _, block := range blocks {
+		if !block.Available() || (block.Pool != "" && block.Pool != FreePoolType) {
+			continue
+		}
+		capacity, err := block.Capacity()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get capacity of resource block %s: %w", block.ID, err)
+		}
+		candidates = append(candidates, capacity)
+	}
+
+	return selectResourceBlocks(candidates, requirements)
+}
+
+// Manifest creates a manifest that composes a system from the selected
+// resource blocks.
+func (selection *CompositionSelection) Manifest() (*Manifest, error) {
+	var system struct {
+		Name  string `json:",omitempty"`
+		Links struct {
+			ResourceBlocks []common.IDRef
+		}
+	}
+	system.Name = selection.Requirements.Name
+	for _, block := range selection.ResourceBlocks {
+		system.Links.ResourceBlocks = append(system.Links.ResourceBlocks, common.IDRef{ODataID: block.ODataID})
+	}
+
+	request, err := json.Marshal(&system)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Manifest{
+		Description: selection.Requirements.Description,
+		Expand:      NoneExpand,
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		Stanzas: []Stanza{{
+			StanzaID:   composeSystemStanzaID,
+			StanzaType: ComposeSystemStanzaType,
+			Request:    request,
+		}},
+	}, nil
+}
+
+// composedSystemResponse is the part of the response of a compose system
+// stanza that this package uses.
+type composedSystemResponse struct {
+	ODataID string `json:"@odata.id"`
+	Links   struct {
+		ResourceBlocks common.Links
+	}
+}
+
+// systemResponse finds the response of the stanza that composes a system.
+func systemResponse(manifest *Manifest) (*composedSystemResponse, error) {
+	for i := range manifest.Stanzas {
+		stanza := &manifest.Stanzas[i]
+		if stanza.StanzaType != ComposeSystemStanzaType || len(stanza.Response) == 0 {
+			continue
+		}
+		var response composedSystemResponse
+		if err := json.Unmarshal(stanza.Response, &response); err != nil {
+			return nil, err
+		}
+		return &response, nil
+	}
+	return nil, errors.New("compose response does not contain a composed system")
+}
+
+// CompositionPreview is the result of previewing a composition.
+type CompositionPreview struct {
+	// ReservationID is the ID of the composition reservation if the resource
+	// blocks were reserved.
+	ReservationID string
+	// ResourceBlocks are the URIs of the resource blocks the service would
+	// compose the system from.
+	ResourceBlocks []string
+	// Manifest is the manifest with the responses of the service.
+	Manifest Manifest
+}
+
+func (compositionservice *CompositionService) preview(selection *CompositionSelection, requestType ComposeRequestType) (*CompositionPreview, error) {
+	manifest, err := selection.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := compositionservice.Compose(&ComposeRequest{
+		Manifest:      *manifest,
+		RequestFormat: ManifestComposeRequestFormat,
+		RequestType:   requestType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	system, err := systemResponse(&response.Manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CompositionPreview{
+		ReservationID:  response.ReservationID,
+		ResourceBlocks: system.Links.ResourceBlocks.ToStrings(),
+		Manifest:       response.Manifest,
+	}, nil
+}
+
+// PreviewComposition asks the service how it would compose a system from the
+// selected resource blocks without composing it.
+func (compositionservice *CompositionService) PreviewComposition(selection *CompositionSelection) (*CompositionPreview, error) {
+	return compositionservice.preview(selection, PreviewComposeRequestType)
+}
+
+// ReserveComposition previews a composition and reserves the resource blocks
+// for it. The reservation is applied with ApplyReservation, or released with
+// ReleaseReservation.
+func (compositionservice *CompositionService) ReserveComposition(selection *CompositionSelection) (*CompositionPreview, error) {
+	preview, err := compositionservice.preview(selection, PreviewReserveComposeRequestType)
+	if err != nil {
+		return nil, err
+	}
+	if preview.ReservationID == "" {
+		return nil, errors.New("service did not return a reservation ID")
+	}
+	return preview, nil
+}
+
+// CompositionReservation gets a composition reservation by its ID.
+func (compositionservice *CompositionService) CompositionReservation(reservationID string) (*CompositionReservation, error) {
+	if compositionservice.compositionReservations == "" {
+		return nil, errors.New("composition service does not support reservations")
+	}
+	return GetCompositionReservation(compositionservice.GetClient(),
+		strings.TrimSuffix(compositionservice.compositionReservations, "/")+"/"+reservationID)
+}
+
+// ReleaseReservation deletes a composition reservation, which returns the
+// reserved resource blocks to the free pool.
+func (compositionservice *CompositionService) ReleaseReservation(reservation *CompositionReservation) error {
+	_, err := DeleteEntity(compositionservice.GetClient(), &reservation.Entity)
+	return err
+}
+
+// ComposedSystem is the result of composing a system.
+type ComposedSystem struct {
+	// System is the composed system.
+	System *ComputerSystem
+	// ReservationID is the ID of the reservation that was applied, if any.
+	ReservationID string
+	// ResourceBlocks are the URIs of the resource blocks the system was
+	// composed from.
+	ResourceBlocks []string
+	// Manifest is the manifest with the responses of the service.
+	Manifest Manifest
+}
+
+func (compositionservice *CompositionService) apply(request *ComposeRequest) (*ComposedSystem, error) {
+	response, err := compositionservice.Compose(request)
+	if err != nil {
+		return nil, err
+	}
+
+	system, err := systemResponse(&response.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	if system.ODataID == "" {
+		return nil, errors.New("compose response does not contain the URI of the composed system")
+	}
+
+	computerSystem, err := GetComputerSystem(compositionservice.GetClient(), system.ODataID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ComposedSystem{
+		System:         computerSystem,
+		ReservationID:  request.ReservationID,
+		ResourceBlocks: system.Links.ResourceBlocks.ToStrings(),
+		Manifest:       response.Manifest,
+	}, nil
+}
+
+// ComposeSystem composes a system from the selected resource blocks.
+func (compositionservice *CompositionService) ComposeSystem(selection *CompositionSelection) (*ComposedSystem, error) {
+	manifest, err := selection.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	return compositionservice.apply(&ComposeRequest{
+		Manifest:      *manifest,
+		RequestFormat: ManifestComposeRequestFormat,
+		RequestType:   ApplyComposeRequestType,
+	})
+}
+
+// ApplyReservation composes the system of a composition reservation.
+func (compositionservice *CompositionService) ApplyReservation(reservationID string) (*ComposedSystem, error) {
+	if reservationID == "" {
+		return nil, errors.New("reservation ID should not be empty")
+	}
+
+	return compositionservice.apply(&ComposeRequest{
+		RequestFormat: ManifestComposeRequestFormat,
+		RequestType:   ApplyComposeRequestType,
+		ReservationID: reservationID,
+	})
+}
+
+// DecomposeSystem decomposes a composed system by deleting it, which returns
+// its resource blocks to the free pool. A task is returned if the service
+// decomposes the system asynchronously.
+func (compositionservice *CompositionService) DecomposeSystem(system *ComputerSystem) (*Task, error) {
+	if system.SystemType != "" && system.SystemType != ComposedSystemType {
+		return nil, fmt.Errorf("system %s is not a composed system", system.ID)
+	}
+	return DeleteEntity(compositionservice.GetClient(), &system.Entity)
+}
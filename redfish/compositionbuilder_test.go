@@ -0,0 +1,254 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+var composeServiceBody = `{
+		"@odata.id": "/redfish/v1/CompositionService",
+		"Id": "CompositionService",
+		"ServiceEnabled": true,
+		"CompositionReservations": {"@odata.id": "/redfish/v1/CompositionService/CompositionReservations"},
+		"FreePool": {"@odata.id": "/redfish/v1/CompositionService/FreePool"},
+		"Actions": {
+			"#CompositionService.Compose": {"target": "/redfish/v1/CompositionService/Actions/CompositionService.Compose"}
+		}
+	}`
+
+var composeResponseBody = `{
+		"RequestFormat": "Manifest",
+		"RequestType": "%s",
+		"ReservationId": "%s",
+		"Manifest": {
+			"Expand": "None",
+			"Stanzas": [{
+				"StanzaType": "ComposeSystem",
+				"StanzaId": "ComposeSystem",
+				"Response": {
+					"@odata.id": "/redfish/v1/Systems/Composed-1",
+					"Links": {"ResourceBlocks": [
+						{"@odata.id": "/redfish/v1/CompositionService/ResourceBlocks/ComputeBlock1"},
+						{"@odata.id": "/redfish/v1/CompositionService/ResourceBlocks/DriveBlock3"}
+					]}
+				}
+			}]
+		}
+	}`
+
+func testResourceBlockCapacity(id string, cores, memoryMiB int, storageBytes int64) *ResourceBlockCapacity {
+	block := &ResourceBlock{}
+	block.ID = id
+	block.ODataID = "/redfish/v1/CompositionService/ResourceBlocks/" + id
+	return &ResourceBlockCapacity{ResourceBlock: block, Cores: cores, MemoryMiB: memoryMiB, StorageBytes: storageBytes}
+}
+
+// TestSelectResourceBlocks tests selecting resource blocks for requirements.
+func TestSelectResourceBlocks(t *testing.T) {
+	candidates := []*ResourceBlockCapacity{
+		testResourceBlockCapacity("DriveBlock3", 0, 0, 500*1024*1024*1024),
+		testResourceBlockCapacity("ComputeBlock2", 16, 64*1024, 0),
+		testResourceBlockCapacity("ComputeBlock1", 8, 32*1024, 0),
+		testResourceBlockCapacity("DriveBlock4", 0, 0, 500*1024*1024*1024),
+	}
+
+	selection, err := selectResourceBlocks(candidates, &CompositionRequirements{
+		Cores:        8,
+		MemoryGiB:    32,
+		StorageBytes: 400 * 1024 * 1024 * 1024,
+	})
+	if err != nil {
+		t.Fatalf("Error selecting resource blocks: %s", err)
+	}
+
+	var ids []string
+	for _, block := range selection.ResourceBlocks {
+		ids = append(ids, block.ID)
+	}
+	if strings.Join(ids, ",") != "ComputeBlock1,DriveBlock3" {
+		t.Errorf("Unexpected selection: %v", ids)
+	}
+	if selection.Capacity.Cores != 8 || selection.Capacity.StorageBytes != 500*1024*1024*1024 {
+		t.Errorf("Unexpected capacity: %+v", selection.Capacity)
+	}
+
+	_, err = selectResourceBlocks(candidates, &CompositionRequirements{Cores: 32})
+	var insufficient *InsufficientResourcesError
+	if !errors.As(err, &insufficient) {
+		t.Fatalf("Expected an InsufficientResourcesError, got: %v", err)
+	}
+	if insufficient.Available.Cores != 24 || !strings.Contains(err.Error(), "8 cores") {
+		t.Errorf("Unexpected error: %s", err)
+	}
+}
+
+// TestResourceBlockCapacity tests reading the capacity of a resource block.
+func TestResourceBlockCapacity(t *testing.T) {
+	var block ResourceBlock
+	err := json.NewDecoder(strings.NewReader(`{
+		"@odata.id": "/redfish/v1/CompositionService/ResourceBlocks/ComputeBlock1",
+		"Id": "ComputeBlock1",
+		"ResourceBlockType": ["Compute"],
+		"CompositionStatus": {"CompositionState": "Unused", "Reserved": false},
+		"Status": {"State": "Enabled", "Health": "OK"},
+		"Processors": [{"@odata.id": "/redfish/v1/CompositionService/ResourceBlocks/ComputeBlock1/Processors/CPU1"}],
+		"Memory": [{"@odata.id": "/redfish/v1/CompositionService/ResourceBlocks/ComputeBlock1/Memory/DIMM1"}],
+		"Drives": [{"@odata.id": "/redfish/v1/CompositionService/ResourceBlocks/ComputeBlock1/Drives/1"}],
+		"EthernetInterfaces": [{"@odata.id": "/redfish/v1/CompositionService/ResourceBlocks/ComputeBlock1/EthernetInterfaces/1"}]
+	}`)).Decode(&block)
+	if err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {
+				getCall(`{"Id": "CPU1", "TotalCores": 8}`),
+				getCall(`{"Id": "DIMM1", "CapacityMiB": 32768}`),
+			},
+		},
+	}
+	block.SetClient(testClient)
+
+	if !block.Available() {
+		t.Error("Expected resource block to be available")
+	}
+
+	capacity, err := block.Capacity()
+	if err != nil {
+		t.Fatalf("Error making Capacity call: %s", err)
+	}
+
+	// Drives and interfaces of a compute block are not counted
+	if capacity.Cores != 8 || capacity.MemoryMiB != 32768 || capacity.StorageBytes != 0 || capacity.NetworkInterfaces != 0 {
+		t.Errorf("Unexpected capacity: %+v", capacity)
+	}
+
+	block.CompositionStatus.Reserved = true
+	if block.Available() {
+		t.Error("Expected reserved resource block not to be available")
+	}
+}
+
+// TestResourceBlockCapacityStorage tests that drives linked both directly and
+// through a storage subsystem are counted once.
+func TestResourceBlockCapacityStorage(t *testing.T) {
+	var block ResourceBlock
+	err := json.NewDecoder(strings.NewReader(`{
+		"@odata.id": "/redfish/v1/CompositionService/ResourceBlocks/DriveBlock3",
+		"Id": "DriveBlock3",
+		"ResourceBlockType": ["Storage"],
+		"Drives": [{"@odata.id": "/redfish/v1/CompositionService/ResourceBlocks/DriveBlock3/Drives/1"}],
+		"Storage": [{"@odata.id": "/redfish/v1/CompositionService/ResourceBlocks/DriveBlock3/Storage/1"}]
+	}`)).Decode(&block)
+	if err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	drive := `{"@odata.id": "/redfish/v1/CompositionService/ResourceBlocks/DriveBlock3/Drives/1", "Id": "1", "CapacityBytes": 1000}`
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {
+				getCall(drive),
+				getCall(`{"@odata.id": "/redfish/v1/CompositionService/ResourceBlocks/DriveBlock3/Storage/1", "Id": "1",
+					"Drives": [{"@odata.id": "/redfish/v1/CompositionService/ResourceBlocks/DriveBlock3/Drives/1"}]}`),
+				getCall(drive),
+			},
+		},
+	}
+	block.SetClient(testClient)
+
+	capacity, err := block.Capacity()
+	if err != nil {
+		t.Fatalf("Error making Capacity call: %s", err)
+	}
+
+	if capacity.StorageBytes != 1000 {
+		t.Errorf("Expected the drive to be counted once, got %d bytes", capacity.StorageBytes)
+	}
+}
+
+// TestCompositionServiceReserveAndApply tests reserving and applying a composition.
+func TestCompositionServiceReserveAndApply(t *testing.T) {
+	var result CompositionService
+	err := json.NewDecoder(strings.NewReader(composeServiceBody)).Decode(&result)
+	if err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodPost: {
+				getCall(strings.Replace(strings.Replace(composeResponseBody, "%s", "PreviewReserve", 1), "%s", "Reservation-1", 1)),
+				getCall(strings.Replace(strings.Replace(composeResponseBody, "%s", "Apply", 1), "%s", "Reservation-1", 1)),
+			},
+			http.MethodGet: {
+				getCall(`{"@odata.id": "/redfish/v1/Systems/Composed-1", "Id": "Composed-1", "SystemType": "Composed"}`),
+			},
+		},
+	}
+	result.SetClient(testClient)
+
+	selection := &CompositionSelection{
+		Requirements: CompositionRequirements{Name: "web-1"},
+		ResourceBlocks: []*ResourceBlock{
+			testResourceBlockCapacity("ComputeBlock1", 8, 0, 0).ResourceBlock,
+			testResourceBlockCapacity("DriveBlock3", 0, 0, 1).ResourceBlock,
+		},
+	}
+
+	preview, err := result.ReserveComposition(selection)
+	if err != nil {
+		t.Fatalf("Error making ReserveComposition call: %s", err)
+	}
+	if preview.ReservationID != "Reservation-1" || len(preview.ResourceBlocks) != 2 {
+		t.Errorf("Unexpected preview: %+v", preview)
+	}
+
+	composed, err := result.ApplyReservation(preview.ReservationID)
+	if err != nil {
+		t.Fatalf("Error making ApplyReservation call: %s", err)
+	}
+	if composed.System.ID != "Composed-1" || composed.ReservationID != "Reservation-1" {
+		t.Errorf("Unexpected composed system: %+v", composed)
+	}
+
+	calls := testClient.CapturedCalls()
+	if len(calls) != 3 {
+		t.Fatalf("Expected three calls to be made, captured: %v", calls)
+	}
+	if !strings.Contains(calls[0].Payload, "RequestType:PreviewReserve") ||
+		!strings.Contains(calls[0].Payload, "StanzaType:ComposeSystem") {
+		t.Errorf("Unexpected reserve payload: %s", calls[0].Payload)
+	}
+
+	payload, err := json.Marshal(&ComposeRequest{
+		RequestFormat: ManifestComposeRequestFormat,
+		RequestType:   ApplyComposeRequestType,
+		ReservationID: "Reservation-1",
+	})
+	if err != nil {
+		t.Fatalf("Error marshaling request: %s", err)
+	}
+	if string(payload) != `{"RequestFormat":"Manifest","RequestType":"Apply","ReservationId":"Reservation-1"}` {
+		t.Errorf("Unexpected apply payload: %s", payload)
+	}
+
+	task, err := result.DecomposeSystem(composed.System)
+	if err != nil || task != nil {
+		t.Errorf("Error making DecomposeSystem call: %v %v", task, err)
+	}
+	calls = testClient.CapturedCalls()
+	if calls[3].Action != http.MethodDelete || calls[3].URL != "/redfish/v1/Systems/Composed-1" {
+		t.Errorf("Unexpected decompose call: %+v", calls[3])
+	}
+}
@@ -62,20 +62,8 @@ func (compositionreservation *CompositionReservation) UnmarshalJSON(b []byte) er
 
 // GetCompositionReservation will get a CompositionReservation instance from the service.
 func GetCompositionReservation(c common.Client, uri string) (*CompositionReservation, error) {
-	resp, err := c.Get(uri)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
 	var compositionreservation CompositionReservation
-	err = json.NewDecoder(resp.Body).Decode(&compositionreservation)
-	if err != nil {
-		return nil, err
-	}
-
-	compositionreservation.SetClient(c)
-	return &compositionreservation, nil
+	return &compositionreservation, compositionreservation.Get(c, uri, &compositionreservation)
 }
 
 // ListReferencedCompositionReservations gets the collection of CompositionReservation from
@@ -47,7 +47,26 @@ type ComposeRequest struct {
 	// ReservationID is the identifier of the composition reservation if applying a reservation. The value for this
 	// parameter is obtained from the response of a Compose action where the RequestType parameter contains the value
 	// `PreviewReserve`.
-	ReservationID string `json:"ReservationID,omitempty"`
+	ReservationID string `json:"ReservationId,omitempty"`
+}
+
+// MarshalJSON omits the manifest if it has no stanzas, since a request that
+// applies a reservation shall not contain one.
+func (request ComposeRequest) MarshalJSON() ([]byte, error) {
+	payload := struct {
+		Manifest      *Manifest `json:",omitempty"`
+		RequestFormat ComposeRequestFormat
+		RequestType   ComposeRequestType
+		ReservationID string `json:"ReservationId,omitempty"`
+	}{
+		RequestFormat: request.RequestFormat,
+		RequestType:   request.RequestType,
+		ReservationID: request.ReservationID,
+	}
+	if len(request.Manifest.Stanzas) > 0 {
+		payload.Manifest = &request.Manifest
+	}
+	return json.Marshal(payload)
 }
 
 // ComposeResponse shall contain the properties found in the response body for the Compose action.
@@ -247,6 +266,9 @@ func (compositionservice *CompositionService) ActivePool() ([]*ResourceBlock, er
 
 // CompositionReservations gets a resource collection whose members represent the reserved resource blocks and the
 // related document that caused the reservations.
+//
+// Deprecated: the members of the collection are composition reservations, not resource blocks. Use Reservations
+// instead.
 func (compositionservice *CompositionService) CompositionReservations() ([]*ResourceBlock, error) {
 	if compositionservice.compositionReservations == "" {
 		return nil, nil
@@ -255,6 +277,16 @@ func (compositionservice *CompositionService) CompositionReservations() ([]*Reso
 	return ListReferencedResourceBlocks(compositionservice.GetClient(), compositionservice.compositionReservations)
 }
 
+// Reservations gets the composition reservations, which represent the reserved resource blocks and the related
+// document that caused the reservations.
+func (compositionservice *CompositionService) Reservations() ([]*CompositionReservation, error) {
+	if compositionservice.compositionReservations == "" {
+		return nil, nil
+	}
+
+	return ListReferencedCompositionReservations(compositionservice.GetClient(), compositionservice.compositionReservations)
+}
+
 // FreePool gets a resource collection whose members represent the reserved resource blocks in the free pool.
 func (compositionservice *CompositionService) FreePool() ([]*ResourceBlock, error) {
 	if compositionservice.freePool == "" {
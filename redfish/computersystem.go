@@ -825,6 +825,10 @@ type ComputerSystem struct {
 	// networkInterfaces shall be a link to a collection of type
 	// NetworkInterfaceCollection.
 	networkInterfaces string
+	// Oem contains the vendor specific properties of the system, as found in
+	// its Oem section. It is vendor responsibility to parse this field
+	// accordingly.
+	Oem json.RawMessage
 	// OperatingSystem shall contain a link to a resource of type OperatingSystem that contains operating system
 	// information for this system.
 	OperatingSystem string
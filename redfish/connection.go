@@ -156,20 +156,8 @@ func (connection *Connection) UnmarshalJSON(b []byte) error {
 
 // GetConnection will get a Connection instance from the service.
 func GetConnection(c common.Client, uri string) (*Connection, error) {
-	resp, err := c.Get(uri)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
 	var connection Connection
-	err = json.NewDecoder(resp.Body).Decode(&connection)
-	if err != nil {
-		return nil, err
-	}
-
-	connection.SetClient(c)
-	return &connection, nil
+	return &connection, connection.Get(c, uri, &connection)
 }
 
 // ListReferencedConnections gets the collection of Connection from
@@ -0,0 +1,215 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// The layout of a UEFI Common Platform Error Record (CPER), as defined in
+// appendix N of the UEFI Specification.
+const (
+	cperHeaderLength            = 128
+	cperSectionDescriptorLength = 72
+	cperSignatureEnd            = 0xFFFFFFFF
+	cperTimestampValid          = 1 << 1
+)
+
+// CPERSeverity is the severity of a CPER record or section.
+type CPERSeverity uint32
+
+const (
+	// RecoverableCPERSeverity means the error was not corrected but the
+	// system can continue.
+	RecoverableCPERSeverity CPERSeverity = 0
+	// FatalCPERSeverity means the error is fatal.
+	FatalCPERSeverity CPERSeverity = 1
+	// CorrectedCPERSeverity means the error was corrected.
+	CorrectedCPERSeverity CPERSeverity = 2
+	// InformationalCPERSeverity means the record is informational.
+	InformationalCPERSeverity CPERSeverity = 3
+)
+
+func (severity CPERSeverity) String() string {
+	switch severity {
+	case RecoverableCPERSeverity:
+		return "Recoverable"
+	case FatalCPERSeverity:
+		return "Fatal"
+	case CorrectedCPERSeverity:
+		return "Corrected"
+	case InformationalCPERSeverity:
+		return "Informational"
+	}
+	return fmt.Sprintf("Unknown(%d)", uint32(severity))
+}
+
+// cperSectionTypes are the names of the standard CPER section types.
+var cperSectionTypes = map[string]string{
+	"9876CCAD-47B4-4BDB-B65E-16F193C4F3DB": "Processor Generic",
+	"DC3EA0B0-A144-4797-B95B-53FA242B6E1D": "IA32/X64",
+	"E19E3D16-BC11-11E4-9CAA-C2051D5D46B0": "ARM",
+	"A5BC1114-6F64-4EDE-B863-3E83ED7C83B1": "Platform Memory",
+	"61EC04FC-48E6-D813-25C9-8DAA44750B12": "Platform Memory 2",
+	"D995E954-BBC1-430F-AD91-B44DCB3C6F35": "PCI Express",
+	"C5753963-3B84-4095-BF78-EDDAD3F9C9DD": "PCI/PCI-X Bus",
+	"EB5E4685-CA66-4769-B6A2-26068B001326": "PCI Component",
+	"81212A96-09ED-4996-9471-8D729C8E69ED": "Firmware Error Record Reference",
+	"5B51FEF7-C79D-4434-8F1B-AA62DE3E2C64": "DMAr Generic",
+	"80B9EFB4-52B5-4DE3-A777-68784B771048": "CXL Protocol Error",
+}
+
+// cperGUID formats a GUID in the mixed-endian layout of UEFI.
+func cperGUID(b []byte) string {
+	return fmt.Sprintf("%08X-%04X-%04X-%X-%X",
+		binary.LittleEndian.Uint32(b[0:4]), binary.LittleEndian.Uint16(b[4:6]), binary.LittleEndian.Uint16(b[6:8]), b[8:10], b[10:16])
+}
+
+// bcd decodes a binary-coded decimal byte.
+func bcd(b byte) int {
+	return int(b>>4)*10 + int(b&0x0F)
+}
+
+// cperTimestamp decodes a CPER timestamp, which stores each field as BCD.
+func cperTimestamp(b []byte) time.Time {
+	year := bcd(b[7])*100 + bcd(b[6])
+	return time.Date(year, time.Month(bcd(b[5])), bcd(b[4]), bcd(b[2]), bcd(b[1]), bcd(b[0]), 0, time.UTC)
+}
+
+// CPERSection is a section of a CPER record.
+type CPERSection struct {
+	// SectionType is the GUID of the type of the section.
+	SectionType string
+	// SectionTypeName is the name of a standard section type, such as
+	// 'Platform Memory', or empty for other types.
+	SectionTypeName string
+	// Severity is the severity of the section.
+	Severity CPERSeverity
+	// Revision is the revision of the section format.
+	Revision uint16
+	// Flags are the flags of the section.
+	Flags uint32
+	// FRUID is the GUID of the field replaceable unit, if valid.
+	FRUID string
+	// FRUText describes the field replaceable unit, if valid.
+	FRUText string
+	// Data is the body of the section.
+	Data []byte
+}
+
+// CPERRecord is a UEFI Common Platform Error Record.
+type CPERRecord struct {
+	// Revision is the revision of the record format.
+	Revision uint16
+	// Severity is the severity of the record.
+	Severity CPERSeverity
+	// Timestamp is the time the error occurred, if valid.
+	Timestamp time.Time
+	// PlatformID is the GUID of the platform, if valid.
+	PlatformID string
+	// CreatorID is the GUID of the creator of the record.
+	CreatorID string
+	// NotificationType is the GUID of the notification type, such as
+	// Machine Check Exception.
+	NotificationType string
+	// RecordID is the unique ID of the record.
+	RecordID uint64
+	// Flags are the flags of the record.
+	Flags uint32
+	// Sections are the sections of the record.
+	Sections []CPERSection
+}
+
+// DecodeCPER decodes a UEFI Common Platform Error Record, such as the
+// diagnostic data of a log entry of type CPER.
+func DecodeCPER(data []byte) (*CPERRecord, error) {
+	if len(data) < cperHeaderLength {
+		return nil, fmt.Errorf("CPER record is %d bytes, which is shorter than its header", len(data))
+	}
+	if !bytes.Equal(data[0:4], []byte("CPER")) || binary.LittleEndian.Uint32(data[6:10]) != cperSignatureEnd {
+		return nil, errors.New("data is not a CPER record")
+	}
+
+	sectionCount := int(binary.LittleEndian.Uint16(data[10:12]))
+	validationBits := binary.LittleEndian.Uint32(data[16:20])
+	recordLength := int(binary.LittleEndian.Uint32(data[20:24]))
+	if recordLength > len(data) {
+		return nil, fmt.Errorf("CPER record is %d bytes rather than %d", len(data), recordLength)
+	}
+	if cperHeaderLength+sectionCount*cperSectionDescriptorLength > recordLength {
+		return nil, fmt.Errorf("CPER record of %d bytes cannot hold %d sections", recordLength, sectionCount)
+	}
+
+	record := &CPERRecord{
+		Revision:         binary.LittleEndian.Uint16(data[4:6]),
+		Severity:         CPERSeverity(binary.LittleEndian.Uint32(data[12:16])),
+		CreatorID:        cperGUID(data[64:80]),
+		NotificationType: cperGUID(data[80:96]),
+		RecordID:         binary.LittleEndian.Uint64(data[96:104]),
+		Flags:            binary.LittleEndian.Uint32(data[104:108]),
+	}
+	if validationBits&1 != 0 {
+		record.PlatformID = cperGUID(data[32:48])
+	}
+	if validationBits&cperTimestampValid != 0 {
+		record.Timestamp = cperTimestamp(data[24:32])
+	}
+
+	for i := 0; i < sectionCount; i++ {
+		descriptor := data[cperHeaderLength+i*cperSectionDescriptorLength:][:cperSectionDescriptorLength]
+		offset := int(binary.LittleEndian.Uint32(descriptor[0:4]))
+		length := int(binary.LittleEndian.Uint32(descriptor[4:8]))
+		if offset+length > recordLength || offset+length < offset {
+			return nil, fmt.Errorf("CPER section %d is outside the record", i)
+		}
+
+		section := CPERSection{
+			Revision:    binary.LittleEndian.Uint16(descriptor[8:10]),
+			Flags:       binary.LittleEndian.Uint32(descriptor[12:16]),
+			SectionType: cperGUID(descriptor[16:32]),
+			Severity:    CPERSeverity(binary.LittleEndian.Uint32(descriptor[48:52])),
+			Data:        data[offset : offset+length],
+		}
+		section.SectionTypeName = cperSectionTypes[section.SectionType]
+		if descriptor[10]&1 != 0 {
+			section.FRUID = cperGUID(descriptor[32:48])
+		}
+		if descriptor[10]&2 != 0 {
+			section.FRUText = strings.TrimRight(string(descriptor[52:72]), "\x00")
+		}
+		record.Sections = append(record.Sections, section)
+	}
+
+	return record, nil
+}
+
+// DecodeCPER decodes the diagnostic data of a log entry of type CPER. The
+// data is taken from DiagnosticData, or downloaded from AdditionalDataURI if
+// the log entry does not contain it.
+func (logentry *LogEntry) DecodeCPER() (*CPERRecord, error) {
+	if logentry.DiagnosticDataType != CPERLogDiagnosticDataTypes {
+		return nil, fmt.Errorf("log entry %s has diagnostic data of type '%s' rather than CPER", logentry.ID, logentry.DiagnosticDataType)
+	}
+
+	if logentry.DiagnosticData != "" {
+		data, err := base64.StdEncoding.DecodeString(logentry.DiagnosticData)
+		if err != nil {
+			return nil, fmt.Errorf("diagnostic data of log entry %s is not valid base64: %w", logentry.ID, err)
+		}
+		return DecodeCPER(data)
+	}
+
+	var buf bytes.Buffer
+	if _, err := logentry.DownloadAdditionalData(&buf); err != nil {
+		return nil, err
+	}
+	return DecodeCPER(buf.Bytes())
+}
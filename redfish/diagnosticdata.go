@@ -0,0 +1,242 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultTaskPollInterval is the interval between reads of a task if none is
+// configured.
+const defaultTaskPollInterval = 5 * time.Second
+
+// CollectDiagnosticDataParameters are the parameters of the
+// CollectDiagnosticData action of a log service.
+type CollectDiagnosticDataParameters struct {
+	// DiagnosticDataType is the type of diagnostic data to collect.
+	DiagnosticDataType LogDiagnosticDataTypes
+	// OEMDiagnosticDataType is the OEM-defined type of diagnostic data to
+	// collect. It is required if DiagnosticDataType is OEM.
+	OEMDiagnosticDataType string `json:",omitempty"`
+	// TargetURI is the URI the service pushes the diagnostic data to, if
+	// supported.
+	TargetURI string `json:",omitempty"`
+	// UserName is the user name to access the target URI.
+	UserName string `json:",omitempty"`
+	// Password is the password to access the target URI.
+	Password string `json:",omitempty"`
+}
+
+// isTaskFinished reports whether a task in this state will not change state
+// again.
+func isTaskFinished(state TaskState) bool {
+	return state == CompletedTaskState || state == KilledTaskState ||
+		state == ExceptionTaskState || state == CancelledTaskState
+}
+
+// CollectDiagnosticData collects diagnostic data of the given type, which the
+// service stores as the additional data of a new log entry. If the service
+// collects the data right away, the log entry is returned. Otherwise the task
+// tracking the collection is returned; WaitForDiagnosticData returns the log
+// entry once it finished.
+func (logservice *LogService) CollectDiagnosticData(parameters *CollectDiagnosticDataParameters) (*LogEntry, *Task, error) {
+	if logservice.collectDiagnosticDataTarget == "" {
+		return nil, nil, errors.New("CollectDiagnosticData is not supported by this log service")
+	}
+	if parameters == nil || parameters.DiagnosticDataType == "" {
+		return nil, nil, errors.New("diagnostic data type should not be empty")
+	}
+	if parameters.DiagnosticDataType == OEMLogDiagnosticDataTypes && parameters.OEMDiagnosticDataType == "" {
+		return nil, nil, errors.New("OEM diagnostic data type should not be empty")
+	}
+	if len(logservice.SupportedDiagnosticDataTypes) > 0 {
+		supported := false
+		for _, dataType := range logservice.SupportedDiagnosticDataTypes {
+			if dataType == parameters.DiagnosticDataType {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return nil, nil, fmt.Errorf("diagnostic data type '%s' is not supported by this log service", parameters.DiagnosticDataType)
+		}
+	}
+
+	resp, err := logservice.PostWithResponse(logservice.collectDiagnosticDataTarget, parameters)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusAccepted {
+		task, err := TaskFromResponse(logservice.GetClient(), resp)
+		return nil, task, err
+	}
+
+	location := locationFromResponse(resp)
+	if location == "" {
+		return nil, nil, errors.New("service did not return the location of the diagnostic data")
+	}
+	entry, err := GetLogEntry(logservice.GetClient(), location)
+	return entry, nil, err
+}
+
+// DiagnosticDataEntry gets the log entry created by a finished diagnostic
+// data collection task. The log entry is taken from the resources the task
+// created, or from the Location header of its task monitor.
+func (logservice *LogService) DiagnosticDataEntry(task *Task) (*LogEntry, error) {
+	if task.TaskState != CompletedTaskState {
+		return nil, fmt.Errorf("diagnostic data collection %s is %s", task.ID, task.TaskState)
+	}
+
+	if len(task.Links.CreatedResources) > 0 {
+		return GetLogEntry(logservice.GetClient(), task.Links.CreatedResources[0])
+	}
+
+	if task.TaskMonitor != "" {
+		resp, err := logservice.GetClient().Get(task.TaskMonitor)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body.Close()
+
+		if location := locationFromResponse(resp); location != "" && location != task.TaskMonitor {
+			return GetLogEntry(logservice.GetClient(), location)
+		}
+	}
+
+	return nil, fmt.Errorf("diagnostic data collection %s did not report its log entry", task.ID)
+}
+
+// WaitForDiagnosticData polls a diagnostic data collection task until it
+// finished and returns the log entry it created.
+func (logservice *LogService) WaitForDiagnosticData(task *Task, timeout, interval time.Duration) (*LogEntry, error) {
+	if interval <= 0 {
+		interval = defaultTaskPollInterval
+	}
+
+	uri := task.ODataID
+	if uri == "" {
+		uri = task.TaskMonitor
+	}
+	if uri == "" && !isTaskFinished(task.TaskState) {
+		return nil, errors.New("diagnostic data collection task has no URI to poll")
+	}
+
+	deadline := time.Now().Add(timeout)
+	current := task
+	for !isTaskFinished(current.TaskState) {
+		if time.Now().Add(interval).After(deadline) {
+			return nil, fmt.Errorf("diagnostic data collection %s is %s after %s", task.ID, current.TaskState, timeout)
+		}
+		time.Sleep(interval)
+
+		var err error
+		current, err = GetTask(logservice.GetClient(), uri)
+		if err != nil {
+			return nil, err
+		}
+		if current.TaskMonitor == "" {
+			current.TaskMonitor = task.TaskMonitor
+		}
+	}
+
+	if current.TaskState != CompletedTaskState && len(current.Messages) > 0 {
+		return nil, fmt.Errorf("diagnostic data collection %s is %s: %s", task.ID, current.TaskState, current.Messages[0].Message)
+	}
+	return logservice.DiagnosticDataEntry(current)
+}
+
+// digestHash returns the hash and expected value of the first supported
+// algorithm of a Digest header, such as 'SHA-256=<base64>'.
+func digestHash(header string) (h hash.Hash, expected []byte) {
+	for _, part := range strings.Split(header, ",") {
+		i := strings.Index(part, "=")
+		if i < 0 {
+			continue
+		}
+		value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(part[i+1:]))
+		if err != nil {
+			continue
+		}
+		switch strings.ToUpper(strings.TrimSpace(part[:i])) {
+		case "SHA-256":
+			return sha256.New(), value
+		case "SHA-512":
+			return sha512.New(), value
+		}
+	}
+	return nil, nil
+}
+
+// DownloadAdditionalData writes the additional data of the log entry, such as
+// collected diagnostic data, to w and returns the number of bytes written. The
+// size is checked against AdditionalDataSizeBytes and the Content-Length of
+// the response. The data is checked against the Digest header of the
+// response and the DiagnosticData of the log entry, if present. If the log
+// entry has no AdditionalDataURI, its DiagnosticData is written instead.
+func (logentry *LogEntry) DownloadAdditionalData(w io.Writer) (int64, error) {
+	var inline []byte
+	if logentry.DiagnosticData != "" {
+		var err error
+		inline, err = base64.StdEncoding.DecodeString(logentry.DiagnosticData)
+		if err != nil {
+			return 0, fmt.Errorf("diagnostic data of log entry %s is not valid base64: %w", logentry.ID, err)
+		}
+	}
+
+	if logentry.AdditionalDataURI == "" {
+		if inline == nil {
+			return 0, fmt.Errorf("log entry %s has no additional data", logentry.ID)
+		}
+		n, err := w.Write(inline)
+		return int64(n), err
+	}
+
+	resp, err := logentry.GetClient().Get(logentry.AdditionalDataURI)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	sum := sha256.New()
+	writers := []io.Writer{w, sum}
+	digest, expectedDigest := digestHash(resp.Header.Get("Digest"))
+	if digest != nil {
+		writers = append(writers, digest)
+	}
+
+	written, err := io.Copy(io.MultiWriter(writers...), resp.Body)
+	if err != nil {
+		return written, err
+	}
+
+	if logentry.AdditionalDataSizeBytes > 0 && written != int64(logentry.AdditionalDataSizeBytes) {
+		return written, fmt.Errorf("additional data of log entry %s is %d bytes rather than %d", logentry.ID, written, logentry.AdditionalDataSizeBytes)
+	}
+	if resp.ContentLength > 0 && written != resp.ContentLength {
+		return written, fmt.Errorf("additional data of log entry %s was truncated: got %d of %d bytes", logentry.ID, written, resp.ContentLength)
+	}
+	if digest != nil && !bytes.Equal(digest.Sum(nil), expectedDigest) {
+		return written, fmt.Errorf("additional data of log entry %s does not match its digest", logentry.ID)
+	}
+	if inline != nil {
+		expected := sha256.Sum256(inline)
+		if !bytes.Equal(sum.Sum(nil), expected[:]) {
+			return written, fmt.Errorf("additional data of log entry %s does not match its diagnostic data", logentry.ID)
+		}
+	}
+	return written, nil
+}
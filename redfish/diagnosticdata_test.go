@@ -0,0 +1,254 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+var diagnosticLogServiceBody = `{
+		"@odata.type": "#LogService.v1_2_0.LogService",
+		"@odata.id": "/redfish/v1/Systems/1/LogServices/Diagnostic",
+		"Id": "Diagnostic",
+		"Name": "Diagnostic Log Service",
+		"Entries": {
+			"@odata.id": "/redfish/v1/Systems/1/LogServices/Diagnostic/Entries"
+		},
+		"Actions": {
+			"#LogService.CollectDiagnosticData": {
+				"target": "/redfish/v1/Systems/1/LogServices/Diagnostic/Actions/LogService.CollectDiagnosticData",
+				"DiagnosticDataType@Redfish.AllowableValues": ["OS", "OEM"],
+				"OEMDiagnosticDataType@Redfish.AllowableValues": ["SystemDump"]
+			}
+		}
+	}`
+
+var diagnosticTaskBody = `{
+		"@odata.type": "#Task.v1_4_3.Task",
+		"@odata.id": "/redfish/v1/TaskService/Tasks/7",
+		"Id": "7",
+		"Name": "Collect diagnostic data",
+		"TaskMonitor": "/redfish/v1/TaskService/TaskMonitors/7",
+		"TaskState": "%s",
+		"Links": {
+			"CreatedResources": [
+				{
+					"@odata.id": "/redfish/v1/Systems/1/LogServices/Diagnostic/Entries/12"
+				}
+			]
+		}
+	}`
+
+var diagnosticLogEntryBody = `{
+		"@odata.type": "#LogEntry.v1_9_0.LogEntry",
+		"@odata.id": "/redfish/v1/Systems/1/LogServices/Diagnostic/Entries/12",
+		"Id": "12",
+		"Name": "OS Dump",
+		"EntryType": "Event",
+		"DiagnosticDataType": "OS",
+		"AdditionalDataURI": "/redfish/v1/Systems/1/LogServices/Diagnostic/Entries/12/attachment",
+		"AdditionalDataSizeBytes": 9
+	}`
+
+func diagnosticTaskCall(state TaskState) *http.Response {
+	return getCall(strings.Replace(diagnosticTaskBody, "%s", string(state), 1))
+}
+
+// TestCollectDiagnosticData tests collecting diagnostic data through a task.
+func TestCollectDiagnosticData(t *testing.T) {
+	var result LogService
+	if err := json.NewDecoder(strings.NewReader(diagnosticLogServiceBody)).Decode(&result); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	if len(result.SupportedDiagnosticDataTypes) != 2 || result.SupportedOEMDiagnosticDataTypes[0] != "SystemDump" {
+		t.Errorf("Invalid supported diagnostic data types: %v %v", result.SupportedDiagnosticDataTypes, result.SupportedOEMDiagnosticDataTypes)
+	}
+
+	header := make(http.Header)
+	header.Set("Location", "/redfish/v1/TaskService/TaskMonitors/7")
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodPost: {&http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(strings.NewReader(diagnosticTaskBody)), Header: header}},
+			http.MethodGet: {
+				diagnosticTaskCall(CompletedTaskState),
+				getCall(diagnosticLogEntryBody),
+			},
+		},
+	}
+	result.SetClient(testClient)
+
+	if _, _, err := result.CollectDiagnosticData(&CollectDiagnosticDataParameters{DiagnosticDataType: ManagerLogDiagnosticDataTypes}); err == nil {
+		t.Error("Expected an unsupported diagnostic data type to fail")
+	}
+	if _, _, err := result.CollectDiagnosticData(&CollectDiagnosticDataParameters{DiagnosticDataType: OEMLogDiagnosticDataTypes}); err == nil {
+		t.Error("Expected an OEM diagnostic data type without a name to fail")
+	}
+
+	entry, task, err := result.CollectDiagnosticData(&CollectDiagnosticDataParameters{DiagnosticDataType: OSLogDiagnosticDataTypes})
+	if err != nil {
+		t.Fatalf("Error collecting diagnostic data: %s", err)
+	}
+	if entry != nil || task == nil {
+		t.Fatalf("Expected a task, got %v and %v", entry, task)
+	}
+	if task.Links.CreatedResources[0] != "/redfish/v1/Systems/1/LogServices/Diagnostic/Entries/12" {
+		t.Errorf("Invalid created resources: %v", task.Links.CreatedResources)
+	}
+
+	task.TaskState = RunningTaskState
+	entry, err = result.WaitForDiagnosticData(task, time.Minute, time.Millisecond)
+	if err != nil {
+		t.Fatalf("Error waiting for diagnostic data: %s", err)
+	}
+	if entry.ID != "12" || entry.AdditionalDataSizeBytes != 9 {
+		t.Errorf("Invalid log entry: %+v", entry)
+	}
+
+	calls := testClient.CapturedCalls()
+	if calls[0].Payload != "map[DiagnosticDataType:OS]" {
+		t.Errorf("Invalid CollectDiagnosticData payload: %s", calls[0].Payload)
+	}
+	if calls[1].URL != "/redfish/v1/TaskService/Tasks/7" {
+		t.Errorf("Invalid task call: %+v", calls[1])
+	}
+}
+
+// TestCollectDiagnosticDataNoTask tests that an accepted request without a
+// task or location to track it fails.
+func TestCollectDiagnosticDataNoTask(t *testing.T) {
+	var result LogService
+	if err := json.NewDecoder(strings.NewReader(diagnosticLogServiceBody)).Decode(&result); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodPost: {common.TestResponse(http.StatusAccepted, "")},
+		},
+	}
+	result.SetClient(testClient)
+
+	if _, task, err := result.CollectDiagnosticData(&CollectDiagnosticDataParameters{DiagnosticDataType: OSLogDiagnosticDataTypes}); err == nil {
+		t.Errorf("Expected an error, got task %+v", task)
+	}
+
+	if _, err := result.WaitForDiagnosticData(&Task{TaskState: RunningTaskState}, time.Minute, time.Millisecond); err == nil {
+		t.Error("Expected waiting for a task without a URI to fail")
+	}
+	if calls := testClient.CapturedCalls(); len(calls) != 1 {
+		t.Errorf("Expected no task to be polled, captured: %v", calls)
+	}
+}
+
+// TestDownloadAdditionalData tests the size and integrity checks of
+// downloading additional data.
+func TestDownloadAdditionalData(t *testing.T) {
+	data := "os-dump-1"
+	digest := sha256.Sum256([]byte(data))
+
+	var entry LogEntry
+	if err := json.NewDecoder(strings.NewReader(diagnosticLogEntryBody)).Decode(&entry); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	withDigest := getCall(data)
+	withDigest.Header.Set("Digest", "SHA-256="+base64.StdEncoding.EncodeToString(digest[:]))
+	badDigest := getCall(data)
+	badDigest.Header.Set("Digest", "SHA-256="+base64.StdEncoding.EncodeToString(make([]byte, sha256.Size)))
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {withDigest, badDigest, getCall("os-dump"), getCall(data)},
+		},
+	}
+	entry.SetClient(testClient)
+
+	var buf bytes.Buffer
+	written, err := entry.DownloadAdditionalData(&buf)
+	if err != nil {
+		t.Fatalf("Error downloading additional data: %s", err)
+	}
+	if written != int64(len(data)) || buf.String() != data {
+		t.Errorf("Invalid additional data: %q", buf.String())
+	}
+
+	if _, err := entry.DownloadAdditionalData(io.Discard); err == nil {
+		t.Error("Expected a digest mismatch to fail")
+	}
+	if _, err := entry.DownloadAdditionalData(io.Discard); err == nil {
+		t.Error("Expected a size mismatch to fail")
+	}
+
+	entry.DiagnosticData = base64.StdEncoding.EncodeToString([]byte("os-dump-2"))
+	if _, err := entry.DownloadAdditionalData(io.Discard); err == nil {
+		t.Error("Expected a diagnostic data mismatch to fail")
+	}
+}
+
+// cperRecord builds a CPER record with a single memory error section.
+func cperRecord() []byte {
+	body := []byte("memory-error")
+	record := make([]byte, cperHeaderLength+cperSectionDescriptorLength+len(body))
+	copy(record, "CPER")
+	binary.LittleEndian.PutUint16(record[4:], 0x0101)
+	binary.LittleEndian.PutUint32(record[6:], cperSignatureEnd)
+	binary.LittleEndian.PutUint16(record[10:], 1)
+	binary.LittleEndian.PutUint32(record[12:], uint32(CorrectedCPERSeverity))
+	binary.LittleEndian.PutUint32(record[16:], cperTimestampValid)
+	binary.LittleEndian.PutUint32(record[20:], uint32(len(record)))
+	// 2023-03-21 10:12:37
+	copy(record[24:], []byte{0x37, 0x12, 0x10, 0x00, 0x21, 0x03, 0x23, 0x20})
+	binary.LittleEndian.PutUint64(record[96:], 42)
+
+	descriptor := record[cperHeaderLength:]
+	binary.LittleEndian.PutUint32(descriptor[0:], uint32(cperHeaderLength+cperSectionDescriptorLength))
+	binary.LittleEndian.PutUint32(descriptor[4:], uint32(len(body)))
+	descriptor[10] = 2
+	// A5BC1114-6F64-4EDE-B863-3E83ED7C83B1
+	copy(descriptor[16:], []byte{0x14, 0x11, 0xBC, 0xA5, 0x64, 0x6F, 0xDE, 0x4E, 0xB8, 0x63, 0x3E, 0x83, 0xED, 0x7C, 0x83, 0xB1})
+	binary.LittleEndian.PutUint32(descriptor[48:], uint32(CorrectedCPERSeverity))
+	copy(descriptor[52:], "DIMM A1")
+	copy(record[cperHeaderLength+cperSectionDescriptorLength:], body)
+	return record
+}
+
+// TestDecodeCPER tests decoding CPER diagnostic data.
+func TestDecodeCPER(t *testing.T) {
+	entry := LogEntry{
+		DiagnosticDataType: CPERLogDiagnosticDataTypes,
+		DiagnosticData:     base64.StdEncoding.EncodeToString(cperRecord()),
+	}
+
+	record, err := entry.DecodeCPER()
+	if err != nil {
+		t.Fatalf("Error decoding CPER: %s", err)
+	}
+	if record.Severity != CorrectedCPERSeverity || record.RecordID != 42 ||
+		record.Timestamp.Format(time.RFC3339) != "2023-03-21T10:12:37Z" {
+		t.Errorf("Invalid CPER record: %+v", record)
+	}
+	if len(record.Sections) != 1 {
+		t.Fatalf("Invalid CPER sections: %+v", record.Sections)
+	}
+	section := record.Sections[0]
+	if section.SectionTypeName != "Platform Memory" || section.FRUText != "DIMM A1" || string(section.Data) != "memory-error" {
+		t.Errorf("Invalid CPER section: %+v", section)
+	}
+
+	if _, err := DecodeCPER(cperRecord()[:cperHeaderLength]); err == nil {
+		t.Error("Expected a truncated CPER record to fail")
+	}
+}
@@ -0,0 +1,229 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EFIGUID is a GUID in the mixed-endian binary layout used by UEFI.
+type EFIGUID [16]byte
+
+// ParseEFIGUID parses a GUID in its canonical string form, such as
+// "c1c41626-504c-4092-aca9-41f936934328".
+func ParseEFIGUID(s string) (EFIGUID, error) {
+	var guid EFIGUID
+
+	b, err := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+	if err != nil || len(b) != 16 || len(s) != 36 {
+		return guid, fmt.Errorf("invalid GUID %q", s)
+	}
+
+	binary.LittleEndian.PutUint32(guid[0:4], binary.BigEndian.Uint32(b[0:4]))
+	binary.LittleEndian.PutUint16(guid[4:6], binary.BigEndian.Uint16(b[4:6]))
+	binary.LittleEndian.PutUint16(guid[6:8], binary.BigEndian.Uint16(b[6:8]))
+	copy(guid[8:], b[8:])
+	return guid, nil
+}
+
+func mustParseEFIGUID(s string) EFIGUID {
+	guid, err := ParseEFIGUID(s)
+	if err != nil {
+		panic(err)
+	}
+	return guid
+}
+
+// String returns the canonical lower case string form of the GUID.
+func (guid EFIGUID) String() string {
+	return fmt.Sprintf("%08x-%04x-%04x-%x-%x",
+		binary.LittleEndian.Uint32(guid[0:4]),
+		binary.LittleEndian.Uint16(guid[4:6]),
+		binary.LittleEndian.Uint16(guid[6:8]),
+		guid[8:10], guid[10:16])
+}
+
+// Signature types defined in the UEFI Specification.
+var (
+	// EFICertSHA256GUID is the type of SHA-256 hashes.
+	EFICertSHA256GUID = mustParseEFIGUID("c1c41626-504c-4092-aca9-41f936934328")
+	// EFICertSHA1GUID is the type of SHA-1 hashes.
+	EFICertSHA1GUID = mustParseEFIGUID("826ca512-cf10-4ac9-b187-be01496631bd")
+	// EFICertSHA384GUID is the type of SHA-384 hashes.
+	EFICertSHA384GUID = mustParseEFIGUID("ff3e5307-9fd0-48c9-85f1-8ad56c701e01")
+	// EFICertSHA512GUID is the type of SHA-512 hashes.
+	EFICertSHA512GUID = mustParseEFIGUID("093e0fae-a6c4-4f50-9f1b-d41e2b89c19a")
+	// EFICertRSA2048GUID is the type of RSA-2048 public keys.
+	EFICertRSA2048GUID = mustParseEFIGUID("3c5766e8-269c-4e34-aa14-ed776e85b3b6")
+	// EFICertX509GUID is the type of DER encoded X.509 certificates.
+	EFICertX509GUID = mustParseEFIGUID("a5c059a1-94e4-4aa7-87b5-ab155c2bf072")
+	// EFICertX509SHA256GUID is the type of SHA-256 hashes of the TBS part of
+	// X.509 certificates, along with a revocation time.
+	EFICertX509SHA256GUID = mustParseEFIGUID("3bd2a492-96c0-4079-b420-fcf98ef103ed")
+	// EFICertTypePKCS7GUID is the type of the PKCS7 signature authenticating a
+	// time based authenticated variable.
+	EFICertTypePKCS7GUID = mustParseEFIGUID("4aafd29d-68df-49ee-8aa9-347d375665a7")
+)
+
+// efiSignatureTypeNames are the names of the signature types, which are used as
+// the SignatureType of Signature resources in the UEFI registry.
+var efiSignatureTypeNames = map[EFIGUID]string{
+	EFICertSHA256GUID:     "EFI_CERT_SHA256_GUID",
+	EFICertSHA1GUID:       "EFI_CERT_SHA1_GUID",
+	EFICertSHA384GUID:     "EFI_CERT_SHA384_GUID",
+	EFICertSHA512GUID:     "EFI_CERT_SHA512_GUID",
+	EFICertRSA2048GUID:    "EFI_CERT_RSA2048_GUID",
+	EFICertX509GUID:       "EFI_CERT_X509_GUID",
+	EFICertX509SHA256GUID: "EFI_CERT_X509_SHA256_GUID",
+}
+
+// EFISignatureTypeName returns the UEFI name of a signature type, such as
+// "EFI_CERT_SHA256_GUID", or an empty string for an unknown type.
+func EFISignatureTypeName(signatureType EFIGUID) string {
+	return efiSignatureTypeNames[signatureType]
+}
+
+// EFISignatureData is a single entry of an EFI signature list.
+type EFISignatureData struct {
+	// Owner is the GUID of the agent that added the signature.
+	Owner EFIGUID
+	// Data is the signature data, such as a hash or a DER encoded certificate.
+	Data []byte
+}
+
+// EFISignatureList is an EFI_SIGNATURE_LIST, a set of signatures of the same
+// type as stored in UEFI Secure Boot databases.
+type EFISignatureList struct {
+	// Type is the type of the signatures in the list.
+	Type EFIGUID
+	// Header is the signature type specific header.
+	Header []byte
+	// Signatures are the signatures in the list.
+	Signatures []EFISignatureData
+}
+
+// efiSignatureListHeaderSize is the size of the fixed part of an
+// EFI_SIGNATURE_LIST.
+const efiSignatureListHeaderSize = 28
+
+// ParseEFISignatureLists parses a sequence of EFI signature lists, as found in
+// .esl files and in the content of UEFI Secure Boot variables.
+func ParseEFISignatureLists(b []byte) ([]EFISignatureList, error) {
+	var result []EFISignatureList
+	for len(b) > 0 {
+		if len(b) < efiSignatureListHeaderSize {
+			return nil, errors.New("truncated EFI signature list")
+		}
+
+		var list EFISignatureList
+		copy(list.Type[:], b[0:16])
+		listSize := int(binary.LittleEndian.Uint32(b[16:20]))
+		headerSize := int(binary.LittleEndian.Uint32(b[20:24]))
+		signatureSize := int(binary.LittleEndian.Uint32(b[24:28]))
+
+		if listSize < efiSignatureListHeaderSize+headerSize || listSize > len(b) {
+			return nil, fmt.Errorf("invalid EFI signature list size %d", listSize)
+		}
+		list.Header = b[efiSignatureListHeaderSize : efiSignatureListHeaderSize+headerSize]
+
+		signatures := b[efiSignatureListHeaderSize+headerSize : listSize]
+		if signatureSize <= 16 || len(signatures)%signatureSize != 0 {
+			return nil, fmt.Errorf("invalid EFI signature size %d", signatureSize)
+		}
+		for ; len(signatures) > 0; signatures = signatures[signatureSize:] {
+			var signature EFISignatureData
+			copy(signature.Owner[:], signatures[0:16])
+			signature.Data = signatures[16:signatureSize]
+			list.Signatures = append(list.Signatures, signature)
+		}
+
+		result = append(result, list)
+		b = b[listSize:]
+	}
+	return result, nil
+}
+
+// EFIAuthenticatedVariable is the content of a time based authenticated
+// variable update, such as a .auth file or the dbxupdate.bin published by the
+// UEFI Forum.
+type EFIAuthenticatedVariable struct {
+	// Timestamp is the time stamp of the update.
+	Timestamp time.Time
+	// CertificateType is the type of the authentication data, normally
+	// EFICertTypePKCS7GUID.
+	CertificateType EFIGUID
+	// Authentication is the authentication data, normally a DER encoded PKCS7
+	// signature.
+	Authentication []byte
+	// SignatureLists are the signature lists of the update.
+	SignatureLists []EFISignatureList
+}
+
+const (
+	// efiTimeSize is the size of an EFI_TIME structure.
+	efiTimeSize = 16
+	// winCertTypeEFIGUID is the WIN_CERTIFICATE type of a WIN_CERTIFICATE_UEFI_GUID.
+	winCertTypeEFIGUID = 0x0EF1
+	// winCertRevision is the revision of WIN_CERTIFICATE structures.
+	winCertRevision = 0x0200
+)
+
+// isEFIAuthenticatedVariable reports whether b starts with an
+// EFI_VARIABLE_AUTHENTICATION_2 header.
+func isEFIAuthenticatedVariable(b []byte) bool {
+	return len(b) >= efiTimeSize+24 &&
+		binary.LittleEndian.Uint16(b[efiTimeSize+4:]) == winCertRevision &&
+		binary.LittleEndian.Uint16(b[efiTimeSize+6:]) == winCertTypeEFIGUID
+}
+
+// ParseEFIAuthenticatedVariable parses a time based authenticated variable
+// update, which is an EFI_VARIABLE_AUTHENTICATION_2 header followed by EFI
+// signature lists. The authentication is not verified since the firmware
+// does so when the variable is written.
+func ParseEFIAuthenticatedVariable(b []byte) (*EFIAuthenticatedVariable, error) {
+	if !isEFIAuthenticatedVariable(b) {
+		return nil, errors.New("data is not a time based authenticated variable")
+	}
+
+	result := &EFIAuthenticatedVariable{
+		Timestamp: time.Date(
+			int(binary.LittleEndian.Uint16(b[0:2])), time.Month(b[2]), int(b[3]),
+			int(b[4]), int(b[5]), int(b[6]), int(binary.LittleEndian.Uint32(b[8:12])), time.UTC),
+	}
+
+	certificateLength := int(binary.LittleEndian.Uint32(b[efiTimeSize:]))
+	if certificateLength < 24 || efiTimeSize+certificateLength > len(b) {
+		return nil, fmt.Errorf("invalid authentication data length %d", certificateLength)
+	}
+	copy(result.CertificateType[:], b[efiTimeSize+8:efiTimeSize+24])
+	result.Authentication = b[efiTimeSize+24 : efiTimeSize+certificateLength]
+
+	lists, err := ParseEFISignatureLists(b[efiTimeSize+certificateLength:])
+	if err != nil {
+		return nil, err
+	}
+	result.SignatureLists = lists
+
+	return result, nil
+}
+
+// ParseEFISignatureFile parses either a plain signature list file (.esl) or a
+// time based authenticated variable update (.auth or dbxupdate.bin) and
+// returns the signature lists it contains.
+func ParseEFISignatureFile(b []byte) ([]EFISignatureList, error) {
+	if isEFIAuthenticatedVariable(b) {
+		variable, err := ParseEFIAuthenticatedVariable(b)
+		if err != nil {
+			return nil, err
+		}
+		return variable.SignatureLists, nil
+	}
+	return ParseEFISignatureLists(b)
+}
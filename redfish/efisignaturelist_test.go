@@ -0,0 +1,100 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+var testSignatureOwner = mustParseEFIGUID("77fa9abd-0359-4d32-bd60-28f4e78f784b")
+
+// testSignatureList encodes an EFI signature list.
+func testSignatureList(signatureType EFIGUID, entries ...[]byte) []byte {
+	size := 16 + len(entries[0])
+
+	var b bytes.Buffer
+	b.Write(signatureType[:])
+	_ = binary.Write(&b, binary.LittleEndian, []uint32{uint32(efiSignatureListHeaderSize + size*len(entries)), 0, uint32(size)})
+	for _, entry := range entries {
+		b.Write(testSignatureOwner[:])
+		b.Write(entry)
+	}
+	return b.Bytes()
+}
+
+// testAuthenticatedVariable wraps signature lists in an authenticated variable
+// header, like dbxupdate.bin.
+func testAuthenticatedVariable(lists ...[]byte) []byte {
+	authentication := []byte{0x30, 0x82, 0x00, 0x00}
+
+	var b bytes.Buffer
+	_ = binary.Write(&b, binary.LittleEndian, uint16(2023))
+	b.Write([]byte{5, 9, 12, 30, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0})
+	_ = binary.Write(&b, binary.LittleEndian, uint32(24+len(authentication)))
+	_ = binary.Write(&b, binary.LittleEndian, []uint16{winCertRevision, winCertTypeEFIGUID})
+	b.Write(EFICertTypePKCS7GUID[:])
+	b.Write(authentication)
+	for _, list := range lists {
+		b.Write(list)
+	}
+	return b.Bytes()
+}
+
+// TestEFIGUID tests converting GUIDs between string and binary form.
+func TestEFIGUID(t *testing.T) {
+	if EFICertSHA256GUID[0] != 0x26 || EFICertSHA256GUID[15] != 0x28 {
+		t.Errorf("Unexpected GUID layout: %x", EFICertSHA256GUID[:])
+	}
+
+	assertEquals(t, "c1c41626-504c-4092-aca9-41f936934328", EFICertSHA256GUID.String())
+	assertEquals(t, "EFI_CERT_SHA256_GUID", EFISignatureTypeName(EFICertSHA256GUID))
+
+	if _, err := ParseEFIGUID("c1c41626504c4092aca941f936934328"); err == nil {
+		t.Error("Expected GUID without dashes to be rejected")
+	}
+}
+
+// TestParseEFISignatureFile tests parsing plain and authenticated signature list files.
+func TestParseEFISignatureFile(t *testing.T) {
+	hashes := testSignatureList(EFICertSHA256GUID, bytes.Repeat([]byte{0x01}, 32), bytes.Repeat([]byte{0x02}, 32))
+	certificate := testSignatureList(EFICertX509GUID, []byte{0x30, 0x03, 0x02, 0x01, 0x01})
+
+	lists, err := ParseEFISignatureFile(append(append([]byte{}, hashes...), certificate...))
+	if err != nil {
+		t.Fatalf("Error parsing signature lists: %s", err)
+	}
+
+	if len(lists) != 2 || len(lists[0].Signatures) != 2 || len(lists[1].Signatures) != 1 {
+		t.Fatalf("Unexpected signature lists: %v", lists)
+	}
+
+	if lists[0].Signatures[1].Data[0] != 0x02 || lists[0].Signatures[1].Owner != testSignatureOwner {
+		t.Errorf("Unexpected signature: %v", lists[0].Signatures[1])
+	}
+
+	variable, err := ParseEFIAuthenticatedVariable(testAuthenticatedVariable(hashes))
+	if err != nil {
+		t.Fatalf("Error parsing authenticated variable: %s", err)
+	}
+
+	if !variable.Timestamp.Equal(time.Date(2023, 5, 9, 12, 30, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected timestamp: %s", variable.Timestamp)
+	}
+
+	if variable.CertificateType != EFICertTypePKCS7GUID || len(variable.Authentication) != 4 {
+		t.Errorf("Unexpected authentication: %s %x", variable.CertificateType, variable.Authentication)
+	}
+
+	if len(variable.SignatureLists) != 1 || variable.SignatureLists[0].Type != EFICertSHA256GUID {
+		t.Errorf("Unexpected signature lists: %v", variable.SignatureLists)
+	}
+
+	if _, err := ParseEFISignatureLists(hashes[:40]); err == nil {
+		t.Error("Expected truncated signature list to be rejected")
+	}
+}
@@ -0,0 +1,307 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+// ExternalAccountProviderUpdate collects changes to the configuration of an
+// external account provider, such as an LDAP or Active Directory service. Only
+// the properties that differ from the current configuration are sent when the
+// update is committed.
+type ExternalAccountProviderUpdate struct {
+	provider *ExternalAccountProvider
+	// entity is the resource the changes are sent to, which is the account
+	// service for the providers embedded in it.
+	entity *common.Entity
+	uri    string
+	// property is the property of the account service that contains the
+	// provider, or empty if the provider is a resource of its own.
+	property string
+
+	changes        map[string]interface{}
+	searchSettings map[string]interface{}
+	roleMappings   []RoleMapping
+	apply          []func()
+}
+
+func newExternalAccountProviderUpdate(provider *ExternalAccountProvider, entity *common.Entity, property string) *ExternalAccountProviderUpdate {
+	return &ExternalAccountProviderUpdate{
+		provider:       provider,
+		entity:         entity,
+		uri:            entity.ODataID,
+		property:       property,
+		changes:        make(map[string]interface{}),
+		searchSettings: make(map[string]interface{}),
+	}
+}
+
+// ConfigureLDAP starts an update of the LDAP external account provider of
+// this account service.
+func (accountservice *AccountService) ConfigureLDAP() *ExternalAccountProviderUpdate {
+	return newExternalAccountProviderUpdate(&accountservice.LDAP, &accountservice.Entity, "LDAP")
+}
+
+// ConfigureActiveDirectory starts an update of the Active Directory external
+// account provider of this account service.
+func (accountservice *AccountService) ConfigureActiveDirectory() *ExternalAccountProviderUpdate {
+	return newExternalAccountProviderUpdate(&accountservice.ActiveDirectory, &accountservice.Entity, "ActiveDirectory")
+}
+
+// Configure starts an update of an external account provider from the
+// AdditionalExternalAccountProviders collection.
+func (externalaccountprovider *ExternalAccountProvider) Configure() *ExternalAccountProviderUpdate {
+	return newExternalAccountProviderUpdate(externalaccountprovider, &externalaccountprovider.Entity, "")
+}
+
+// ServiceEnabled enables or disables the external account provider.
+func (update *ExternalAccountProviderUpdate) ServiceEnabled(enabled bool) *ExternalAccountProviderUpdate {
+	if update.provider.ServiceEnabled != enabled {
+		update.changes["ServiceEnabled"] = enabled
+		update.apply = append(update.apply, func() { update.provider.ServiceEnabled = enabled })
+	}
+	return update
+}
+
+// ServiceAddresses sets the addresses of the external account provider, such
+// as 'ldaps://ldap.example.com:636'.
+func (update *ExternalAccountProviderUpdate) ServiceAddresses(addresses ...string) *ExternalAccountProviderUpdate {
+	if !reflect.DeepEqual(update.provider.ServiceAddresses, addresses) {
+		update.changes["ServiceAddresses"] = addresses
+		update.apply = append(update.apply, func() { update.provider.ServiceAddresses = addresses })
+	}
+	return update
+}
+
+// Authentication sets the credentials the service uses to connect to the
+// external account provider. Fields that are empty are left unchanged, so a
+// password can be rotated without repeating the username. The password, token
+// and keytab are not returned by services, so they are sent whenever they are
+// set.
+func (update *ExternalAccountProviderUpdate) Authentication(authentication Authentication) *ExternalAccountProviderUpdate {
+	changes := make(map[string]interface{})
+	current := update.provider.Authentication
+	if authentication.AuthenticationType != "" && authentication.AuthenticationType != current.AuthenticationType {
+		changes["AuthenticationType"] = authentication.AuthenticationType
+	}
+	if authentication.Username != "" && authentication.Username != current.Username {
+		changes["Username"] = authentication.Username
+	}
+	if authentication.Password != "" {
+		changes["Password"] = authentication.Password
+	}
+	if authentication.Token != "" {
+		changes["Token"] = authentication.Token
+	}
+	if authentication.KerberosKeytab != "" {
+		changes["KerberosKeytab"] = authentication.KerberosKeytab
+	}
+
+	if len(changes) > 0 {
+		update.changes["Authentication"] = changes
+		update.apply = append(update.apply, func() {
+			if authentication.AuthenticationType != "" {
+				update.provider.Authentication.AuthenticationType = authentication.AuthenticationType
+			}
+			if authentication.Username != "" {
+				update.provider.Authentication.Username = authentication.Username
+			}
+		})
+	}
+	return update
+}
+
+// SearchSettings sets the settings used to search a generic LDAP service.
+// Fields that are empty are left unchanged, so a single attribute can be
+// changed without repeating the others. Fields that equal the current
+// settings are not sent.
+func (update *ExternalAccountProviderUpdate) SearchSettings(settings LDAPSearchSettings) *ExternalAccountProviderUpdate {
+	current := &update.provider.LDAPService.SearchSettings
+	var apply []func()
+	if settings.BaseDistinguishedNames != nil && !reflect.DeepEqual(current.BaseDistinguishedNames, settings.BaseDistinguishedNames) {
+		update.searchSettings["BaseDistinguishedNames"] = settings.BaseDistinguishedNames
+		apply = append(apply, func() { current.BaseDistinguishedNames = settings.BaseDistinguishedNames })
+	}
+
+	attributes := map[string][2]*string{
+		"EmailAttribute":     {&current.EmailAttribute, &settings.EmailAttribute},
+		"GroupNameAttribute": {&current.GroupNameAttribute, &settings.GroupNameAttribute},
+		"GroupsAttribute":    {&current.GroupsAttribute, &settings.GroupsAttribute},
+		"SSHKeyAttribute":    {&current.SSHKeyAttribute, &settings.SSHKeyAttribute},
+		"UsernameAttribute":  {&current.UsernameAttribute, &settings.UsernameAttribute},
+	}
+	for name, values := range attributes {
+		currentValue, value := values[0], *values[1]
+		if value != "" && value != *currentValue {
+			update.searchSettings[name] = value
+			apply = append(apply, func() { *currentValue = value })
+		}
+	}
+
+	update.apply = append(update.apply, apply...)
+	return update
+}
+
+// sameRoleMapping reports whether two role mappings map the same remote user
+// or group. The local role is only compared if it is set in the pattern.
+func sameRoleMapping(mapping, pattern *RoleMapping) bool {
+	return mapping.RemoteGroup == pattern.RemoteGroup &&
+		mapping.RemoteUser == pattern.RemoteUser &&
+		(pattern.LocalRole == "" || mapping.LocalRole == pattern.LocalRole)
+}
+
+func (update *ExternalAccountProviderUpdate) currentRoleMappings() []RoleMapping {
+	if update.roleMappings != nil {
+		return update.roleMappings
+	}
+	return update.provider.RemoteRoleMapping
+}
+
+// AddRoleMapping maps a remote group or user to a local role. A mapping that
+// already exists is not added again.
+func (update *ExternalAccountProviderUpdate) AddRoleMapping(mapping RoleMapping) *ExternalAccountProviderUpdate {
+	mappings := update.currentRoleMappings()
+	for i := range mappings {
+		if sameRoleMapping(&mappings[i], &mapping) {
+			return update
+		}
+	}
+	update.roleMappings = append(append([]RoleMapping{}, mappings...), mapping)
+	return update
+}
+
+// RemoveRoleMapping removes the mappings of a remote group or user. If the
+// local role of the mapping is empty, the mappings to all local roles are
+// removed.
+func (update *ExternalAccountProviderUpdate) RemoveRoleMapping(mapping RoleMapping) *ExternalAccountProviderUpdate {
+	mappings := update.currentRoleMappings()
+	result := make([]RoleMapping, 0, len(mappings))
+	for i := range mappings {
+		if !sameRoleMapping(&mappings[i], &mapping) {
+			result = append(result, mappings[i])
+		}
+	}
+	if len(result) != len(mappings) {
+		update.roleMappings = result
+	}
+	return update
+}
+
+// roleMappingPayload is a role mapping without the properties that are not
+// set, so unchanged mappings are sent back as they were read.
+type roleMappingPayload struct {
+	LocalRole   string
+	MFABypass   *MFABypass `json:",omitempty"`
+	RemoteGroup string     `json:",omitempty"`
+	RemoteUser  string     `json:",omitempty"`
+}
+
+// Changes returns the properties of the provider that the update changes.
+func (update *ExternalAccountProviderUpdate) Changes() map[string]interface{} {
+	changes := make(map[string]interface{}, len(update.changes)+2)
+	for name, value := range update.changes {
+		changes[name] = value
+	}
+
+	if len(update.searchSettings) > 0 {
+		changes["LDAPService"] = map[string]interface{}{"SearchSettings": update.searchSettings}
+	}
+
+	if update.roleMappings != nil {
+		mappings := make([]roleMappingPayload, 0, len(update.roleMappings))
+		for i := range update.roleMappings {
+			mapping := roleMappingPayload{
+				LocalRole:   update.roleMappings[i].LocalRole,
+				RemoteGroup: update.roleMappings[i].RemoteGroup,
+				RemoteUser:  update.roleMappings[i].RemoteUser,
+			}
+			if update.roleMappings[i].MFABypass.BypassTypes != nil {
+				mapping.MFABypass = &update.roleMappings[i].MFABypass
+			}
+			mappings = append(mappings, mapping)
+		}
+		changes["RemoteRoleMapping"] = mappings
+	}
+
+	return changes
+}
+
+// Commit sends the changes to the service. Nothing is sent if the update
+// does not change anything.
+func (update *ExternalAccountProviderUpdate) Commit() error {
+	changes := update.Changes()
+	if len(changes) == 0 {
+		return nil
+	}
+	if update.uri == "" {
+		return errors.New("external account provider has no URI to update")
+	}
+
+	var payload interface{} = changes
+	if update.property != "" {
+		payload = map[string]interface{}{update.property: changes}
+	}
+
+	if err := update.entity.Patch(update.uri, payload); err != nil {
+		return err
+	}
+
+	for _, apply := range update.apply {
+		apply()
+	}
+	if update.roleMappings != nil {
+		update.provider.RemoteRoleMapping = update.roleMappings
+	}
+	return nil
+}
+
+// DirectoryLogin is the result of logging in with an account of an external
+// account provider.
+type DirectoryLogin struct {
+	// UserName is the user name of the session.
+	UserName string
+	// Session is the session that was created for the user.
+	Session string
+	// Roles are the roles the service mapped the user to.
+	Roles []string
+}
+
+// VerifyDirectoryLogin creates a session with the credentials of a directory
+// user, reports the roles the service mapped the user to and deletes the
+// session again. The roles are read from the session, which requires the
+// client to be allowed to read other sessions, such as an administrator
+// session.
+func VerifyDirectoryLogin(c common.Client, sessionsURI, username, password string) (*DirectoryLogin, error) {
+	auth, err := CreateSession(c, sessionsURI, username, password)
+	if err != nil {
+		return nil, fmt.Errorf("login as %s failed: %w", username, err)
+	}
+	if auth.Session == "" {
+		return nil, errors.New("service did not return the created session")
+	}
+	defer func() {
+		_ = DeleteSession(c, auth.Session)
+	}()
+
+	session, err := GetSession(c, auth.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DirectoryLogin{
+		UserName: session.UserName,
+		Session:  auth.Session,
+		Roles:    session.Roles,
+	}
+	if result.UserName == "" {
+		result.UserName = username
+	}
+	return result, nil
+}
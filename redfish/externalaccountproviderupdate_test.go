@@ -0,0 +1,201 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stmcginnis/gofish"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+var directoryAccountServiceBody = `{
+	"@odata.id": "/redfish/v1/AccountService",
+	"Id": "AccountService",
+	"LDAP": {
+		"AccountProviderType": "LDAPService",
+		"ServiceEnabled": false,
+		"ServiceAddresses": ["ldap://old.example.com"],
+		"Authentication": {"AuthenticationType": "UsernameAndPassword", "Username": "cn=bind", "Password": null},
+		"LDAPService": {
+			"SearchSettings": {
+				"BaseDistinguishedNames": ["dc=example,dc=com"],
+				"UsernameAttribute": "uid",
+				"GroupsAttribute": "memberof"
+			}
+		},
+		"RemoteRoleMapping": [
+			{"RemoteGroup": "admins", "LocalRole": "Administrator"},
+			{"RemoteGroup": "legacy", "LocalRole": "ReadOnly"}
+		]
+	}
+}`
+
+// directoryServer is a mock Redfish service with an LDAP account provider.
+type directoryServer struct {
+	mu       sync.Mutex
+	patches  []string
+	sessions int
+}
+
+func (server *directoryServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	switch r.Method + " " + r.URL.Path {
+	case "GET /redfish/v1/":
+		_, _ = w.Write([]byte(`{
+			"@odata.id": "/redfish/v1/",
+			"AccountService": {"@odata.id": "/redfish/v1/AccountService"},
+			"Links": {"Sessions": {"@odata.id": "/redfish/v1/SessionService/Sessions"}}
+		}`))
+	case "GET /redfish/v1/AccountService":
+		_, _ = w.Write([]byte(directoryAccountServiceBody))
+	case "PATCH /redfish/v1/AccountService":
+		body, _ := io.ReadAll(r.Body)
+		server.patches = append(server.patches, string(body))
+		w.WriteHeader(http.StatusNoContent)
+	case "POST /redfish/v1/SessionService/Sessions":
+		var login struct{ UserName, Password string }
+		_ = json.NewDecoder(r.Body).Decode(&login)
+		if login.UserName != "jdoe" || login.Password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		server.sessions++
+		w.Header().Set("Location", "/redfish/v1/SessionService/Sessions/1")
+		w.Header().Set("X-Auth-Token", "token")
+		w.WriteHeader(http.StatusCreated)
+	case "GET /redfish/v1/SessionService/Sessions/1":
+		_, _ = w.Write([]byte(`{"@odata.id": "/redfish/v1/SessionService/Sessions/1", "Id": "1", "UserName": "jdoe", "Roles": ["Operator"]}`))
+	case "DELETE /redfish/v1/SessionService/Sessions/1":
+		server.sessions--
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// recorded returns the bodies of the PATCH requests and the number of open
+// sessions.
+func (server *directoryServer) recorded() (patches []string, sessions int) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	return append([]string{}, server.patches...), server.sessions
+}
+
+func testDirectoryService(t *testing.T) (*gofish.APIClient, *directoryServer) {
+	server := &directoryServer{}
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+
+	c, err := gofish.Connect(gofish.ClientConfig{Endpoint: ts.URL, HTTPClient: ts.Client(), Username: "admin", Password: "admin", BasicAuth: true})
+	if err != nil {
+		t.Fatalf("Error connecting: %s", err)
+	}
+	return c, server
+}
+
+func testAccountService(t *testing.T, c *gofish.APIClient) *redfish.AccountService {
+	accountService, err := c.Service.AccountService()
+	if err != nil {
+		t.Fatalf("Error getting account service: %s", err)
+	}
+	return accountService
+}
+
+// TestConfigureLDAP tests configuring an LDAP provider.
+func TestConfigureLDAP(t *testing.T) {
+	c, server := testDirectoryService(t)
+	accountService := testAccountService(t, c)
+
+	update := accountService.ConfigureLDAP().
+		ServiceEnabled(true).
+		ServiceAddresses("ldap://old.example.com").
+		Authentication(redfish.Authentication{Username: "cn=bind", Password: "bindpw"}).
+		SearchSettings(redfish.LDAPSearchSettings{GroupsAttribute: "memberOf"}).
+		AddRoleMapping(redfish.RoleMapping{RemoteGroup: "operators", LocalRole: "Operator"}).
+		AddRoleMapping(redfish.RoleMapping{RemoteGroup: "admins", LocalRole: "Administrator"}).
+		RemoveRoleMapping(redfish.RoleMapping{RemoteGroup: "legacy"})
+	if err := update.Commit(); err != nil {
+		t.Fatalf("Error committing LDAP configuration: %s", err)
+	}
+
+	patches, _ := server.recorded()
+	if len(patches) != 1 {
+		t.Fatalf("Expected one PATCH, got %v", patches)
+	}
+
+	expected := `{"LDAP":{"Authentication":{"Password":"bindpw"},` +
+		`"LDAPService":{"SearchSettings":{"GroupsAttribute":"memberOf"}},` +
+		`"RemoteRoleMapping":[{"LocalRole":"Administrator","RemoteGroup":"admins"},{"LocalRole":"Operator","RemoteGroup":"operators"}],` +
+		`"ServiceEnabled":true}}`
+	if patches[0] != expected {
+		t.Errorf("Unexpected PATCH payload:\n%s\nexpected:\n%s", patches[0], expected)
+	}
+
+	settings := accountService.LDAP.LDAPService.SearchSettings
+	if settings.GroupsAttribute != "memberOf" || settings.UsernameAttribute != "uid" || len(settings.BaseDistinguishedNames) != 1 {
+		t.Errorf("Search settings that were not set should be kept: %+v", settings)
+	}
+
+	if !accountService.LDAP.ServiceEnabled || len(accountService.LDAP.RemoteRoleMapping) != 2 {
+		t.Errorf("Local configuration was not updated: %+v", accountService.LDAP)
+	}
+
+	if err := accountService.ConfigureLDAP().ServiceEnabled(true).SearchSettings(settings).Commit(); err != nil {
+		t.Errorf("Error committing unchanged LDAP configuration: %s", err)
+	}
+	if patches, _ := server.recorded(); len(patches) != 1 {
+		t.Errorf("Expected unchanged configuration not to be sent: %v", patches)
+	}
+}
+
+// TestConfigureLDAPRotatePassword tests that rotating the bind password
+// leaves the bind username unchanged.
+func TestConfigureLDAPRotatePassword(t *testing.T) {
+	c, server := testDirectoryService(t)
+	accountService := testAccountService(t, c)
+
+	if err := accountService.ConfigureLDAP().Authentication(redfish.Authentication{Password: "rotated"}).Commit(); err != nil {
+		t.Fatalf("Error committing LDAP configuration: %s", err)
+	}
+
+	patches, _ := server.recorded()
+	if len(patches) != 1 || patches[0] != `{"LDAP":{"Authentication":{"Password":"rotated"}}}` {
+		t.Errorf("Unexpected PATCH requests: %v", patches)
+	}
+
+	if accountService.LDAP.Authentication.Username != "cn=bind" {
+		t.Errorf("Expected the bind username to be kept, got %q", accountService.LDAP.Authentication.Username)
+	}
+}
+
+// TestVerifyDirectoryLogin tests verifying a directory login.
+func TestVerifyDirectoryLogin(t *testing.T) {
+	c, server := testDirectoryService(t)
+
+	login, err := c.Service.VerifyDirectoryLogin("jdoe", "secret")
+	if err != nil {
+		t.Fatalf("Error verifying directory login: %s", err)
+	}
+
+	if login.UserName != "jdoe" || len(login.Roles) != 1 || login.Roles[0] != "Operator" {
+		t.Errorf("Unexpected login result: %+v", login)
+	}
+
+	if _, sessions := server.recorded(); sessions != 0 {
+		t.Errorf("Expected the verification session to be deleted, %d remain", sessions)
+	}
+
+	if _, err := c.Service.VerifyDirectoryLogin("jdoe", "wrong"); err == nil {
+		t.Error("Expected login with wrong password to fail")
+	}
+}
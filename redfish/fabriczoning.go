@@ -0,0 +1,398 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"errors"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+// endpointRefs creates references to a set of endpoints.
+func endpointRefs(endpoints []*Endpoint) []common.IDRef {
+	result := make([]common.IDRef, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		result = append(result, common.IDRef{ODataID: endpoint.ODataID})
+	}
+	return result
+}
+
+// endpointGroupRefs creates references to a set of endpoint groups.
+func endpointGroupRefs(groups []*EndpointGroup) []common.IDRef {
+	result := make([]common.IDRef, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, common.IDRef{ODataID: group.ODataID})
+	}
+	return result
+}
+
+// ZoneBuilder builds the request to create a Zone.
+type ZoneBuilder struct {
+	request struct {
+		Name                  string                `json:",omitempty"`
+		Description           string                `json:",omitempty"`
+		ZoneType              ZoneType              `json:",omitempty"`
+		ExternalAccessibility ExternalAccessibility `json:",omitempty"`
+		DefaultRoutingEnabled *bool                 `json:",omitempty"`
+		Links                 struct {
+			AddressPools  []common.IDRef `json:",omitempty"`
+			ContainsZones []common.IDRef `json:",omitempty"`
+			Endpoints     []common.IDRef `json:",omitempty"`
+		}
+	}
+}
+
+// NewZoneBuilder starts building a zone of the given type.
+func NewZoneBuilder(name string, zoneType ZoneType) *ZoneBuilder {
+	builder := &ZoneBuilder{}
+	builder.request.Name = name
+	builder.request.ZoneType = zoneType
+	return builder
+}
+
+// Description sets the description of the zone.
+func (builder *ZoneBuilder) Description(description string) *ZoneBuilder {
+	builder.request.Description = description
+	return builder
+}
+
+// ExternalAccessibility sets how endpoints outside of the zone can access the
+// endpoints in the zone.
+func (builder *ZoneBuilder) ExternalAccessibility(accessibility ExternalAccessibility) *ZoneBuilder {
+	builder.request.ExternalAccessibility = accessibility
+	return builder
+}
+
+// DefaultRoutingEnabled sets whether routing within the zone is enabled.
+func (builder *ZoneBuilder) DefaultRoutingEnabled(enabled bool) *ZoneBuilder {
+	builder.request.DefaultRoutingEnabled = &enabled
+	return builder
+}
+
+// Endpoints adds endpoints to a zone of endpoints.
+func (builder *ZoneBuilder) Endpoints(endpoints ...*Endpoint) *ZoneBuilder {
+	builder.request.Links.Endpoints = append(builder.request.Links.Endpoints, endpointRefs(endpoints)...)
+	return builder
+}
+
+// ContainsZones adds zones to a zone of zones.
+func (builder *ZoneBuilder) ContainsZones(zones ...*Zone) *ZoneBuilder {
+	for _, zone := range zones {
+		builder.request.Links.ContainsZones = append(builder.request.Links.ContainsZones, common.IDRef{ODataID: zone.ODataID})
+	}
+	return builder
+}
+
+// AddressPools associates address pools with the zone.
+func (builder *ZoneBuilder) AddressPools(addressPools ...*AddressPool) *ZoneBuilder {
+	for _, addressPool := range addressPools {
+		builder.request.Links.AddressPools = append(builder.request.Links.AddressPools, common.IDRef{ODataID: addressPool.ODataID})
+	}
+	return builder
+}
+
+func (builder *ZoneBuilder) validate() error {
+	links := &builder.request.Links
+	switch builder.request.ZoneType {
+	case ZoneOfEndpointsZoneType:
+		if len(links.ContainsZones) > 0 {
+			return errors.New("a zone of endpoints can only contain endpoints")
+		}
+	case ZoneOfZonesZoneType:
+		if len(links.Endpoints) > 0 {
+			return errors.New("a zone of zones can only contain zones")
+		}
+	case ZoneOfResourceBlocksZoneType:
+		return errors.New("zones of resource blocks are created through the composition service")
+	}
+	return nil
+}
+
+// EndpointGroupBuilder builds the request to create an EndpointGroup.
+type EndpointGroupBuilder struct {
+	request struct {
+		Name                          string             `json:",omitempty"`
+		Description                   string             `json:",omitempty"`
+		GroupType                     GroupType          `json:",omitempty"`
+		Identifier                    *common.Identifier `json:",omitempty"`
+		TargetEndpointGroupIdentifier *int               `json:",omitempty"`
+		Links                         struct {
+			Endpoints []common.IDRef `json:",omitempty"`
+		}
+	}
+}
+
+// NewEndpointGroupBuilder starts building an endpoint group of the given type.
+func NewEndpointGroupBuilder(name string, groupType GroupType) *EndpointGroupBuilder {
+	builder := &EndpointGroupBuilder{}
+	builder.request.Name = name
+	builder.request.GroupType = groupType
+	return builder
+}
+
+// Description sets the description of the endpoint group.
+func (builder *EndpointGroupBuilder) Description(description string) *EndpointGroupBuilder {
+	builder.request.Description = description
+	return builder
+}
+
+// Identifier sets the durable name of the endpoint group.
+func (builder *EndpointGroupBuilder) Identifier(identifier common.Identifier) *EndpointGroupBuilder {
+	builder.request.Identifier = &identifier
+	return builder
+}
+
+// TargetEndpointGroupIdentifier sets the SCSI target port group of the
+// endpoint group.
+func (builder *EndpointGroupBuilder) TargetEndpointGroupIdentifier(identifier int) *EndpointGroupBuilder {
+	builder.request.TargetEndpointGroupIdentifier = &identifier
+	return builder
+}
+
+// Endpoints adds endpoints to the endpoint group.
+func (builder *EndpointGroupBuilder) Endpoints(endpoints ...*Endpoint) *EndpointGroupBuilder {
+	builder.request.Links.Endpoints = append(builder.request.Links.Endpoints, endpointRefs(endpoints)...)
+	return builder
+}
+
+// volumeInfoRequest is the request representation of VolumeInfo.
+type volumeInfoRequest struct {
+	AccessCapabilities []AccessCapability `json:",omitempty"`
+	LUN                *int               `json:",omitempty"`
+	Volume             common.IDRef
+}
+
+// ConnectionBuilder builds the request to create a Connection.
+type ConnectionBuilder struct {
+	request struct {
+		Name           string              `json:",omitempty"`
+		Description    string              `json:",omitempty"`
+		ConnectionType ConnectionType      `json:",omitempty"`
+		ConnectionKeys *ConnectionKey      `json:",omitempty"`
+		VolumeInfo     []volumeInfoRequest `json:",omitempty"`
+		Links          struct {
+			InitiatorEndpointGroups []common.IDRef `json:",omitempty"`
+			InitiatorEndpoints      []common.IDRef `json:",omitempty"`
+			TargetEndpointGroups    []common.IDRef `json:",omitempty"`
+			TargetEndpoints         []common.IDRef `json:",omitempty"`
+		}
+	}
+}
+
+// NewConnectionBuilder starts building a connection of the given type.
+func NewConnectionBuilder(name string, connectionType ConnectionType) *ConnectionBuilder {
+	builder := &ConnectionBuilder{}
+	builder.request.Name = name
+	builder.request.ConnectionType = connectionType
+	return builder
+}
+
+// Description sets the description of the connection.
+func (builder *ConnectionBuilder) Description(description string) *ConnectionBuilder {
+	builder.request.Description = description
+	return builder
+}
+
+// ConnectionKeys sets the permission keys required to use the connection.
+func (builder *ConnectionBuilder) ConnectionKeys(keys *ConnectionKey) *ConnectionBuilder {
+	builder.request.ConnectionKeys = keys
+	return builder
+}
+
+// Volume grants the initiators access to a volume. If lun is negative, the
+// service assigns the logical unit number.
+func (builder *ConnectionBuilder) Volume(volumeURI string, lun int, capabilities ...AccessCapability) *ConnectionBuilder {
+	info := volumeInfoRequest{
+		AccessCapabilities: capabilities,
+		Volume:             common.IDRef{ODataID: volumeURI},
+	}
+	if lun >= 0 {
+		info.LUN = &lun
+	}
+	builder.request.VolumeInfo = append(builder.request.VolumeInfo, info)
+	return builder
+}
+
+// InitiatorEndpoints adds initiator endpoints to the connection.
+func (builder *ConnectionBuilder) InitiatorEndpoints(endpoints ...*Endpoint) *ConnectionBuilder {
+	builder.request.Links.InitiatorEndpoints = append(builder.request.Links.InitiatorEndpoints, endpointRefs(endpoints)...)
+	return builder
+}
+
+// InitiatorEndpointGroups adds initiator endpoint groups to the connection.
+func (builder *ConnectionBuilder) InitiatorEndpointGroups(groups ...*EndpointGroup) *ConnectionBuilder {
+	builder.request.Links.InitiatorEndpointGroups = append(builder.request.Links.InitiatorEndpointGroups, endpointGroupRefs(groups)...)
+	return builder
+}
+
+// TargetEndpoints adds target endpoints to the connection.
+func (builder *ConnectionBuilder) TargetEndpoints(endpoints ...*Endpoint) *ConnectionBuilder {
+	builder.request.Links.TargetEndpoints = append(builder.request.Links.TargetEndpoints, endpointRefs(endpoints)...)
+	return builder
+}
+
+// TargetEndpointGroups adds target endpoint groups to the connection.
+func (builder *ConnectionBuilder) TargetEndpointGroups(groups ...*EndpointGroup) *ConnectionBuilder {
+	builder.request.Links.TargetEndpointGroups = append(builder.request.Links.TargetEndpointGroups, endpointGroupRefs(groups)...)
+	return builder
+}
+
+func (builder *ConnectionBuilder) validate() error {
+	links := &builder.request.Links
+	if len(links.InitiatorEndpoints) > 0 && len(links.InitiatorEndpointGroups) > 0 {
+		return errors.New("a connection cannot reference both initiator endpoints and initiator endpoint groups")
+	}
+	if len(links.TargetEndpoints) > 0 && len(links.TargetEndpointGroups) > 0 {
+		return errors.New("a connection cannot reference both target endpoints and target endpoint groups")
+	}
+	if len(builder.request.VolumeInfo) > 0 && len(links.InitiatorEndpoints) == 0 && len(links.InitiatorEndpointGroups) == 0 {
+		return errors.New("a connection granting volume access requires initiators")
+	}
+	return nil
+}
+
+// AddressPoolBuilder builds the request to create an AddressPool.
+type AddressPoolBuilder struct {
+	request struct {
+		Name        string      `json:",omitempty"`
+		Description string      `json:",omitempty"`
+		Ethernet    *APEthernet `json:",omitempty"`
+		GenZ        *APGenZ     `json:",omitempty"`
+	}
+}
+
+// NewAddressPoolBuilder starts building an address pool.
+func NewAddressPoolBuilder(name string) *AddressPoolBuilder {
+	builder := &AddressPoolBuilder{}
+	builder.request.Name = name
+	return builder
+}
+
+// Description sets the description of the address pool.
+func (builder *AddressPoolBuilder) Description(description string) *AddressPoolBuilder {
+	builder.request.Description = description
+	return builder
+}
+
+// Ethernet sets the Ethernet properties of the address pool. The complete
+// object is sent to the service.
+func (builder *AddressPoolBuilder) Ethernet(ethernet *APEthernet) *AddressPoolBuilder {
+	builder.request.Ethernet = ethernet
+	return builder
+}
+
+// GenZ sets the Gen-Z properties of the address pool.
+func (builder *AddressPoolBuilder) GenZ(genZ *APGenZ) *AddressPoolBuilder {
+	builder.request.GenZ = genZ
+	return builder
+}
+
+// Zones gets any zones associated with this fabric.
+func (fabric *Fabric) Zones() ([]*Zone, error) {
+	return ListReferencedZones(fabric.GetClient(), fabric.zones)
+}
+
+// CreateZone creates a zone in this fabric. If the service creates the zone
+// asynchronously, the zone is nil and the task tracking the operation is
+// returned instead.
+func (fabric *Fabric) CreateZone(builder *ZoneBuilder) (*Zone, *Task, error) {
+	if fabric.zones == "" {
+		return nil, nil, errors.New("zones are not supported by this fabric")
+	}
+	if err := builder.validate(); err != nil {
+		return nil, nil, err
+	}
+
+	if fabric.MaxZones > 0 {
+		zones, err := common.GetCollection(fabric.GetClient(), fabric.zones)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(zones.ItemLinks) >= fabric.MaxZones {
+			return nil, nil, errors.New("the fabric has reached its maximum number of zones")
+		}
+	}
+
+	var zone Zone
+	task, err := CreateResource(fabric.GetClient(), fabric.zones, &builder.request, &zone)
+	if err != nil || task != nil {
+		return nil, task, err
+	}
+	zone.SetClient(fabric.GetClient())
+	return &zone, nil, nil
+}
+
+// DeleteZone deletes a zone from this fabric. The request is conditional on
+// the etag of the zone, so it fails if the zone was modified since it was
+// fetched.
+func (fabric *Fabric) DeleteZone(zone *Zone) (*Task, error) {
+	return DeleteEntity(fabric.GetClient(), &zone.Entity)
+}
+
+// CreateEndpointGroup creates an endpoint group in this fabric.
+func (fabric *Fabric) CreateEndpointGroup(builder *EndpointGroupBuilder) (*EndpointGroup, *Task, error) {
+	if fabric.endpointGroups == "" {
+		return nil, nil, errors.New("endpoint groups are not supported by this fabric")
+	}
+
+	var endpointGroup EndpointGroup
+	task, err := CreateResource(fabric.GetClient(), fabric.endpointGroups, &builder.request, &endpointGroup)
+	if err != nil || task != nil {
+		return nil, task, err
+	}
+	endpointGroup.SetClient(fabric.GetClient())
+	return &endpointGroup, nil, nil
+}
+
+// DeleteEndpointGroup deletes an endpoint group from this fabric, conditional
+// on the etag of the endpoint group.
+func (fabric *Fabric) DeleteEndpointGroup(endpointGroup *EndpointGroup) (*Task, error) {
+	return DeleteEntity(fabric.GetClient(), &endpointGroup.Entity)
+}
+
+// CreateConnection creates a connection in this fabric.
+func (fabric *Fabric) CreateConnection(builder *ConnectionBuilder) (*Connection, *Task, error) {
+	if fabric.connections == "" {
+		return nil, nil, errors.New("connections are not supported by this fabric")
+	}
+	if err := builder.validate(); err != nil {
+		return nil, nil, err
+	}
+
+	var connection Connection
+	task, err := CreateResource(fabric.GetClient(), fabric.connections, &builder.request, &connection)
+	if err != nil || task != nil {
+		return nil, task, err
+	}
+	connection.SetClient(fabric.GetClient())
+	return &connection, nil, nil
+}
+
+// DeleteConnection deletes a connection from this fabric, conditional on the
+// etag of the connection.
+func (fabric *Fabric) DeleteConnection(connection *Connection) (*Task, error) {
+	return DeleteEntity(fabric.GetClient(), &connection.Entity)
+}
+
+// CreateAddressPool creates an address pool in this fabric.
+func (fabric *Fabric) CreateAddressPool(builder *AddressPoolBuilder) (*AddressPool, *Task, error) {
+	if fabric.addressPools == "" {
+		return nil, nil, errors.New("address pools are not supported by this fabric")
+	}
+
+	var addressPool AddressPool
+	task, err := CreateResource(fabric.GetClient(), fabric.addressPools, &builder.request, &addressPool)
+	if err != nil || task != nil {
+		return nil, task, err
+	}
+	addressPool.SetClient(fabric.GetClient())
+	return &addressPool, nil, nil
+}
+
+// DeleteAddressPool deletes an address pool from this fabric, conditional on
+// the etag of the address pool.
+func (fabric *Fabric) DeleteAddressPool(addressPool *AddressPool) (*Task, error) {
+	return DeleteEntity(fabric.GetClient(), &addressPool.Entity)
+}
@@ -0,0 +1,199 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+var zoningFabricBody = `{
+		"@odata.id": "/redfish/v1/Fabrics/NVMeoF",
+		"Id": "NVMeoF",
+		"Name": "NVMe-oF Fabric",
+		"FabricType": "NVMeOverFabrics",
+		"MaxZones": 2,
+		"Zones": {"@odata.id": "/redfish/v1/Fabrics/NVMeoF/Zones"},
+		"Endpoints": {"@odata.id": "/redfish/v1/Fabrics/NVMeoF/Endpoints"},
+		"EndpointGroups": {"@odata.id": "/redfish/v1/Fabrics/NVMeoF/EndpointGroups"},
+		"Connections": {"@odata.id": "/redfish/v1/Fabrics/NVMeoF/Connections"}
+	}`
+
+func zoningFabric(t *testing.T, testClient *common.TestClient) *Fabric {
+	var result Fabric
+	if err := json.NewDecoder(strings.NewReader(zoningFabricBody)).Decode(&result); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	result.SetClient(testClient)
+	return &result
+}
+
+func zoningEndpoint(uri string) *Endpoint {
+	endpoint := &Endpoint{}
+	endpoint.ODataID = uri
+	return endpoint
+}
+
+// TestFabricCreateZone tests creating a zone of endpoints.
+func TestFabricCreateZone(t *testing.T) {
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {
+				getCall(`{"Members": [{"@odata.id": "/redfish/v1/Fabrics/NVMeoF/Zones/1"}], "Members@odata.count": 1}`),
+			},
+			http.MethodPost: {
+				getCall(`{"@odata.id": "/redfish/v1/Fabrics/NVMeoF/Zones/2", "Id": "2", "ZoneType": "ZoneOfEndpoints"}`),
+			},
+		},
+	}
+	fabric := zoningFabric(t, testClient)
+
+	zone, task, err := fabric.CreateZone(
+		NewZoneBuilder("hosts", ZoneOfEndpointsZoneType).
+			Endpoints(zoningEndpoint("/redfish/v1/Fabrics/NVMeoF/Endpoints/Initiator1")).
+			DefaultRoutingEnabled(false))
+	if err != nil {
+		t.Fatalf("Error making CreateZone call: %s", err)
+	}
+
+	if task != nil {
+		t.Errorf("Unexpected task returned: %v", task)
+	}
+
+	if zone.ID != "2" || zone.ZoneType != ZoneOfEndpointsZoneType {
+		t.Errorf("Unexpected zone: %v", zone)
+	}
+
+	calls := testClient.CapturedCalls()
+	post := calls[len(calls)-1]
+	if post.URL != "/redfish/v1/Fabrics/NVMeoF/Zones" {
+		t.Errorf("Unexpected CreateZone URL: %s", post.URL)
+	}
+
+	if !strings.Contains(post.Payload, "Endpoints:[map[@odata.id:/redfish/v1/Fabrics/NVMeoF/Endpoints/Initiator1]]") {
+		t.Errorf("Unexpected endpoints in payload: %s", post.Payload)
+	}
+
+	if !strings.Contains(post.Payload, "DefaultRoutingEnabled:false") {
+		t.Errorf("Unexpected routing in payload: %s", post.Payload)
+	}
+}
+
+// TestFabricCreateZoneMaxZones tests that zones are not created past MaxZones.
+func TestFabricCreateZoneMaxZones(t *testing.T) {
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {
+				getCall(`{"Members": [{"@odata.id": "/redfish/v1/Fabrics/NVMeoF/Zones/1"}, {"@odata.id": "/redfish/v1/Fabrics/NVMeoF/Zones/2"}]}`),
+			},
+		},
+	}
+	fabric := zoningFabric(t, testClient)
+
+	_, _, err := fabric.CreateZone(NewZoneBuilder("full", ZoneOfEndpointsZoneType))
+	if err == nil {
+		t.Error("Expected zone creation to be rejected")
+	}
+}
+
+// TestFabricCreateConnection tests granting initiators access to a volume.
+func TestFabricCreateConnection(t *testing.T) {
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodPost: {
+				getCall(`{"@odata.id": "/redfish/v1/Fabrics/NVMeoF/Connections/1", "Id": "1", "ConnectionType": "Storage"}`),
+			},
+		},
+	}
+	fabric := zoningFabric(t, testClient)
+
+	group := &EndpointGroup{}
+	group.ODataID = "/redfish/v1/Fabrics/NVMeoF/EndpointGroups/Hosts"
+
+	_, _, err := fabric.CreateConnection(
+		NewConnectionBuilder("invalid", StorageConnectionType).
+			Volume("/redfish/v1/Storage/1/Volumes/1", 1, ReadAccessCapability))
+	if err == nil {
+		t.Error("Expected connection without initiators to be rejected")
+	}
+
+	connection, _, err := fabric.CreateConnection(
+		NewConnectionBuilder("hosts", StorageConnectionType).
+			Volume("/redfish/v1/Storage/1/Volumes/1", -1, ReadAccessCapability, WriteAccessCapability).
+			InitiatorEndpointGroups(group).
+			TargetEndpoints(zoningEndpoint("/redfish/v1/Fabrics/NVMeoF/Endpoints/Target1")))
+	if err != nil {
+		t.Fatalf("Error making CreateConnection call: %s", err)
+	}
+
+	if connection.ConnectionType != StorageConnectionType {
+		t.Errorf("Unexpected connection type: %s", connection.ConnectionType)
+	}
+
+	calls := testClient.CapturedCalls()
+	if len(calls) != 1 {
+		t.Fatalf("Expected one call, got: %v", calls)
+	}
+
+	if !strings.Contains(calls[0].Payload, "VolumeInfo:[map[AccessCapabilities:[Read Write] Volume:map[@odata.id:/redfish/v1/Storage/1/Volumes/1]]]") {
+		t.Errorf("Unexpected volume info in payload: %s", calls[0].Payload)
+	}
+
+	if !strings.Contains(calls[0].Payload, "InitiatorEndpointGroups:[map[@odata.id:/redfish/v1/Fabrics/NVMeoF/EndpointGroups/Hosts]]") {
+		t.Errorf("Unexpected initiators in payload: %s", calls[0].Payload)
+	}
+}
+
+// TestFabricDeleteZone tests that deleting a zone is conditional on its etag,
+// unless the etag match is disabled on the zone.
+func TestFabricDeleteZone(t *testing.T) {
+	zoneResponse := func() *http.Response {
+		resp := getCall(`{
+			"@odata.id": "/redfish/v1/Fabrics/NVMeoF/Zones/1",
+			"Id": "1"
+		}`)
+		resp.Header.Set("ETag", `W/"12345"`)
+		return resp
+	}
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {zoneResponse(), zoneResponse()},
+		},
+	}
+	fabric := zoningFabric(t, testClient)
+
+	zone, err := GetZone(testClient, "/redfish/v1/Fabrics/NVMeoF/Zones/1")
+	if err != nil {
+		t.Fatalf("Error making GetZone call: %s", err)
+	}
+	if _, err := fabric.DeleteZone(zone); err != nil {
+		t.Fatalf("Error making DeleteZone call: %s", err)
+	}
+
+	zone, err = GetZone(testClient, "/redfish/v1/Fabrics/NVMeoF/Zones/1")
+	if err != nil {
+		t.Fatalf("Error making GetZone call: %s", err)
+	}
+	zone.DisableEtagMatch(true)
+	if _, err := fabric.DeleteZone(zone); err != nil {
+		t.Fatalf("Error making DeleteZone call: %s", err)
+	}
+
+	calls := testClient.CapturedCalls()
+	if len(calls) != 4 || calls[1].Action != http.MethodDelete || calls[3].Action != http.MethodDelete {
+		t.Fatalf("Expected two delete calls, got: %v", calls)
+	}
+
+	if calls[1].CustomHeaders["If-Match"] != `W/"12345"` {
+		t.Errorf("Unexpected If-Match header: %v", calls[1].CustomHeaders)
+	}
+	if _, ok := calls[3].CustomHeaders["If-Match"]; ok {
+		t.Errorf("Expected no If-Match header when disabled: %v", calls[3].CustomHeaders)
+	}
+}
@@ -122,15 +122,30 @@ type LogService struct {
 	// rawData holds the original serialized JSON so we can compare updates.
 	rawData []byte
 
+	// SupportedDiagnosticDataTypes are the types of diagnostic data the
+	// CollectDiagnosticData action can collect.
+	SupportedDiagnosticDataTypes []LogDiagnosticDataTypes
+	// SupportedOEMDiagnosticDataTypes are the OEM types of diagnostic data the
+	// CollectDiagnosticData action can collect.
+	SupportedOEMDiagnosticDataTypes []string
+
 	// clearLogTarget is the URL to send ClearLog actions to.
 	clearLogTarget string
+	// collectDiagnosticDataTarget is the URL to send CollectDiagnosticData
+	// actions to.
+	collectDiagnosticDataTarget string
 }
 
 // UnmarshalJSON unmarshals a LogService object from the raw JSON.
 func (logservice *LogService) UnmarshalJSON(b []byte) error {
 	type temp LogService
 	type Actions struct {
-		ClearLog common.ActionTarget `json:"#LogService.ClearLog"`
+		ClearLog              common.ActionTarget `json:"#LogService.ClearLog"`
+		CollectDiagnosticData struct {
+			DiagnosticDataTypes    []LogDiagnosticDataTypes `json:"DiagnosticDataType@Redfish.AllowableValues"`
+			OEMDiagnosticDataTypes []string                 `json:"OEMDiagnosticDataType@Redfish.AllowableValues"`
+			Target                 string
+		} `json:"#LogService.CollectDiagnosticData"`
 	}
 	var t struct {
 		temp
@@ -147,6 +162,9 @@ func (logservice *LogService) UnmarshalJSON(b []byte) error {
 	*logservice = LogService(t.temp)
 	logservice.entries = t.Entries.String()
 	logservice.clearLogTarget = t.Actions.ClearLog.Target
+	logservice.collectDiagnosticDataTarget = t.Actions.CollectDiagnosticData.Target
+	logservice.SupportedDiagnosticDataTypes = t.Actions.CollectDiagnosticData.DiagnosticDataTypes
+	logservice.SupportedOEMDiagnosticDataTypes = t.Actions.CollectDiagnosticData.OEMDiagnosticDataTypes
 
 	// This is a read/write object, so we need to save the raw object data for later
 	logservice.rawData = b
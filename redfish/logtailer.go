@@ -0,0 +1,532 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+// defaultLogPollInterval is the interval between polls of a log if none is
+// configured.
+const defaultLogPollInterval = 30 * time.Second
+
+// LogResetReason is the reason entries of a log were removed between polls.
+type LogResetReason string
+
+const (
+	// WrappedLogResetReason means old entries were overwritten by new ones.
+	// Entries may have been lost between polls.
+	WrappedLogResetReason LogResetReason = "Wrapped"
+	// ClearedLogResetReason means the log was cleared. All remaining entries
+	// are new.
+	ClearedLogResetReason LogResetReason = "Cleared"
+)
+
+// LogBookmark is the position of a log tailer in a log. It can be persisted,
+// for example as JSON, to resume tailing later.
+type LogBookmark struct {
+	// LogService is the URI of the log service.
+	LogService string
+	// EntryID is the ID of the last seen log entry.
+	EntryID string
+	// EntryURI is the URI of the last seen log entry.
+	EntryURI string
+	// Created is the creation time of the last seen log entry.
+	Created string
+	// SameTimeIDs are the IDs of the seen log entries created at the same
+	// time as the last seen log entry.
+	SameTimeIDs []string
+	// EntryCount is the number of entries in the log at the last poll.
+	EntryCount int
+	// NewestFirst is whether the service lists the newest entries first.
+	NewestFirst bool
+	// OverWritePolicy is the overwrite policy of the log at the last poll.
+	OverWritePolicy OverWritePolicy
+}
+
+// advance returns the bookmark after the given log entry was seen.
+func (bookmark LogBookmark) advance(entry *LogEntry) LogBookmark {
+	if entry.Created != bookmark.Created || bookmark.EntryID == "" {
+		bookmark.SameTimeIDs = nil
+	}
+	bookmark.SameTimeIDs = append(append([]string(nil), bookmark.SameTimeIDs...), entry.ID)
+	bookmark.EntryID = entry.ID
+	bookmark.EntryURI = entry.ODataID
+	bookmark.Created = entry.Created
+	bookmark.EntryCount++
+	return bookmark
+}
+
+// matches reports whether the log entry is the last seen log entry. Services
+// that reuse IDs after a wrap are detected by the creation time.
+func (bookmark *LogBookmark) matches(entry *LogEntry) bool {
+	return entry.ID == bookmark.EntryID && entry.Created == bookmark.Created
+}
+
+// isNewer reports whether the log entry was created after the last seen log
+// entry. Entries without a readable creation time are considered newer.
+func (bookmark *LogBookmark) isNewer(entry *LogEntry) bool {
+	created, err := time.Parse(time.RFC3339, entry.Created)
+	last, lastErr := time.Parse(time.RFC3339, bookmark.Created)
+	if err != nil || lastErr != nil {
+		return true
+	}
+	if !created.Equal(last) {
+		return created.After(last)
+	}
+	for _, id := range bookmark.SameTimeIDs {
+		if id == entry.ID {
+			return false
+		}
+	}
+	return true
+}
+
+// LogTailEvent is an event of a tailed log. Either Entry, Reset or Err is set.
+type LogTailEvent struct {
+	// Entry is a new log entry.
+	Entry *LogEntry
+	// Reset is set if entries were removed from the log since the last poll.
+	Reset LogResetReason
+	// Err is set if the log could not be polled. Tailing continues at the
+	// next poll.
+	Err error
+	// Bookmark is the position in the log after this event.
+	Bookmark LogBookmark
+}
+
+// LogTailer reads the entries added to a log since the last poll, without
+// downloading the whole log each time. New entries are read with a $filter
+// on their creation time if FilterQuery is set, with $skip if the service
+// lists the oldest entries first, and otherwise by scanning the pages of the
+// log until the last seen entry is found.
+type LogTailer struct {
+	// FilterQuery enables reading new entries with $filter. It should only
+	// be set if the service supports $filter, as reported by
+	// ProtocolFeaturesSupported.
+	FilterQuery bool
+	// PollInterval is the interval between polls when tailing. It defaults to
+	// 30 seconds.
+	PollInterval time.Duration
+
+	logService *LogService
+	// skipUnsupported is set once the service was found to ignore $skip.
+	skipUnsupported bool
+
+	mu       sync.Mutex
+	bookmark LogBookmark
+}
+
+// NewLogTailer creates a tailer of the log service. If a bookmark is given,
+// tailing resumes after its last seen entry; otherwise the first poll returns
+// all entries of the log. To only receive entries created from now on,
+// discard the result of the first poll.
+func NewLogTailer(logservice *LogService, bookmark *LogBookmark) (*LogTailer, error) {
+	tailer := &LogTailer{logService: logservice}
+	if bookmark != nil {
+		if bookmark.LogService != "" && bookmark.LogService != logservice.ODataID {
+			return nil, fmt.Errorf("bookmark of log %s cannot be used for log %s", bookmark.LogService, logservice.ODataID)
+		}
+		tailer.bookmark = *bookmark
+	}
+	tailer.bookmark.LogService = logservice.ODataID
+	if logservice.OverWritePolicy != "" {
+		tailer.bookmark.OverWritePolicy = logservice.OverWritePolicy
+	}
+	return tailer, nil
+}
+
+// Bookmark returns the position of the tailer in the log.
+func (tailer *LogTailer) Bookmark() LogBookmark {
+	tailer.mu.Lock()
+	defer tailer.mu.Unlock()
+	return tailer.bookmark
+}
+
+// logEntryPage is a page of a log entry collection.
+type logEntryPage struct {
+	Members  []json.RawMessage
+	Count    int    `json:"Members@odata.count"`
+	NextLink string `json:"Members@odata.nextLink"`
+
+	entries []*LogEntry
+}
+
+// getLogEntryPage gets a page of a log entry collection. Most services
+// include the log entries in the page; members that are only references are
+// read individually.
+func getLogEntryPage(c common.Client, uri string) (*logEntryPage, error) {
+	resp, err := c.Get(uri)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var page logEntryPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, err
+	}
+
+	page.entries = make([]*LogEntry, len(page.Members))
+	collectionError := common.NewCollectionError()
+	var links []string
+	var mu sync.Mutex
+	index := make(map[string]int)
+	for i, member := range page.Members {
+		var entry LogEntry
+		if err := json.Unmarshal(member, &entry); err != nil {
+			return nil, err
+		}
+		if entry.ID == "" {
+			links = append(links, entry.ODataID)
+			index[entry.ODataID] = i
+			continue
+		}
+		entry.SetClient(c)
+		page.entries[i] = &entry
+	}
+
+	common.CollectCollection(func(link string) {
+		entry, err := GetLogEntry(c, link)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			collectionError.Failures[link] = err
+			return
+		}
+		page.entries[index[link]] = entry
+	}, links)
+
+	if !collectionError.Empty() {
+		return nil, collectionError
+	}
+	return &page, nil
+}
+
+// logOrder reports whether the entries are listed newest first. The result is
+// false if the order cannot be told from the creation times.
+func logOrder(entries []*LogEntry) (newestFirst, ok bool) {
+	var first, last time.Time
+	for _, entry := range entries {
+		created, err := time.Parse(time.RFC3339, entry.Created)
+		if err != nil {
+			continue
+		}
+		if first.IsZero() {
+			first = created
+		}
+		last = created
+	}
+	if first.Equal(last) {
+		return false, false
+	}
+	return first.After(last), true
+}
+
+// reverseLogEntries reverses the order of the entries in place.
+func reverseLogEntries(entries []*LogEntry) {
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+}
+
+// logPoll is the result of a poll of a log.
+type logPoll struct {
+	// entries are the new entries, oldest first.
+	entries []*LogEntry
+	// count is the number of entries in the log, or -1 if not known.
+	count int
+	// found is the position of the last seen entry in the log as listed by
+	// the service, or -1 if it was not found or not looked for.
+	found       int
+	newestFirst bool
+	reset       LogResetReason
+}
+
+// pollFiltered reads the entries created since the last seen entry with a
+// $filter. The result is nil if the last seen entry is gone, in which case
+// the log needs to be scanned to tell whether it wrapped or was cleared.
+func (tailer *LogTailer) pollFiltered(bookmark *LogBookmark) (*logPoll, error) {
+	c := tailer.logService.GetClient()
+	if last, err := GetLogEntry(c, bookmark.EntryURI); err != nil || !bookmark.matches(last) {
+		return nil, nil
+	}
+
+	filter := strings.ReplaceAll(url.QueryEscape("Created ge "+bookmark.Created), "+", "%20")
+	var entries []*LogEntry
+	for uri := fmt.Sprintf("%s?$filter=%s", tailer.logService.entries, filter); uri != ""; {
+		page, err := getLogEntryPage(c, uri)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, page.entries...)
+		uri = page.NextLink
+	}
+
+	newestFirst, ok := logOrder(entries)
+	if !ok {
+		newestFirst = bookmark.NewestFirst
+	}
+	if newestFirst {
+		reverseLogEntries(entries)
+	}
+
+	result := &logPoll{count: -1, found: -1, newestFirst: newestFirst}
+	for _, entry := range entries {
+		if bookmark.isNewer(entry) {
+			result.entries = append(result.entries, entry)
+		}
+	}
+	return result, nil
+}
+
+// pollSkip reads the entries after the last seen entry with $skip, for logs
+// listing the oldest entries first. The result is nil if the service did not
+// return the last seen entry at its previous position.
+func (tailer *LogTailer) pollSkip(bookmark *LogBookmark) (*logPoll, error) {
+	c := tailer.logService.GetClient()
+	uri := fmt.Sprintf("%s?$skip=%d", tailer.logService.entries, bookmark.EntryCount-1)
+	page, err := getLogEntryPage(c, uri)
+	if err != nil {
+		return nil, err
+	}
+	if len(page.entries) == 0 || !bookmark.matches(page.entries[0]) {
+		return nil, nil
+	}
+
+	result := &logPoll{count: -1, found: bookmark.EntryCount - 1, entries: page.entries[1:]}
+	if page.Count > 0 {
+		result.count = page.Count
+	}
+	for page.NextLink != "" {
+		page, err = getLogEntryPage(c, page.NextLink)
+		if err != nil {
+			return nil, err
+		}
+		result.entries = append(result.entries, page.entries...)
+	}
+
+	if result.count >= 0 && result.count < bookmark.EntryCount+len(result.entries) {
+		result.reset = WrappedLogResetReason
+	}
+	return result, nil
+}
+
+// pollScan reads the pages of the log until the last seen entry is found. If
+// the service lists the newest entries first, the scan stops at the page with
+// the last seen entry; otherwise the whole log is read.
+func (tailer *LogTailer) pollScan(bookmark *LogBookmark) (*logPoll, error) {
+	c := tailer.logService.GetClient()
+	result := &logPoll{count: -1, found: -1, newestFirst: bookmark.NewestFirst}
+
+	var entries []*LogEntry
+	uri := tailer.logService.entries
+	for uri != "" {
+		page, err := getLogEntryPage(c, uri)
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) == 0 && page.Count > 0 {
+			result.count = page.Count
+		}
+		entries = append(entries, page.entries...)
+		if newestFirst, ok := logOrder(entries); ok {
+			result.newestFirst = newestFirst
+		}
+
+		if result.found < 0 && bookmark.EntryID != "" {
+			for i := len(entries) - len(page.entries); i < len(entries); i++ {
+				if bookmark.matches(entries[i]) {
+					result.found = i
+					break
+				}
+			}
+		}
+		uri = page.NextLink
+		if result.found >= 0 && result.newestFirst {
+			break
+		}
+	}
+	if result.count < 0 && uri == "" {
+		result.count = len(entries)
+	}
+
+	position := result.found
+	if result.newestFirst {
+		reverseLogEntries(entries)
+		if position >= 0 {
+			position = len(entries) - 1 - position
+		}
+	}
+
+	switch {
+	case bookmark.EntryID == "":
+		result.entries = entries
+	case position >= 0:
+		result.entries = entries[position+1:]
+		if result.count >= 0 && result.count < bookmark.EntryCount+len(result.entries) {
+			result.reset = WrappedLogResetReason
+		}
+	case bookmark.OverWritePolicy == WrapsWhenFullOverWritePolicy && result.count >= bookmark.EntryCount:
+		result.reset = WrappedLogResetReason
+		for _, entry := range entries {
+			if bookmark.isNewer(entry) {
+				result.entries = append(result.entries, entry)
+			}
+		}
+	default:
+		result.reset = ClearedLogResetReason
+		result.entries = entries
+	}
+	return result, nil
+}
+
+// poll reads the new entries of the log and returns them along with the
+// bookmark to advance from.
+func (tailer *LogTailer) poll() (*logPoll, LogBookmark, error) {
+	bookmark := tailer.Bookmark()
+
+	var result *logPoll
+	var err error
+	triedSkip := false
+	switch {
+	case bookmark.EntryID == "":
+	case tailer.FilterQuery && bookmark.EntryURI != "":
+		result, err = tailer.pollFiltered(&bookmark)
+	case !bookmark.NewestFirst && bookmark.EntryCount > 0 && !tailer.skipUnsupported:
+		triedSkip = true
+		result, err = tailer.pollSkip(&bookmark)
+	}
+	if err == nil && result == nil {
+		result, err = tailer.pollScan(&bookmark)
+		if err == nil && triedSkip && result.found == bookmark.EntryCount-1 {
+			// The entry was where $skip should have found it.
+			tailer.skipUnsupported = true
+		}
+	}
+	if err != nil {
+		return nil, bookmark, err
+	}
+
+	if result.reset == ClearedLogResetReason {
+		bookmark = LogBookmark{LogService: bookmark.LogService, OverWritePolicy: bookmark.OverWritePolicy}
+	}
+	if result.count >= 0 {
+		bookmark.EntryCount = result.count - len(result.entries)
+	}
+	bookmark.NewestFirst = result.newestFirst
+	if tailer.logService.OverWritePolicy != "" {
+		bookmark.OverWritePolicy = tailer.logService.OverWritePolicy
+	}
+	return result, bookmark, nil
+}
+
+// Poll returns the entries added to the log since the last poll, oldest
+// first, and advances the bookmark past them. If entries were removed from
+// the log, the reason is returned as well.
+func (tailer *LogTailer) Poll() ([]*LogEntry, LogResetReason, error) {
+	result, bookmark, err := tailer.poll()
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, entry := range result.entries {
+		bookmark = bookmark.advance(entry)
+	}
+	tailer.mu.Lock()
+	tailer.bookmark = bookmark
+	tailer.mu.Unlock()
+	return result.entries, result.reset, nil
+}
+
+// Tail polls the log until stop is closed and sends its new entries on the
+// returned channel. Each event carries the bookmark to resume after it. The
+// channel is closed once tailing stopped.
+func (tailer *LogTailer) Tail(stop <-chan struct{}) <-chan LogTailEvent {
+	events := make(chan LogTailEvent)
+	go func() {
+		defer close(events)
+		tailer.tail(events, stop)
+	}()
+	return events
+}
+
+// tail polls the log until stop is closed and sends its events.
+func (tailer *LogTailer) tail(events chan<- LogTailEvent, stop <-chan struct{}) {
+	interval := tailer.PollInterval
+	if interval <= 0 {
+		interval = defaultLogPollInterval
+	}
+
+	send := func(event LogTailEvent) bool {
+		select {
+		case events <- event:
+			return true
+		case <-stop:
+			return false
+		}
+	}
+
+	for {
+		result, bookmark, err := tailer.poll()
+		if err != nil {
+			if !send(LogTailEvent{Err: err, Bookmark: bookmark}) {
+				return
+			}
+		} else {
+			if result.reset != "" {
+				tailer.mu.Lock()
+				tailer.bookmark = bookmark
+				tailer.mu.Unlock()
+				if !send(LogTailEvent{Reset: result.reset, Bookmark: bookmark}) {
+					return
+				}
+			}
+			for _, entry := range result.entries {
+				bookmark = bookmark.advance(entry)
+				tailer.mu.Lock()
+				tailer.bookmark = bookmark
+				tailer.mu.Unlock()
+				if !send(LogTailEvent{Entry: entry, Bookmark: bookmark}) {
+					return
+				}
+			}
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// TailLogs tails several logs until stop is closed and sends their new
+// entries on a single channel. The log of an event is identified by the
+// LogService of its bookmark. The channel is closed once tailing stopped.
+func TailLogs(tailers []*LogTailer, stop <-chan struct{}) <-chan LogTailEvent {
+	events := make(chan LogTailEvent)
+	var wg sync.WaitGroup
+	for _, tailer := range tailers {
+		wg.Add(1)
+		go func(tailer *LogTailer) {
+			defer wg.Done()
+			tailer.tail(events, stop)
+		}(tailer)
+	}
+
+	go func() {
+		wg.Wait()
+		close(events)
+	}()
+	return events
+}
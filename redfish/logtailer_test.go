@@ -0,0 +1,213 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+var tailedLogServiceBody = `{
+		"@odata.type": "#LogService.v1_2_0.LogService",
+		"@odata.id": "/redfish/v1/Managers/1/LogServices/SEL",
+		"Id": "SEL",
+		"Name": "IPMI SEL",
+		"OverWritePolicy": "%s",
+		"Entries": {
+			"@odata.id": "/redfish/v1/Managers/1/LogServices/SEL/Entries"
+		}
+	}`
+
+// logEntryPageBody returns a page of the tailed log with the given entries,
+// each given as ID and minute of creation.
+func logEntryPageBody(count int, nextLink string, entries ...[2]int) string {
+	members := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		members = append(members, fmt.Sprintf(`{
+			"@odata.id": "/redfish/v1/Managers/1/LogServices/SEL/Entries/%d",
+			"Id": "%d",
+			"Name": "Log Entry %d",
+			"EntryType": "SEL",
+			"Created": "2023-03-21T10:%02d:00Z"
+		}`, entry[0], entry[0], entry[0], entry[1]))
+	}
+
+	next := ""
+	if nextLink != "" {
+		next = fmt.Sprintf(`, "Members@odata.nextLink": %q`, nextLink)
+	}
+	return fmt.Sprintf(`{
+		"@odata.type": "#LogEntryCollection.LogEntryCollection",
+		"@odata.id": "/redfish/v1/Managers/1/LogServices/SEL/Entries",
+		"Name": "Log Service Collection",
+		"Members": [%s],
+		"Members@odata.count": %d%s
+	}`, strings.Join(members, ","), count, next)
+}
+
+func getTailedLogService(t *testing.T, policy OverWritePolicy, testClient *common.TestClient) *LogService {
+	var result LogService
+	if err := json.NewDecoder(strings.NewReader(fmt.Sprintf(tailedLogServiceBody, policy))).Decode(&result); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	result.SetClient(testClient)
+	return &result
+}
+
+func logEntryIDs(entries []*LogEntry) string {
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+// TestLogTailerSkip tests tailing a log that lists the oldest entries first.
+func TestLogTailerSkip(t *testing.T) {
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {
+				getCall(logEntryPageBody(2, "", [2]int{1, 1}, [2]int{2, 2})),
+				getCall(logEntryPageBody(3, "", [2]int{2, 2}, [2]int{3, 3})),
+				getCall(logEntryPageBody(1, "", [2]int{1, 5})),
+			},
+		},
+	}
+	tailer, err := NewLogTailer(getTailedLogService(t, NeverOverWritesOverWritePolicy, testClient), nil)
+	if err != nil {
+		t.Fatalf("Error creating log tailer: %s", err)
+	}
+
+	entries, reset, err := tailer.Poll()
+	if err != nil {
+		t.Fatalf("Error polling log: %s", err)
+	}
+	if logEntryIDs(entries) != "1,2" || reset != "" {
+		t.Errorf("Invalid first poll: %s %s", logEntryIDs(entries), reset)
+	}
+
+	entries, reset, err = tailer.Poll()
+	if err != nil {
+		t.Fatalf("Error polling log: %s", err)
+	}
+	if logEntryIDs(entries) != "3" || reset != "" {
+		t.Errorf("Invalid second poll: %s %s", logEntryIDs(entries), reset)
+	}
+	if calls := testClient.CapturedCalls(); calls[1].URL != "/redfish/v1/Managers/1/LogServices/SEL/Entries?$skip=1" {
+		t.Errorf("Invalid skip call: %s", calls[1].URL)
+	}
+
+	bookmark := tailer.Bookmark()
+	if bookmark.EntryID != "3" || bookmark.EntryCount != 3 || bookmark.Created != "2023-03-21T10:03:00Z" {
+		t.Errorf("Invalid bookmark: %+v", bookmark)
+	}
+
+	// The entry at the bookmark is gone and the log shrunk, so it was cleared.
+	resumed, err := NewLogTailer(getTailedLogService(t, NeverOverWritesOverWritePolicy, testClient), &bookmark)
+	if err != nil {
+		t.Fatalf("Error resuming log tailer: %s", err)
+	}
+	testClient.CustomReturnForActions[http.MethodGet] = append(testClient.CustomReturnForActions[http.MethodGet],
+		getCall(logEntryPageBody(1, "", [2]int{1, 5})))
+	entries, reset, err = resumed.Poll()
+	if err != nil {
+		t.Fatalf("Error polling log: %s", err)
+	}
+	if logEntryIDs(entries) != "1" || reset != ClearedLogResetReason {
+		t.Errorf("Invalid poll after clear: %s %s", logEntryIDs(entries), reset)
+	}
+	if bookmark := resumed.Bookmark(); bookmark.EntryCount != 1 || bookmark.Created != "2023-03-21T10:05:00Z" {
+		t.Errorf("Invalid bookmark after clear: %+v", bookmark)
+	}
+}
+
+// TestLogTailerWrap tests tailing a log that lists the newest entries first
+// and wraps when full.
+func TestLogTailerWrap(t *testing.T) {
+	bookmark := &LogBookmark{
+		LogService:  "/redfish/v1/Managers/1/LogServices/SEL",
+		EntryID:     "2",
+		EntryURI:    "/redfish/v1/Managers/1/LogServices/SEL/Entries/2",
+		Created:     "2023-03-21T10:02:00Z",
+		SameTimeIDs: []string{"2"},
+		EntryCount:  3,
+		NewestFirst: true,
+	}
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {
+				// The scan stops at the page with the bookmarked entry.
+				getCall(logEntryPageBody(3, "/redfish/v1/Managers/1/LogServices/SEL/Entries?$skiptoken=2", [2]int{4, 4}, [2]int{3, 3})),
+				getCall(logEntryPageBody(3, "/redfish/v1/Managers/1/LogServices/SEL/Entries?$skiptoken=4", [2]int{2, 2})),
+				// Entries 1 to 3 were overwritten.
+				getCall(logEntryPageBody(3, "", [2]int{6, 6}, [2]int{5, 5}, [2]int{4, 4})),
+			},
+		},
+	}
+	tailer, err := NewLogTailer(getTailedLogService(t, WrapsWhenFullOverWritePolicy, testClient), bookmark)
+	if err != nil {
+		t.Fatalf("Error creating log tailer: %s", err)
+	}
+
+	entries, reset, err := tailer.Poll()
+	if err != nil {
+		t.Fatalf("Error polling log: %s", err)
+	}
+	if logEntryIDs(entries) != "3,4" || reset != WrappedLogResetReason {
+		t.Errorf("Invalid first poll: %s %s", logEntryIDs(entries), reset)
+	}
+	if len(testClient.CapturedCalls()) != 2 {
+		t.Errorf("Expected the scan to stop at the bookmarked entry: %+v", testClient.CapturedCalls())
+	}
+
+	entries, reset, err = tailer.Poll()
+	if err != nil {
+		t.Fatalf("Error polling log: %s", err)
+	}
+	if logEntryIDs(entries) != "5,6" || reset != WrappedLogResetReason {
+		t.Errorf("Invalid second poll: %s %s", logEntryIDs(entries), reset)
+	}
+
+	if _, err := NewLogTailer(getTailedLogService(t, WrapsWhenFullOverWritePolicy, testClient),
+		&LogBookmark{LogService: "/redfish/v1/Systems/1/LogServices/SEL"}); err == nil {
+		t.Error("Expected a bookmark of another log to fail")
+	}
+}
+
+// TestLogTailerTail tests tailing a log on a channel.
+func TestLogTailerTail(t *testing.T) {
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {
+				getCall(logEntryPageBody(2, "", [2]int{1, 1}, [2]int{2, 1})),
+			},
+		},
+	}
+	tailer, err := NewLogTailer(getTailedLogService(t, WrapsWhenFullOverWritePolicy, testClient), nil)
+	if err != nil {
+		t.Fatalf("Error creating log tailer: %s", err)
+	}
+
+	stop := make(chan struct{})
+	events := TailLogs([]*LogTailer{tailer}, stop)
+	first := <-events
+	second := <-events
+	close(stop)
+	for range events {
+	}
+
+	if first.Entry.ID != "1" || first.Bookmark.EntryCount != 1 {
+		t.Errorf("Invalid first event: %+v", first)
+	}
+	if second.Entry.ID != "2" || strings.Join(second.Bookmark.SameTimeIDs, ",") != "1,2" ||
+		second.Bookmark.LogService != "/redfish/v1/Managers/1/LogServices/SEL" {
+		t.Errorf("Invalid second event: %+v", second)
+	}
+}
@@ -234,6 +234,44 @@ func (manageraccount *ManagerAccount) ChangePassword(newPassword, sessionAccount
 	return manageraccount.Post(manageraccount.changePasswordTarget, parameters)
 }
 
+// Enable allows the account to log in.
+func (manageraccount *ManagerAccount) Enable() error {
+	return manageraccount.setEnabled(true)
+}
+
+// Disable prevents the account from logging in without deleting it.
+func (manageraccount *ManagerAccount) Disable() error {
+	return manageraccount.setEnabled(false)
+}
+
+func (manageraccount *ManagerAccount) setEnabled(enabled bool) error {
+	payload := struct {
+		Enabled bool
+	}{
+		Enabled: enabled,
+	}
+	if err := manageraccount.Patch(manageraccount.ODataID, payload); err != nil {
+		return err
+	}
+	manageraccount.Enabled = enabled
+	return nil
+}
+
+// Unlock clears the lockout condition of an account that the account service
+// locked because the AccountLockoutThreshold was exceeded.
+func (manageraccount *ManagerAccount) Unlock() error {
+	payload := struct {
+		Locked bool
+	}{
+		Locked: false,
+	}
+	if err := manageraccount.Patch(manageraccount.ODataID, payload); err != nil {
+		return err
+	}
+	manageraccount.Locked = false
+	return nil
+}
+
 // Update commits updates to this object's properties to the running system.
 func (manageraccount *ManagerAccount) Update() error {
 	// Get a representation of the object's original state so we can find what
@@ -98,3 +98,41 @@ func TestManagerAccountUpdate(t *testing.T) {
 		t.Errorf("Unexpected Role ID update payload: %s", calls[0].Payload)
 	}
 }
+
+// TestManagerAccountUnlock tests unlocking and disabling an account.
+func TestManagerAccountUnlock(t *testing.T) {
+	var result ManagerAccount
+	err := json.NewDecoder(strings.NewReader(managerAccountBody)).Decode(&result)
+	if err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	testClient := &common.TestClient{}
+	result.SetClient(testClient)
+
+	result.Locked = true
+	if err := result.Unlock(); err != nil {
+		t.Errorf("Error making Unlock call: %s", err)
+	}
+
+	if err := result.Disable(); err != nil {
+		t.Errorf("Error making Disable call: %s", err)
+	}
+
+	if result.Locked || result.Enabled {
+		t.Errorf("Account should be unlocked and disabled: %t %t", result.Locked, result.Enabled)
+	}
+
+	calls := testClient.CapturedCalls()
+	if len(calls) != 2 {
+		t.Fatalf("Expected two calls to be made, captured: %v", calls)
+	}
+
+	if calls[0].URL != "/redfish/v1/AccountService/Accounts/1" || calls[0].Payload != "map[Locked:false]" {
+		t.Errorf("Unexpected Unlock call: %v", calls[0])
+	}
+
+	if calls[1].Payload != "map[Enabled:false]" {
+		t.Errorf("Unexpected Disable payload: %s", calls[1].Payload)
+	}
+}
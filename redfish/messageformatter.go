@@ -0,0 +1,378 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+// bundledRegistryFiles are copies of the DMTF standard Base, ResourceEvent and
+// TaskEvent registries, which are the ones most commonly seen in responses and
+// events. They are used when the service does not provide a registry.
+//
+//go:embed registries/*.json
+var bundledRegistryFiles embed.FS
+
+var (
+	bundledRegistriesOnce sync.Once
+	bundledRegistries     []*MessageRegistry
+	errBundledRegistries  error
+)
+
+// loadBundledRegistries decodes the bundled registries once.
+func loadBundledRegistries() ([]*MessageRegistry, error) {
+	bundledRegistriesOnce.Do(func() {
+		files, err := bundledRegistryFiles.ReadDir("registries")
+		if err != nil {
+			errBundledRegistries = err
+			return
+		}
+		for _, file := range files {
+			b, err := bundledRegistryFiles.ReadFile(path.Join("registries", file.Name()))
+			if err != nil {
+				errBundledRegistries = err
+				return
+			}
+			var registry MessageRegistry
+			if err := json.Unmarshal(b, &registry); err != nil {
+				errBundledRegistries = fmt.Errorf("bundled registry %s is invalid: %w", file.Name(), err)
+				return
+			}
+			bundledRegistries = append(bundledRegistries, &registry)
+		}
+	})
+	return bundledRegistries, errBundledRegistries
+}
+
+// parsedMessageID is a MessageId split into its registry prefix, version and
+// message key. Both 'Base.1.8.PropertyUnknown' and 'Base.1.8.1.PropertyUnknown'
+// are accepted.
+type parsedMessageID struct {
+	prefix string
+	major  int
+	minor  int
+	key    string
+}
+
+func parseMessageID(messageID string) (*parsedMessageID, error) {
+	parts := strings.Split(strings.TrimSpace(messageID), ".")
+	if len(parts) != MessageIDSectionLength && len(parts) != MessageIDSectionLength+1 {
+		return nil, fmt.Errorf("received invalid messageID %s", messageID)
+	}
+
+	major, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("received invalid messageID %s", messageID)
+	}
+	minor, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("received invalid messageID %s", messageID)
+	}
+	return &parsedMessageID{prefix: parts[0], major: major, minor: minor, key: parts[len(parts)-1]}, nil
+}
+
+// registryMajorMinor returns the major and minor version of a registry
+// version such as '1.16.0'.
+func registryMajorMinor(version string) (major, minor int, ok bool) {
+	parts := strings.Split(version, ".")
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
+
+// FormattedMessage is a message rendered from its message registry.
+type FormattedMessage struct {
+	// MessageID is the MessageId of the message.
+	MessageID string
+	// Message is the message with its arguments substituted.
+	Message string
+	// Severity is the severity of the message.
+	Severity string
+	// Resolution is the suggested resolution of the message.
+	Resolution string
+	// Registry is the prefix and version of the registry the message was
+	// found in, for example 'Base.1.16.0'.
+	Registry string
+}
+
+// MessageFormatter renders messages from their message registries, with the
+// message arguments substituted. Registries are read from the service once
+// and cached per prefix and version. A message is taken from the registry of
+// the requested version if available, and otherwise from the nearest
+// registry with the same major version, which is compatible by definition.
+// The DMTF TaskEvent registry and the commonly seen messages of the Base and
+// ResourceEvent registries are bundled for services that do not provide them,
+// or to format messages offline.
+type MessageFormatter struct {
+	// Language is the RFC5646-conformant language code of the registries
+	// read from the service. It defaults to 'en'.
+	Language string
+
+	client         common.Client
+	registriesLink string
+
+	mu sync.Mutex
+	// registries are the registries added or read from the service.
+	registries []*MessageRegistry
+	// files are the registry files of the service, once listed.
+	files []*MessageRegistryFile
+	// loadedPrefixes are the prefixes whose registries were read from the
+	// service.
+	loadedPrefixes map[string]bool
+}
+
+// NewMessageFormatter creates a message formatter reading registries from the
+// given registries collection of a service. With a nil client, only the
+// bundled and added registries are used.
+func NewMessageFormatter(c common.Client, registriesLink string) *MessageFormatter {
+	return &MessageFormatter{
+		client:         c,
+		registriesLink: registriesLink,
+		loadedPrefixes: make(map[string]bool),
+	}
+}
+
+// AddRegistry adds a registry to the formatter, such as an OEM registry
+// loaded from a file. Added registries are preferred over bundled ones.
+func (formatter *MessageFormatter) AddRegistry(registry *MessageRegistry) {
+	formatter.mu.Lock()
+	defer formatter.mu.Unlock()
+	formatter.registries = append(formatter.registries, registry)
+}
+
+// loadServiceRegistries reads the registries with the given prefix from the
+// service, unless this was done before. Must be called with mu held.
+func (formatter *MessageFormatter) loadServiceRegistries(prefix string) error {
+	if formatter.client == nil || formatter.registriesLink == "" || formatter.loadedPrefixes[prefix] {
+		return nil
+	}
+
+	if formatter.files == nil {
+		links, err := common.GetCollection(formatter.client, formatter.registriesLink)
+		if err != nil {
+			return err
+		}
+		files := make([]*MessageRegistryFile, 0, len(links.ItemLinks))
+		for _, link := range links.ItemLinks {
+			file, err := GetMessageRegistryFile(formatter.client, link)
+			if err != nil {
+				return err
+			}
+			files = append(files, file)
+		}
+		formatter.files = files
+	}
+
+	language := formatter.Language
+	if language == "" {
+		language = "en"
+	}
+	var registries []*MessageRegistry
+	for _, file := range formatter.files {
+		if strings.SplitN(file.Registry, ".", 2)[0] != prefix {
+			continue
+		}
+
+		var uri string
+		for _, location := range file.Location {
+			if location.URI == "" {
+				continue
+			}
+			if location.Language == language {
+				uri = location.URI
+				break
+			}
+			if uri == "" || location.Language == "default" {
+				uri = location.URI
+			}
+		}
+		if uri == "" {
+			continue
+		}
+
+		registry, err := GetMessageRegistry(formatter.client, uri)
+		if err != nil {
+			return err
+		}
+		registries = append(registries, registry)
+	}
+
+	formatter.registries = append(formatter.registries, registries...)
+	formatter.loadedPrefixes[prefix] = true
+	return nil
+}
+
+// Lookup finds the registry message of a MessageId and returns it along with
+// the prefix and version of the registry it was found in.
+func (formatter *MessageFormatter) Lookup(messageID string) (*MessageRegistryMessage, string, error) {
+	id, err := parseMessageID(messageID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	formatter.mu.Lock()
+	serviceErr := formatter.loadServiceRegistries(id.prefix)
+	candidates := append([]*MessageRegistry(nil), formatter.registries...)
+	formatter.mu.Unlock()
+
+	bundled, err := loadBundledRegistries()
+	if err != nil {
+		return nil, "", err
+	}
+	candidates = append(candidates, bundled...)
+
+	// Rank the registries of the same major version: the requested minor
+	// version first, then newer ones from the oldest and then older ones
+	// from the newest. The order of equally ranked registries is kept so
+	// that registries of the service win over bundled ones.
+	type rankedRegistry struct {
+		registry *MessageRegistry
+		rank     int
+	}
+	var ranked []rankedRegistry
+	for _, registry := range candidates {
+		major, minor, ok := registryMajorMinor(registry.RegistryVersion)
+		if !ok || registry.RegistryPrefix != id.prefix || major != id.major {
+			continue
+		}
+		rank := minor - id.minor
+		if rank < 0 {
+			rank = 1000 - rank
+		}
+		ranked = append(ranked, rankedRegistry{registry: registry, rank: rank})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].rank < ranked[j].rank
+	})
+
+	for _, candidate := range ranked {
+		if message, ok := candidate.registry.Messages[id.key]; ok {
+			return &message, candidate.registry.RegistryPrefix + "." + candidate.registry.RegistryVersion, nil
+		}
+	}
+
+	if serviceErr != nil {
+		return nil, "", fmt.Errorf("message %s not found, reading the registries of the service failed: %w", messageID, serviceErr)
+	}
+	return nil, "", fmt.Errorf("message %s not found", messageID)
+}
+
+// substituteMessageArgs replaces the %1, %2, ... placeholders of a message
+// with its arguments.
+func substituteMessageArgs(message string, args []string) string {
+	var sb strings.Builder
+	for i := 0; i < len(message); i++ {
+		if message[i] != '%' {
+			sb.WriteByte(message[i])
+			continue
+		}
+
+		j := i + 1
+		for j < len(message) && message[j] >= '0' && message[j] <= '9' {
+			j++
+		}
+		n, err := strconv.Atoi(message[i+1 : j])
+		if err != nil || n < 1 || n > len(args) {
+			sb.WriteByte(message[i])
+			continue
+		}
+		sb.WriteString(args[n-1])
+		i = j - 1
+	}
+	return sb.String()
+}
+
+// Format renders a message from its MessageId and arguments. The number of
+// arguments and the arguments of type number are checked against the
+// registry.
+func (formatter *MessageFormatter) Format(messageID string, args []string) (*FormattedMessage, error) {
+	message, registry, err := formatter.Lookup(messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) != message.NumberOfArgs {
+		return nil, fmt.Errorf("message %s takes %d arguments, got %d", messageID, message.NumberOfArgs, len(args))
+	}
+	for i, paramType := range message.ParamTypes {
+		if i >= len(args) || paramType != NumberParamType {
+			continue
+		}
+		if _, err := strconv.ParseFloat(strings.TrimSpace(args[i]), 64); err != nil {
+			return nil, fmt.Errorf("argument %d of message %s should be a number, got '%s'", i+1, messageID, args[i])
+		}
+	}
+
+	severity := message.MessageSeverity
+	if severity == "" {
+		severity = message.Severity
+	}
+	return &FormattedMessage{
+		MessageID:  messageID,
+		Message:    substituteMessageArgs(message.Message, args),
+		Severity:   severity,
+		Resolution: message.Resolution,
+		Registry:   registry,
+	}, nil
+}
+
+// formatWithOverrides renders a message, keeping the severity and resolution
+// the service set in place of the ones of the registry.
+func (formatter *MessageFormatter) formatWithOverrides(messageID string, args []string, severity, resolution string) (*FormattedMessage, error) {
+	result, err := formatter.Format(messageID, args)
+	if err != nil {
+		return nil, err
+	}
+	if severity != "" {
+		result.Severity = severity
+	}
+	if resolution != "" {
+		result.Resolution = resolution
+	}
+	return result, nil
+}
+
+// FormatMessage renders a message returned by the service.
+func (formatter *MessageFormatter) FormatMessage(message *Message) (*FormattedMessage, error) {
+	return formatter.formatWithOverrides(message.MessageID, message.MessageArgs, message.MessageSeverity, message.Resolution)
+}
+
+// FormatCommonMessage renders a message returned by the service.
+func (formatter *MessageFormatter) FormatCommonMessage(message *common.Message) (*FormattedMessage, error) {
+	return formatter.formatWithOverrides(message.MessageID, message.MessageArgs, message.Severity, message.Resolution)
+}
+
+// FormatExtendedInfo renders the extended information of an error.
+func (formatter *MessageFormatter) FormatExtendedInfo(info *common.ErrExtendedInfo) (*FormattedMessage, error) {
+	return formatter.formatWithOverrides(info.MessageID, info.MessageArgs, info.Severity, info.Resolution)
+}
+
+// FormatEventRecord renders the message of an event.
+func (formatter *MessageFormatter) FormatEventRecord(event *EventRecord) (*FormattedMessage, error) {
+	return formatter.formatWithOverrides(event.MessageID, event.MessageArgs, string(event.MessageSeverity), event.Resolution)
+}
+
+// FormatLogEntry renders the message of a log entry.
+func (formatter *MessageFormatter) FormatLogEntry(entry *LogEntry) (*FormattedMessage, error) {
+	return formatter.formatWithOverrides(entry.MessageID, entry.MessageArgs, string(entry.Severity), entry.Resolution)
+}
@@ -0,0 +1,147 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+var registryCollectionBody = `{
+		"@odata.type": "#MessageRegistryFileCollection.MessageRegistryFileCollection",
+		"@odata.id": "/redfish/v1/Registries",
+		"Name": "Registry File Collection",
+		"Members": [
+			{
+				"@odata.id": "/redfish/v1/Registries/MyRegistry"
+			}
+		],
+		"Members@odata.count": 1
+	}`
+
+var registryFileBody = `{
+		"@odata.type": "#MessageRegistryFile.v1_1_3.MessageRegistryFile",
+		"@odata.id": "/redfish/v1/Registries/MyRegistry",
+		"Id": "MyRegistry",
+		"Name": "MyRegistry Message Registry File",
+		"Languages": ["en"],
+		"Registry": "MyRegistry.2.2.0",
+		"Location": [
+			{
+				"Language": "en",
+				"Uri": "/redfish/v1/Registries/MyRegistry/MyRegistry.2.2.0.json"
+			}
+		]
+	}`
+
+// TestMessageFormatterOffline tests formatting messages from the bundled
+// registries.
+func TestMessageFormatterOffline(t *testing.T) {
+	formatter := NewMessageFormatter(nil, "")
+
+	// Base 1.8 messages are found in the newer bundled Base registry.
+	result, err := formatter.Format("Base.1.8.1.PropertyValueNotInList", []string{"Fast", "IndicatorLED"})
+	if err != nil {
+		t.Fatalf("Error formatting message: %s", err)
+	}
+	if result.Message != "The value 'Fast' for the property IndicatorLED is not in the list of acceptable values." ||
+		result.Severity != "Warning" || result.Registry != "Base.1.16.0" {
+		t.Errorf("Invalid formatted message: %+v", result)
+	}
+
+	if _, err := formatter.Format("Base.1.8.PropertyValueNotInList", []string{"Fast"}); err == nil {
+		t.Error("Expected a missing argument to fail")
+	}
+	if _, err := formatter.Format("Base.1.8.StringValueTooLong", []string{"name", "long"}); err == nil {
+		t.Error("Expected a non-number argument of type number to fail")
+	}
+	if _, err := formatter.Format("Base.2.0.Success", nil); err == nil {
+		t.Error("Expected a message of another major version to fail")
+	}
+
+	result, err = formatter.FormatExtendedInfo(&common.ErrExtendedInfo{
+		MessageID:   "Base.1.12.PropertyMissing",
+		MessageArgs: []string{"UserName"},
+		Resolution:  "Set the UserName.",
+	})
+	if err != nil {
+		t.Fatalf("Error formatting extended info: %s", err)
+	}
+	if result.Message != "The property UserName is a required property and must be included in the request." ||
+		result.Resolution != "Set the UserName." {
+		t.Errorf("Invalid formatted extended info: %+v", result)
+	}
+}
+
+// TestMessageFormatterService tests formatting messages from the registries
+// of the service.
+func TestMessageFormatterService(t *testing.T) {
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {
+				getCall(registryCollectionBody),
+				getCall(registryFileBody),
+				getCall(messageRegistryBody),
+			},
+		},
+	}
+	formatter := NewMessageFormatter(testClient, "/redfish/v1/Registries")
+
+	result, err := formatter.FormatLogEntry(&LogEntry{MessageID: "MyRegistry.2.0.ThirdMessage", MessageArgs: []string{"a", "b"}})
+	if err != nil {
+		t.Fatalf("Error formatting message: %s", err)
+	}
+	if result.Message != "This message has two args: a and b" || result.Severity != "Warning" || result.Registry != "MyRegistry.2.2.0" {
+		t.Errorf("Invalid formatted message: %+v", result)
+	}
+
+	// The registry is read once.
+	if _, err := formatter.Format("MyRegistry.2.2.FirstMessage", []string{"x"}); err != nil {
+		t.Errorf("Error formatting cached message: %s", err)
+	}
+	if len(testClient.CapturedCalls()) != 3 {
+		t.Errorf("Expected the registry to be cached: %+v", testClient.CapturedCalls())
+	}
+}
+
+// TestMessageFormatterServiceRetry tests reading the registries again after
+// the service failed to return them.
+func TestMessageFormatterServiceRetry(t *testing.T) {
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {
+				getCall(registryCollectionBody),
+				getCall(registryFileBody),
+				common.TestResponse(http.StatusServiceUnavailable, "{}"),
+				getCall(messageRegistryBody),
+			},
+		},
+	}
+	formatter := NewMessageFormatter(testClient, "/redfish/v1/Registries")
+
+	if _, err := formatter.Format("MyRegistry.2.2.FirstMessage", []string{"x"}); err == nil {
+		t.Error("Expected formatting to fail while the registry cannot be read")
+	}
+
+	if _, err := formatter.Format("MyRegistry.2.2.FirstMessage", []string{"x"}); err != nil {
+		t.Fatalf("Error formatting message: %s", err)
+	}
+	if len(formatter.files) != 1 || len(formatter.registries) != 1 {
+		t.Errorf("Expected the registry to be read once: %d files, %d registries", len(formatter.files), len(formatter.registries))
+	}
+	if len(testClient.CapturedCalls()) != 4 {
+		t.Errorf("Expected only the failed registry to be read again: %+v", testClient.CapturedCalls())
+	}
+}
+
+// TestSubstituteMessageArgs tests substituting message arguments.
+func TestSubstituteMessageArgs(t *testing.T) {
+	args := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}
+	if result := substituteMessageArgs("%1 %10 100% %11", args); result != "a j 100% %11" {
+		t.Errorf("Invalid substitution: %s", result)
+	}
+}
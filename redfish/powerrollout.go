@@ -0,0 +1,282 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultPowerPollInterval is the interval between reads of the power state
+// if none is configured.
+const defaultPowerPollInterval = 5 * time.Second
+
+// ExpectedPowerState returns the power state a resource reaches after a reset
+// of the given type. The result is false for reset types that do not lead to
+// a known power state, such as Nmi. Restarts, such as ForceRestart, end in On,
+// so reaching it alone does not show that a resource that was on restarted.
+func ExpectedPowerState(resetType ResetType) (PowerState, bool) {
+	switch resetType {
+	case OnResetType, ForceOnResetType, ForceRestartResetType, GracefulRestartResetType,
+		PowerCycleResetType, ResumeResetType:
+		return OnPowerState, true
+	case ForceOffResetType, GracefulShutdownResetType:
+		return OffPowerState, true
+	case PauseResetType:
+		return PausedPowerState, true
+	}
+	return "", false
+}
+
+// PowerOutcomeStatus is the outcome of a power action on a system.
+type PowerOutcomeStatus string
+
+const (
+	// SucceededPowerOutcomeStatus means the system accepted the action and,
+	// if waited for, reached the expected power state.
+	SucceededPowerOutcomeStatus PowerOutcomeStatus = "Succeeded"
+	// FailedPowerOutcomeStatus means the system rejected the action.
+	FailedPowerOutcomeStatus PowerOutcomeStatus = "Failed"
+	// TimedOutPowerOutcomeStatus means the system accepted the action but did
+	// not reach the expected power state in time.
+	TimedOutPowerOutcomeStatus PowerOutcomeStatus = "TimedOut"
+	// SkippedPowerOutcomeStatus means the action was not sent because the
+	// rollout was aborted.
+	SkippedPowerOutcomeStatus PowerOutcomeStatus = "Skipped"
+)
+
+// SystemPowerOutcome is the outcome of a power action on one system.
+type SystemPowerOutcome struct {
+	// System is the system the action was sent to.
+	System *ComputerSystem
+	// Batch is the index of the batch the system was part of.
+	Batch int
+	// Status is the outcome of the action.
+	Status PowerOutcomeStatus
+	// PowerState is the last power state read from the system.
+	PowerState PowerState
+	// Error is the reason the action failed or timed out.
+	Error error
+	// Duration is the time from sending the action until the expected power
+	// state was reached or waiting stopped.
+	Duration time.Duration
+}
+
+// PowerRolloutResult is the result of a power rollout.
+type PowerRolloutResult struct {
+	// Outcomes are the outcomes of each system, in the order of the systems.
+	Outcomes []SystemPowerOutcome
+	// Delegated is true if the action was sent to an aggregation service,
+	// which then reset the systems in batches itself.
+	Delegated bool
+	// Aborted is true if the rollout stopped because of too many failures.
+	Aborted bool
+	// Failures is the number of systems that failed or timed out.
+	Failures int
+}
+
+// ErrPowerRolloutAborted is returned by PowerRollout.Run if the rollout
+// stopped because of too many failures.
+var ErrPowerRolloutAborted = errors.New("power rollout aborted after too many failures")
+
+// PowerRollout sends a power action to many systems in batches, which may be
+// managed by different services. A batch is only started once every system
+// of the previous batch has reached the expected power state or failed.
+type PowerRollout struct {
+	// ResetType is the power action to perform.
+	ResetType ResetType
+	// BatchSize is the number of systems reset at the same time. If zero,
+	// all systems are reset in a single batch.
+	BatchSize int
+	// DelayBetweenBatches is the time to wait between batches. A delegated
+	// rollout rounds it up to whole seconds.
+	DelayBetweenBatches time.Duration
+	// MaxFailures is the number of failed systems after which the rollout is
+	// aborted. If zero, the rollout is never aborted.
+	MaxFailures int
+	// WaitTimeout is the time to wait for each system to reach the power
+	// state expected after the action. If zero, the power state is not
+	// checked. A restart is only considered complete once it was observed,
+	// like in ComputerSystem.ResetAndWait.
+	WaitTimeout time.Duration
+	// PollInterval is the interval between reads of the power state. The
+	// default is five seconds.
+	PollInterval time.Duration
+	// Aggregator is an aggregation service that manages the systems, used if
+	// the rollout is delegated.
+	Aggregator *AggregationService
+	// Delegate sends the action for all systems to the Reset action of the
+	// Aggregator, which then resets them in batches itself. The service cannot
+	// be told to stop, so a delegated rollout cannot have MaxFailures set.
+	Delegate bool
+}
+
+// Run performs the power rollout. The result reports the outcome of every
+// system; ErrPowerRolloutAborted is returned along with it if the rollout was
+// aborted.
+func (rollout *PowerRollout) Run(systems []*ComputerSystem) (*PowerRolloutResult, error) {
+	if rollout.ResetType == "" {
+		return nil, errors.New("reset type should not be empty")
+	}
+
+	if rollout.Delegate {
+		switch {
+		case rollout.Aggregator == nil || rollout.Aggregator.resetTarget == "":
+			return nil, errors.New("delegated rollouts require an aggregation service that supports the Reset action")
+		case rollout.MaxFailures > 0:
+			return nil, errors.New("delegated rollouts cannot be aborted after failures")
+		}
+		return rollout.runDelegated(systems)
+	}
+
+	batchSize := rollout.BatchSize
+	if batchSize <= 0 {
+		batchSize = len(systems)
+	}
+
+	result := &PowerRolloutResult{Outcomes: make([]SystemPowerOutcome, len(systems))}
+	for i, system := range systems {
+		result.Outcomes[i] = SystemPowerOutcome{System: system, Batch: i / batchSize, Status: SkippedPowerOutcomeStatus}
+	}
+
+	for start := 0; start < len(systems); start += batchSize {
+		if start > 0 && rollout.DelayBetweenBatches > 0 {
+			time.Sleep(rollout.DelayBetweenBatches)
+		}
+
+		end := start + batchSize
+		if end > len(systems) {
+			end = len(systems)
+		}
+
+		var wg sync.WaitGroup
+		for i := start; i < end; i++ {
+			wg.Add(1)
+			go func(outcome *SystemPowerOutcome) {
+				defer wg.Done()
+				rollout.reset(outcome)
+			}(&result.Outcomes[i])
+		}
+		wg.Wait()
+
+		for i := start; i < end; i++ {
+			if result.Outcomes[i].Status != SucceededPowerOutcomeStatus {
+				result.Failures++
+			}
+		}
+		if rollout.MaxFailures > 0 && result.Failures >= rollout.MaxFailures && end < len(systems) {
+			result.Aborted = true
+			return result, ErrPowerRolloutAborted
+		}
+	}
+
+	return result, nil
+}
+
+// reset sends the power action to the system of an outcome and waits for
+// the expected power state.
+func (rollout *PowerRollout) reset(outcome *SystemPowerOutcome) {
+	started := time.Now()
+	defer func() { outcome.Duration = time.Since(started) }()
+
+	var lastReset string
+	if rollout.waits() {
+		_, lastReset, _ = systemPowerReader(outcome.System)()
+	}
+
+	if err := outcome.System.Reset(rollout.ResetType); err != nil {
+		outcome.Status = FailedPowerOutcomeStatus
+		outcome.Error = err
+		return
+	}
+	rollout.wait(outcome, lastReset, rollout.WaitTimeout)
+}
+
+// waits reports whether the rollout waits for the power state expected after
+// the action.
+func (rollout *PowerRollout) waits() bool {
+	_, ok := ExpectedPowerState(rollout.ResetType)
+	return rollout.WaitTimeout > 0 && ok
+}
+
+// wait waits for the system of an outcome to reach the expected power state.
+// lastReset is the LastResetTime of the system before the action was sent.
+func (rollout *PowerRollout) wait(outcome *SystemPowerOutcome, lastReset string, timeout time.Duration) {
+	outcome.Status = SucceededPowerOutcomeStatus
+	if !rollout.waits() {
+		return
+	}
+
+	attempt := waitForPowerState(systemPowerReader(outcome.System), rollout.ResetType, lastReset, timeout, rollout.PollInterval)
+	outcome.PowerState = attempt.PowerState
+	if attempt.Error != nil {
+		outcome.Status = TimedOutPowerOutcomeStatus
+		outcome.Error = fmt.Errorf("system %s: %w", outcome.System.ID, attempt.Error)
+	}
+}
+
+// runDelegated sends the power action to the aggregation service and waits
+// for all systems to reach the expected power state. The service does not
+// report when it starts a batch, so systems of later batches are given the
+// wait timeout and delay of each earlier batch in addition to their own.
+func (rollout *PowerRollout) runDelegated(systems []*ComputerSystem) (*PowerRolloutResult, error) {
+	targets := make([]string, 0, len(systems))
+	for _, system := range systems {
+		targets = append(targets, system.ODataID)
+	}
+
+	batchSize := rollout.BatchSize
+	if batchSize <= 0 {
+		batchSize = len(systems)
+	}
+
+	// The service only accepts whole seconds
+	delaySeconds := int((rollout.DelayBetweenBatches + time.Second - 1) / time.Second)
+	delay := time.Duration(delaySeconds) * time.Second
+
+	lastResets := make([]string, len(systems))
+	if rollout.waits() {
+		var wg sync.WaitGroup
+		for i, system := range systems {
+			wg.Add(1)
+			go func(i int, system *ComputerSystem) {
+				defer wg.Done()
+				_, lastResets[i], _ = systemPowerReader(system)()
+			}(i, system)
+		}
+		wg.Wait()
+	}
+
+	started := time.Now()
+	err := rollout.Aggregator.Reset(batchSize, delaySeconds, rollout.ResetType, targets)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PowerRolloutResult{Outcomes: make([]SystemPowerOutcome, len(systems)), Delegated: true}
+	var wg sync.WaitGroup
+	for i, system := range systems {
+		result.Outcomes[i] = SystemPowerOutcome{System: system, Batch: i / batchSize}
+		batch := time.Duration(result.Outcomes[i].Batch)
+		timeout := rollout.WaitTimeout*(batch+1) + delay*batch
+
+		wg.Add(1)
+		go func(outcome *SystemPowerOutcome, lastReset string) {
+			defer wg.Done()
+			rollout.wait(outcome, lastReset, timeout)
+			outcome.Duration = time.Since(started)
+		}(&result.Outcomes[i], lastResets[i])
+	}
+	wg.Wait()
+
+	for i := range result.Outcomes {
+		if result.Outcomes[i].Status != SucceededPowerOutcomeStatus {
+			result.Failures++
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,250 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+// testPowerSystem creates a system managed by its own service, which answers
+// the reset action with resetResponse and reports the power states in order.
+func testPowerSystem(t *testing.T, id string, resetResponse *http.Response, powerStates ...PowerState) (*ComputerSystem, *common.TestClient) {
+	var system ComputerSystem
+	err := json.NewDecoder(strings.NewReader(`{
+		"@odata.id": "/redfish/v1/Systems/` + id + `",
+		"Id": "` + id + `",
+		"PowerState": "On",
+		"Actions": {"#ComputerSystem.Reset": {"target": "/redfish/v1/Systems/` + id + `/Actions/ComputerSystem.Reset"}}
+	}`)).Decode(&system)
+	if err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	var gets []interface{}
+	for _, state := range powerStates {
+		gets = append(gets, getCall(`{"@odata.id": "/redfish/v1/Systems/`+id+`", "Id": "`+id+`", "PowerState": "`+string(state)+`"}`))
+	}
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{http.MethodGet: gets},
+	}
+	if resetResponse != nil {
+		testClient.CustomReturnForActions[http.MethodPost] = []interface{}{resetResponse}
+	}
+	system.SetClient(testClient)
+	return &system, testClient
+}
+
+// TestPowerRollout tests resetting systems in batches and aborting on failures.
+func TestPowerRollout(t *testing.T) {
+	system1, _ := testPowerSystem(t, "1", nil, PoweringOffPowerState, OffPowerState)
+	system2, _ := testPowerSystem(t, "2", &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Body:       io.NopCloser(bytes.NewBufferString(`{"error": {"code": "Base.1.0.GeneralError", "message": "busy"}}`)),
+	})
+	system3, client3 := testPowerSystem(t, "3", nil)
+
+	rollout := &PowerRollout{
+		ResetType:    ForceOffResetType,
+		BatchSize:    1,
+		MaxFailures:  1,
+		WaitTimeout:  time.Second,
+		PollInterval: time.Millisecond,
+	}
+	result, err := rollout.Run([]*ComputerSystem{system1, system2, system3})
+	if err != ErrPowerRolloutAborted {
+		t.Errorf("Expected the rollout to be aborted, got: %v", err)
+	}
+
+	if !result.Aborted || result.Failures != 1 || len(result.Outcomes) != 3 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+
+	outcome := result.Outcomes[0]
+	if outcome.Status != SucceededPowerOutcomeStatus || outcome.PowerState != OffPowerState || outcome.Batch != 0 {
+		t.Errorf("Unexpected outcome of system 1: %+v", outcome)
+	}
+	if outcome := result.Outcomes[1]; outcome.Status != FailedPowerOutcomeStatus || outcome.Error == nil {
+		t.Errorf("Unexpected outcome of system 2: %+v", outcome)
+	}
+	if outcome := result.Outcomes[2]; outcome.Status != SkippedPowerOutcomeStatus || outcome.Batch != 2 {
+		t.Errorf("Unexpected outcome of system 3: %+v", outcome)
+	}
+	if calls := client3.CapturedCalls(); len(calls) != 0 {
+		t.Errorf("Expected no calls to a skipped system, captured: %v", calls)
+	}
+}
+
+// TestPowerRolloutTimeout tests a system that does not reach the expected power state.
+func TestPowerRolloutTimeout(t *testing.T) {
+	states := make([]PowerState, 50)
+	for i := range states {
+		states[i] = PoweringOnPowerState
+	}
+	system, _ := testPowerSystem(t, "1", nil, states...)
+
+	rollout := &PowerRollout{
+		ResetType:    OnResetType,
+		WaitTimeout:  5 * time.Millisecond,
+		PollInterval: time.Millisecond,
+	}
+	result, err := rollout.Run([]*ComputerSystem{system})
+	if err != nil {
+		t.Fatalf("Error running rollout: %s", err)
+	}
+
+	if outcome := result.Outcomes[0]; outcome.Status != TimedOutPowerOutcomeStatus || outcome.PowerState != PoweringOnPowerState {
+		t.Errorf("Unexpected outcome: %+v", outcome)
+	}
+}
+
+// TestPowerRolloutDelegated tests delegating a rollout to an aggregation service.
+func TestPowerRolloutDelegated(t *testing.T) {
+	var aggregator AggregationService
+	err := json.NewDecoder(strings.NewReader(aggregationServiceBody)).Decode(&aggregator)
+	if err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	aggregatorClient := &common.TestClient{}
+	aggregator.SetClient(aggregatorClient)
+
+	// The systems are read before the action and then observed restarting
+	system1, client1 := testPowerSystem(t, "1", nil, OnPowerState, OffPowerState, OnPowerState)
+	system2, _ := testPowerSystem(t, "2", nil, OnPowerState, PoweringOnPowerState, OnPowerState)
+
+	rollout := &PowerRollout{
+		ResetType:           GracefulRestartResetType,
+		BatchSize:           1,
+		DelayBetweenBatches: 29500 * time.Millisecond,
+		WaitTimeout:         time.Second,
+		PollInterval:        time.Millisecond,
+		Aggregator:          &aggregator,
+		Delegate:            true,
+	}
+	result, err := rollout.Run([]*ComputerSystem{system1, system2})
+	if err != nil {
+		t.Fatalf("Error running rollout: %s", err)
+	}
+
+	if !result.Delegated || result.Failures != 0 || result.Outcomes[1].Batch != 1 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	calls := aggregatorClient.CapturedCalls()
+	if len(calls) != 1 {
+		t.Fatalf("Expected one call to the aggregator, captured: %v", calls)
+	}
+	if !strings.Contains(calls[0].Payload, "DelayBetweenBatchesInSeconds:30") ||
+		!strings.Contains(calls[0].Payload, "TargetURIs:[/redfish/v1/Systems/1 /redfish/v1/Systems/2]") {
+		t.Errorf("Unexpected reset payload: %s", calls[0].Payload)
+	}
+
+	for _, call := range client1.CapturedCalls() {
+		if call.Action != http.MethodGet {
+			t.Errorf("Expected no power action to be sent to the system, captured: %+v", call)
+		}
+	}
+}
+
+// TestPowerRolloutRestartNotObserved tests that a restart is not reported as
+// succeeded just because the system is on.
+func TestPowerRolloutRestartNotObserved(t *testing.T) {
+	states := make([]PowerState, 50)
+	for i := range states {
+		states[i] = OnPowerState
+	}
+	system, _ := testPowerSystem(t, "1", nil, states...)
+
+	rollout := &PowerRollout{
+		ResetType:    ForceRestartResetType,
+		WaitTimeout:  5 * time.Millisecond,
+		PollInterval: time.Millisecond,
+	}
+	result, err := rollout.Run([]*ComputerSystem{system})
+	if err != nil {
+		t.Fatalf("Error running rollout: %s", err)
+	}
+
+	if outcome := result.Outcomes[0]; outcome.Status != TimedOutPowerOutcomeStatus || outcome.Error == nil {
+		t.Errorf("Unexpected outcome: %+v", outcome)
+	}
+}
+
+// TestPowerRolloutDelegatedSingleBatch tests that a delegated rollout without
+// a batch size resets all systems in a single batch.
+func TestPowerRolloutDelegatedSingleBatch(t *testing.T) {
+	var aggregator AggregationService
+	err := json.NewDecoder(strings.NewReader(aggregationServiceBody)).Decode(&aggregator)
+	if err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	aggregatorClient := &common.TestClient{}
+	aggregator.SetClient(aggregatorClient)
+
+	system1, _ := testPowerSystem(t, "1", nil)
+	system2, _ := testPowerSystem(t, "2", nil)
+
+	rollout := &PowerRollout{
+		ResetType:  ForceOffResetType,
+		Aggregator: &aggregator,
+		Delegate:   true,
+	}
+	result, err := rollout.Run([]*ComputerSystem{system1, system2})
+	if err != nil {
+		t.Fatalf("Error running rollout: %s", err)
+	}
+
+	if !result.Delegated || result.Outcomes[1].Batch != 0 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	calls := aggregatorClient.CapturedCalls()
+	if len(calls) != 1 || !strings.Contains(calls[0].Payload, "BatchSize:2") {
+		t.Errorf("Unexpected calls to the aggregator: %v", calls)
+	}
+}
+
+// TestPowerRolloutNotDelegated tests that a rollout is only delegated to an
+// aggregation service if requested, and never if it may abort, as the
+// service cannot be stopped.
+func TestPowerRolloutNotDelegated(t *testing.T) {
+	var aggregator AggregationService
+	err := json.NewDecoder(strings.NewReader(aggregationServiceBody)).Decode(&aggregator)
+	if err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	aggregatorClient := &common.TestClient{}
+	aggregator.SetClient(aggregatorClient)
+
+	system, client := testPowerSystem(t, "1", nil)
+
+	rollout := &PowerRollout{
+		ResetType:  ForceOffResetType,
+		Aggregator: &aggregator,
+	}
+	result, err := rollout.Run([]*ComputerSystem{system})
+	if err != nil {
+		t.Fatalf("Error running rollout: %s", err)
+	}
+
+	if result.Delegated || len(aggregatorClient.CapturedCalls()) != 0 {
+		t.Errorf("Expected the rollout not to be delegated: %+v", result)
+	}
+	if calls := client.CapturedCalls(); len(calls) != 1 || calls[0].Action != http.MethodPost {
+		t.Errorf("Expected the action to be sent to the system, captured: %v", calls)
+	}
+
+	rollout.Delegate = true
+	rollout.MaxFailures = 1
+	if _, err := rollout.Run([]*ComputerSystem{system}); err == nil || len(aggregatorClient.CapturedCalls()) != 0 {
+		t.Errorf("Expected a delegated rollout with MaxFailures to be rejected: %v", err)
+	}
+}
@@ -6,6 +6,9 @@ package redfish
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/stmcginnis/gofish/common"
 )
@@ -135,8 +138,202 @@ func ListReferencedPrivilegeRegistrys(c common.Client, link string) ([]*Privileg
 type TargetPrivilegeMap struct {
 	// OperationMap shall contain the mapping between the HTTP operation and the privilege required to complete the
 	// operation.
-	OperationMap string
+	OperationMap OperationMap
 	// Targets shall contain the array of URIs, Resource types, or properties. For example, '/redfish/v1/Systems/1',
 	// 'Manager', or 'Password'. When the Targets property is not present, no override is specified.
 	Targets []string
 }
+
+// Privileges returns the privileges required for an HTTP method. Each entry is
+// an alternative set of privileges that all need to be held. The second return
+// value reports whether the operation map contains the method.
+func (operationmap *OperationMap) Privileges(method string) ([]OperationPrivilege, bool) {
+	var privileges []OperationPrivilege
+	switch strings.ToUpper(method) {
+	case http.MethodDelete:
+		privileges = operationmap.DELETE
+	case http.MethodGet:
+		privileges = operationmap.GET
+	case http.MethodHead:
+		privileges = operationmap.HEAD
+	case http.MethodPatch:
+		privileges = operationmap.PATCH
+	case http.MethodPost:
+		privileges = operationmap.POST
+	case http.MethodPut:
+		privileges = operationmap.PUT
+	}
+	return privileges, privileges != nil
+}
+
+// PrivilegeRequest describes an operation to check against a privilege map.
+type PrivilegeRequest struct {
+	// Entity is the resource type of the target, such as 'ComputerSystem'.
+	Entity string
+	// Method is the HTTP method of the operation.
+	Method string
+	// URI is the URI of the target. If set, resource URI overrides apply.
+	URI string
+	// Ancestors are the resource types of the resources the target is
+	// subordinate to, from the service root down to its parent, such as
+	// 'Manager' and 'EthernetInterfaceCollection'. If set, subordinate
+	// overrides apply.
+	Ancestors []string
+	// Properties are the properties the operation modifies. If set, property
+	// overrides apply.
+	Properties []string
+	// Self indicates the target is the account itself or a resource it owns,
+	// which the ConfigureSelf privilege grants access to.
+	Self bool
+}
+
+// PrivilegeDecision is the result of evaluating a PrivilegeRequest.
+type PrivilegeDecision struct {
+	// Allowed reports whether the privileges allow the operation.
+	Allowed bool
+	// Required are the alternative privilege sets the operation on the target
+	// requires.
+	Required []OperationPrivilege
+	// Override is the kind of override that determined Required, either
+	// 'ResourceURIOverrides', 'SubordinateOverrides' or empty.
+	Override string
+	// DeniedProperties are the modified properties that the privileges do not
+	// allow the operation to change.
+	DeniedProperties []string
+}
+
+// Mapping returns the mapping for a resource type, or nil if there is none.
+func (privilegeregistry *PrivilegeRegistry) Mapping(entity string) *Mapping {
+	for i := range privilegeregistry.Mappings {
+		if privilegeregistry.Mappings[i].Entity == entity {
+			return &privilegeregistry.Mappings[i]
+		}
+	}
+	return nil
+}
+
+// containsTargets reports whether the targets of a subordinate override appear
+// in order in the ancestors of a resource.
+func containsTargets(ancestors, targets []string) bool {
+	if len(targets) == 0 {
+		return false
+	}
+	i := 0
+	for _, ancestor := range ancestors {
+		if ancestor == targets[i] {
+			i++
+			if i == len(targets) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// overridePrivileges returns the privileges of the first override matching
+// the targets that maps the method.
+func overridePrivileges(overrides []TargetPrivilegeMap, method string, matches func(targets []string) bool) ([]OperationPrivilege, bool) {
+	for i := range overrides {
+		if !matches(overrides[i].Targets) {
+			continue
+		}
+		if privileges, ok := overrides[i].OperationMap.Privileges(method); ok {
+			return privileges, true
+		}
+	}
+	return nil, false
+}
+
+// satisfies reports whether the held privileges satisfy one of the alternative
+// privilege sets.
+func satisfies(held map[string]bool, required []OperationPrivilege) bool {
+	for _, alternative := range required {
+		satisfied := true
+		for _, privilege := range alternative.Privilege {
+			if privilege != string(NoAuthPrivilegeType) && !held[privilege] {
+				satisfied = false
+				break
+			}
+		}
+		if satisfied {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate checks whether a set of Redfish and OEM privileges allows an
+// operation. Resource URI overrides take precedence over subordinate
+// overrides, which take precedence over the operation map of the resource
+// type. Each modified property with a property override must be allowed by
+// that override instead. An error is returned if the privilege map has no
+// mapping for the resource type.
+func (privilegeregistry *PrivilegeRegistry) Evaluate(privileges []string, request *PrivilegeRequest) (*PrivilegeDecision, error) {
+	mapping := privilegeregistry.Mapping(request.Entity)
+	if mapping == nil {
+		return nil, fmt.Errorf("privilege map has no mapping for %s", request.Entity)
+	}
+
+	held := make(map[string]bool, len(privileges))
+	for _, privilege := range privileges {
+		if privilege == string(ConfigureSelfPrivilegeType) && !request.Self {
+			continue
+		}
+		held[privilege] = true
+	}
+
+	decision := &PrivilegeDecision{}
+	required, _ := mapping.OperationMap.Privileges(request.Method)
+	if privileges, ok := overridePrivileges(mapping.ResourceURIOverrides, request.Method, func(targets []string) bool {
+		for _, target := range targets {
+			if request.URI != "" && strings.TrimSuffix(target, "/") == strings.TrimSuffix(request.URI, "/") {
+				return true
+			}
+		}
+		return false
+	}); ok {
+		required = privileges
+		decision.Override = "ResourceURIOverrides"
+	} else if privileges, ok := overridePrivileges(mapping.SubordinateOverrides, request.Method, func(targets []string) bool {
+		return containsTargets(request.Ancestors, targets)
+	}); ok {
+		required = privileges
+		decision.Override = "SubordinateOverrides"
+	}
+	decision.Required = required
+
+	if len(request.Properties) == 0 {
+		decision.Allowed = satisfies(held, required)
+		return decision, nil
+	}
+
+	for _, property := range request.Properties {
+		propertyRequired := required
+		if privileges, ok := overridePrivileges(mapping.PropertyOverrides, request.Method, func(targets []string) bool {
+			for _, target := range targets {
+				if target == property {
+					return true
+				}
+			}
+			return false
+		}); ok {
+			propertyRequired = privileges
+		}
+		if !satisfies(held, propertyRequired) {
+			decision.DeniedProperties = append(decision.DeniedProperties, property)
+		}
+	}
+	decision.Allowed = len(decision.DeniedProperties) == 0
+
+	return decision, nil
+}
+
+// CheckRole checks whether the privileges of a role allow an operation.
+func (privilegeregistry *PrivilegeRegistry) CheckRole(role *Role, request *PrivilegeRequest) (*PrivilegeDecision, error) {
+	privileges := make([]string, 0, len(role.AssignedPrivileges)+len(role.OemPrivileges))
+	for _, privilege := range role.AssignedPrivileges {
+		privileges = append(privileges, string(privilege))
+	}
+	privileges = append(privileges, role.OemPrivileges...)
+	return privilegeregistry.Evaluate(privileges, request)
+}
@@ -92,3 +92,100 @@ func TestPrivilegeRegistry(t *testing.T) {
 	assertEquals(t, "Login", result.Mappings[0].OperationMap.GET[0].Privilege[0])
 	assertEquals(t, "ConfigureManager", result.Mappings[0].OperationMap.DELETE[0].Privilege[0])
 }
+
+var privilegeOverridesBody = `{
+	"@odata.id": "/redfish/v1/AccountService/PrivilegeMap",
+	"Id": "PrivilegeMap",
+	"Mappings": [
+		{
+			"Entity": "ManagerAccount",
+			"OperationMap": {
+				"GET": [{"Privilege": ["ConfigureUsers"]}, {"Privilege": ["ConfigureManager"]}, {"Privilege": ["ConfigureSelf"]}],
+				"PATCH": [{"Privilege": ["ConfigureUsers"]}]
+			},
+			"PropertyOverrides": [
+				{
+					"Targets": ["Password"],
+					"OperationMap": {
+						"PATCH": [{"Privilege": ["ConfigureUsers"]}, {"Privilege": ["ConfigureSelf"]}]
+					}
+				}
+			]
+		},
+		{
+			"Entity": "EthernetInterface",
+			"OperationMap": {
+				"GET": [{"Privilege": ["Login"]}],
+				"PATCH": [{"Privilege": ["ConfigureComponents"]}]
+			},
+			"SubordinateOverrides": [
+				{
+					"Targets": ["Manager", "EthernetInterfaceCollection"],
+					"OperationMap": {
+						"PATCH": [{"Privilege": ["ConfigureManager"]}]
+					}
+				}
+			],
+			"ResourceURIOverrides": [
+				{
+					"Targets": ["/redfish/v1/Systems/1/EthernetInterfaces/1"],
+					"OperationMap": {
+						"PATCH": [{"Privilege": ["Login", "ConfigureComponents"]}]
+					}
+				}
+			]
+		},
+		{
+			"Entity": "ServiceRoot",
+			"OperationMap": {
+				"GET": [{"Privilege": ["NoAuth"]}]
+			}
+		}
+	]
+}`
+
+// TestPrivilegeRegistryEvaluate tests evaluating operations against a privilege map.
+func TestPrivilegeRegistryEvaluate(t *testing.T) {
+	var result PrivilegeRegistry
+	err := json.NewDecoder(strings.NewReader(privilegeOverridesBody)).Decode(&result)
+	if err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	operator := &Role{AssignedPrivileges: []PrivilegeType{LoginPrivilegeType, ConfigureComponentsPrivilegeType, ConfigureSelfPrivilegeType}}
+
+	tests := []struct {
+		name     string
+		request  PrivilegeRequest
+		allowed  bool
+		override string
+	}{
+		{"own password", PrivilegeRequest{Entity: "ManagerAccount", Method: "PATCH", Properties: []string{"Password"}, Self: true}, true, ""},
+		{"other password", PrivilegeRequest{Entity: "ManagerAccount", Method: "PATCH", Properties: []string{"Password"}}, false, ""},
+		{"own role", PrivilegeRequest{Entity: "ManagerAccount", Method: "PATCH", Properties: []string{"Password", "RoleId"}, Self: true}, false, ""},
+		{"system interface", PrivilegeRequest{Entity: "EthernetInterface", Method: "PATCH", Ancestors: []string{"ComputerSystem", "EthernetInterfaceCollection"}}, true, ""},
+		{"manager interface", PrivilegeRequest{Entity: "EthernetInterface", Method: "patch", Ancestors: []string{"ServiceRoot", "Manager", "EthernetInterfaceCollection"}}, false, "SubordinateOverrides"},
+		{"uri override", PrivilegeRequest{Entity: "EthernetInterface", Method: "PATCH", URI: "/redfish/v1/Systems/1/EthernetInterfaces/1/", Ancestors: []string{"Manager", "EthernetInterfaceCollection"}}, true, "ResourceURIOverrides"},
+		{"no auth", PrivilegeRequest{Entity: "ServiceRoot", Method: "GET"}, true, ""},
+		{"unmapped method", PrivilegeRequest{Entity: "ServiceRoot", Method: "DELETE"}, false, ""},
+	}
+
+	for _, test := range tests {
+		decision, err := result.CheckRole(operator, &test.request)
+		if err != nil {
+			t.Fatalf("%s: error evaluating privileges: %s", test.name, err)
+		}
+		if decision.Allowed != test.allowed || decision.Override != test.override {
+			t.Errorf("%s: unexpected decision: %+v", test.name, decision)
+		}
+	}
+
+	decision, _ := result.CheckRole(operator, &PrivilegeRequest{Entity: "ManagerAccount", Method: "PATCH", Properties: []string{"Password", "RoleId"}, Self: true})
+	if len(decision.DeniedProperties) != 1 || decision.DeniedProperties[0] != "RoleId" {
+		t.Errorf("Unexpected denied properties: %v", decision.DeniedProperties)
+	}
+
+	if _, err := result.Evaluate(nil, &PrivilegeRequest{Entity: "Chassis", Method: "GET"}); err == nil {
+		t.Error("Expected an error for an unmapped resource type")
+	}
+}
@@ -0,0 +1,310 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// defaultResetTimeout is the time to wait for a reset type to reach the
+// expected power state if none is configured.
+const defaultResetTimeout = 5 * time.Minute
+
+// resetFallbacks are the reset types to try, in order, if a reset type is not
+// supported or does not reach the expected power state.
+var resetFallbacks = map[ResetType][]ResetType{
+	GracefulRestartResetType:  {ForceRestartResetType, PowerCycleResetType},
+	GracefulShutdownResetType: {ForceOffResetType},
+	ForceRestartResetType:     {PowerCycleResetType},
+	OnResetType:               {ForceOnResetType},
+}
+
+// isRestart reports whether a reset type ends in the power state the resource
+// started in, so reaching the power state alone does not show it happened.
+func isRestart(resetType ResetType) bool {
+	return resetType == GracefulRestartResetType || resetType == ForceRestartResetType || resetType == PowerCycleResetType
+}
+
+// ResetTypeChain returns the reset types to try for a requested reset type,
+// in order. Types that are not in the supported reset types are left out; if
+// no supported types are given, all types are assumed to be supported. If
+// fallback is false, the chain only contains the requested type.
+func ResetTypeChain(requested ResetType, supported []ResetType, fallback bool) ([]ResetType, error) {
+	chain := []ResetType{requested}
+	if fallback {
+		chain = append(chain, resetFallbacks[requested]...)
+	}
+	if len(supported) == 0 {
+		return chain, nil
+	}
+
+	var result []ResetType
+	for _, resetType := range chain {
+		for _, s := range supported {
+			if resetType == s {
+				result = append(result, resetType)
+				break
+			}
+		}
+	}
+	if len(result) == 0 {
+		names := make([]string, 0, len(supported))
+		for _, s := range supported {
+			names = append(names, string(s))
+		}
+		return nil, fmt.Errorf("reset type '%s' is not supported, supported types are: %s", requested, strings.Join(names, ", "))
+	}
+	return result, nil
+}
+
+// ResetOptions configures a reset that waits for the expected power state.
+type ResetOptions struct {
+	// Timeout is the time to wait for each reset type to reach the expected
+	// power state before falling back to the next one. The default is five
+	// minutes.
+	Timeout time.Duration
+	// PollInterval is the interval between reads of the power state. The
+	// default is five seconds. Restarts are detected by a change of the power
+	// state or of LastResetTime, or by the service being unreachable, so the
+	// interval should be shorter than a restart of the resource.
+	PollInterval time.Duration
+	// DisableFallback only sends the requested reset type.
+	DisableFallback bool
+}
+
+// ResetAttempt is the result of sending one reset type.
+type ResetAttempt struct {
+	// ResetType is the reset type that was sent.
+	ResetType ResetType
+	// Error is the error returned by the service, or the reason the
+	// expected power state was not reached.
+	Error error
+	// PowerState is the last power state read after the reset.
+	PowerState PowerState
+	// TransitionObserved is true if a restart was seen to happen.
+	TransitionObserved bool
+	// Reached is true if the expected power state was reached.
+	Reached bool
+	// Duration is the time from sending the reset until waiting stopped.
+	Duration time.Duration
+}
+
+// ResetOutcome is the result of a reset that waits for the expected power
+// state.
+type ResetOutcome struct {
+	// Requested is the reset type that was requested.
+	Requested ResetType
+	// Applied is the reset type that reached the expected power state, or
+	// the last one sent if none did.
+	Applied ResetType
+	// TargetPowerState is the power state expected after the reset. It is
+	// empty if the reset type does not lead to a known power state.
+	TargetPowerState PowerState
+	// InitialPowerState is the power state before the reset.
+	InitialPowerState PowerState
+	// PowerState is the last power state read.
+	PowerState PowerState
+	// Verified is true if the expected power state was observed.
+	Verified bool
+	// Attempts are the reset types that were sent, in order.
+	Attempts []ResetAttempt
+	// Duration is the time the reset took.
+	Duration time.Duration
+}
+
+// resettable is a resource that can be reset and reports its power state.
+type resettable struct {
+	name      string
+	supported []ResetType
+	reset     func(ResetType) error
+	// read returns the current power state and time of the last reset.
+	read         func() (PowerState, string, error)
+	initialState PowerState
+}
+
+// resetAndWait resets a resource with the chain of reset types for the
+// requested type until one reaches the expected power state.
+func resetAndWait(target *resettable, requested ResetType, options *ResetOptions) (*ResetOutcome, error) {
+	if options == nil {
+		options = &ResetOptions{}
+	}
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = defaultResetTimeout
+	}
+	interval := options.PollInterval
+	if interval <= 0 {
+		interval = defaultPowerPollInterval
+	}
+
+	chain, err := ResetTypeChain(requested, target.supported, !options.DisableFallback)
+	if err != nil {
+		return nil, err
+	}
+
+	started := time.Now()
+	outcome := &ResetOutcome{Requested: requested, InitialPowerState: target.initialState, PowerState: target.initialState}
+	outcome.TargetPowerState, _ = ExpectedPowerState(requested)
+	for _, resetType := range chain {
+		attempt := waitForReset(target, resetType, timeout, interval)
+		outcome.Attempts = append(outcome.Attempts, attempt)
+		outcome.Applied = resetType
+		if attempt.PowerState != "" {
+			outcome.PowerState = attempt.PowerState
+		}
+
+		if attempt.Error == nil {
+			outcome.Verified = attempt.Reached
+			outcome.Duration = time.Since(started)
+			return outcome, nil
+		}
+	}
+
+	outcome.Duration = time.Since(started)
+	last := outcome.Attempts[len(outcome.Attempts)-1]
+	return outcome, fmt.Errorf("reset of %s failed: %w", target.name, last.Error)
+}
+
+// waitForReset sends a reset type and waits for the expected power state.
+func waitForReset(target *resettable, resetType ResetType, timeout, interval time.Duration) ResetAttempt {
+	_, lastReset, _ := target.read()
+
+	started := time.Now()
+	if err := target.reset(resetType); err != nil {
+		return ResetAttempt{ResetType: resetType, Error: err, Duration: time.Since(started)}
+	}
+
+	attempt := waitForPowerState(target.read, resetType, lastReset, timeout, interval)
+	attempt.Duration = time.Since(started)
+	return attempt
+}
+
+// waitForPowerState polls a resource after a reset was sent until it reaches
+// the power state expected after the reset. A restart ends in the power state
+// the resource started in, so it is only accepted once the restart was
+// observed through a change of the power state, a LastResetTime other than
+// lastReset, or the resource being unreachable.
+func waitForPowerState(read func() (PowerState, string, error), resetType ResetType, lastReset string, timeout, interval time.Duration) (attempt ResetAttempt) {
+	attempt.ResetType = resetType
+	expected, ok := ExpectedPowerState(resetType)
+	if !ok {
+		// Nothing to wait for
+		return attempt
+	}
+	if interval <= 0 {
+		interval = defaultPowerPollInterval
+	}
+	restart := isRestart(resetType)
+
+	deadline := time.Now().Add(timeout)
+	for {
+		state, resetTime, err := read()
+		if err != nil {
+			// Services are commonly unreachable while they restart
+			if restart {
+				attempt.TransitionObserved = true
+			}
+		} else {
+			attempt.PowerState = state
+			if (state != "" && state != expected) || (resetTime != "" && resetTime != lastReset) {
+				attempt.TransitionObserved = true
+			}
+
+			// Without a power state, only a restart can be observed
+			if state == "" && !restart {
+				return attempt
+			}
+			if (state == expected || state == "") && (!restart || attempt.TransitionObserved) {
+				attempt.Reached = state == expected
+				return attempt
+			}
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			switch {
+			case err != nil:
+				attempt.Error = fmt.Errorf("power state could not be read after %s: %w", resetType, err)
+			case restart && state == expected:
+				attempt.Error = fmt.Errorf("no restart was observed within %s of %s", timeout, resetType)
+			default:
+				attempt.Error = fmt.Errorf("power state is %s rather than %s after %s of %s", state, expected, timeout, resetType)
+			}
+			return attempt
+		}
+		time.Sleep(interval)
+	}
+}
+
+// systemPowerReader returns a function that reads the power state and time of
+// the last reset of a system.
+func systemPowerReader(system *ComputerSystem) func() (PowerState, string, error) {
+	return func() (PowerState, string, error) {
+		current, err := GetComputerSystem(system.GetClient(), system.ODataID)
+		if err != nil {
+			return "", "", err
+		}
+		return current.PowerState, current.LastResetTime, nil
+	}
+}
+
+// ResetAndWait resets the system and waits until it reaches the power state
+// expected after the reset. The reset type is checked against the supported
+// reset types, and if the system does not reach the expected power state in
+// time, the next reset type of the fallback chain is tried, such as
+// ForceRestart after GracefulRestart and ForceOff after GracefulShutdown. The
+// outcome is returned along with the error if no reset type succeeded.
+func (computersystem *ComputerSystem) ResetAndWait(resetType ResetType, options *ResetOptions) (*ResetOutcome, error) {
+	return resetAndWait(&resettable{
+		name:         "system " + computersystem.ID,
+		supported:    computersystem.SupportedResetTypes,
+		reset:        computersystem.Reset,
+		initialState: computersystem.PowerState,
+		read:         systemPowerReader(computersystem),
+	}, resetType, options)
+}
+
+// ResetAndWait resets the chassis and waits until it reaches the power state
+// expected after the reset, falling back to other reset types like
+// ComputerSystem.ResetAndWait. Chassis do not report a LastResetTime, so a
+// restart is only observed through the power state changing, such as to Off
+// or PoweringOn, or the service being unreachable. A restart that completes
+// within the PollInterval is not observed and is reported as failed.
+func (chassis *Chassis) ResetAndWait(resetType ResetType, options *ResetOptions) (*ResetOutcome, error) {
+	return resetAndWait(&resettable{
+		name:         "chassis " + chassis.ID,
+		supported:    chassis.SupportedResetTypes,
+		reset:        chassis.Reset,
+		initialState: chassis.PowerState,
+		read: func() (PowerState, string, error) {
+			current, err := GetChassis(chassis.GetClient(), chassis.ODataID)
+			if err != nil {
+				return "", "", err
+			}
+			return current.PowerState, "", nil
+		},
+	}, resetType, options)
+}
+
+// ResetAndWait resets the manager and waits until it is back in the power
+// state expected after the reset, falling back to other reset types like
+// ComputerSystem.ResetAndWait. A restart of a manager is observed through its
+// LastResetTime or the service being unreachable.
+func (manager *Manager) ResetAndWait(resetType ResetType, options *ResetOptions) (*ResetOutcome, error) {
+	return resetAndWait(&resettable{
+		name:         "manager " + manager.ID,
+		supported:    manager.SupportedResetTypes,
+		reset:        manager.Reset,
+		initialState: manager.PowerState,
+		read: func() (PowerState, string, error) {
+			current, err := GetManager(manager.GetClient(), manager.ODataID)
+			if err != nil {
+				return "", "", err
+			}
+			return current.PowerState, current.LastResetTime, nil
+		},
+	}, resetType, options)
+}
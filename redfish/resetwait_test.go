@@ -0,0 +1,162 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+// powerStateResponder simulates the power state of a resource. Each reset
+// type queues the power states the following reads return; the last one
+// remains the power state.
+type powerStateResponder struct {
+	*common.TestClient
+	body string
+
+	mu      sync.Mutex
+	state   PowerState
+	queued  []PowerState
+	effects map[ResetType][]PowerState
+	resets  []ResetType
+}
+
+func (responder *powerStateResponder) Get(url string) (*http.Response, error) {
+	responder.mu.Lock()
+	defer responder.mu.Unlock()
+
+	if len(responder.queued) > 0 {
+		responder.state = responder.queued[0]
+		responder.queued = responder.queued[1:]
+	}
+	return getCall(fmt.Sprintf(responder.body, responder.state)), nil
+}
+
+func (responder *powerStateResponder) PostWithHeaders(url string, payload interface{}, customHeaders map[string]string) (*http.Response, error) {
+	responder.mu.Lock()
+	defer responder.mu.Unlock()
+
+	var request struct{ ResetType ResetType }
+	b, _ := json.Marshal(payload)
+	_ = json.Unmarshal(b, &request)
+
+	responder.resets = append(responder.resets, request.ResetType)
+	responder.queued = append([]PowerState{}, responder.effects[request.ResetType]...)
+	return getCall(""), nil
+}
+
+var resetSystemBody = `{
+		"@odata.id": "/redfish/v1/Systems/1",
+		"Id": "1",
+		"PowerState": "%s",
+		"Actions": {
+			"#ComputerSystem.Reset": {
+				"target": "/redfish/v1/Systems/1/Actions/ComputerSystem.Reset",
+				"ResetType@Redfish.AllowableValues": ["On", "ForceOff", "GracefulShutdown", "GracefulRestart", "ForceRestart"]
+			}
+		}
+	}`
+
+func testResetSystem(t *testing.T, state PowerState, effects map[ResetType][]PowerState) (*ComputerSystem, *powerStateResponder) {
+	var system ComputerSystem
+	err := json.NewDecoder(strings.NewReader(fmt.Sprintf(resetSystemBody, state))).Decode(&system)
+	if err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	responder := &powerStateResponder{TestClient: &common.TestClient{}, body: resetSystemBody, state: state, effects: effects}
+	system.SetClient(responder)
+	return &system, responder
+}
+
+// TestResetTypeChain tests negotiating reset types against the supported types.
+func TestResetTypeChain(t *testing.T) {
+	chain, err := ResetTypeChain(GracefulRestartResetType, []ResetType{OnResetType, ForceRestartResetType}, true)
+	if err != nil {
+		t.Fatalf("Error making ResetTypeChain call: %s", err)
+	}
+	if len(chain) != 1 || chain[0] != ForceRestartResetType {
+		t.Errorf("Unexpected chain: %v", chain)
+	}
+
+	chain, _ = ResetTypeChain(GracefulShutdownResetType, nil, true)
+	if len(chain) != 2 || chain[1] != ForceOffResetType {
+		t.Errorf("Unexpected chain: %v", chain)
+	}
+
+	if _, err := ResetTypeChain(GracefulRestartResetType, []ResetType{ForceRestartResetType}, false); err == nil {
+		t.Error("Expected an unsupported reset type without fallback to fail")
+	}
+}
+
+// TestComputerSystemResetAndWaitFallback tests falling back to ForceOff when a
+// graceful shutdown does not power off the system.
+func TestComputerSystemResetAndWaitFallback(t *testing.T) {
+	system, responder := testResetSystem(t, OnPowerState, map[ResetType][]PowerState{
+		ForceOffResetType: {PoweringOffPowerState, OffPowerState},
+	})
+
+	outcome, err := system.ResetAndWait(GracefulShutdownResetType, &ResetOptions{
+		Timeout:      10 * time.Millisecond,
+		PollInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Error making ResetAndWait call: %s", err)
+	}
+
+	if outcome.Applied != ForceOffResetType || !outcome.Verified || outcome.PowerState != OffPowerState ||
+		outcome.TargetPowerState != OffPowerState || outcome.InitialPowerState != OnPowerState {
+		t.Errorf("Unexpected outcome: %+v", outcome)
+	}
+	if len(outcome.Attempts) != 2 || outcome.Attempts[0].Error == nil || outcome.Attempts[0].PowerState != OnPowerState {
+		t.Errorf("Unexpected attempts: %+v", outcome.Attempts)
+	}
+	if len(responder.resets) != 2 || responder.resets[0] != GracefulShutdownResetType {
+		t.Errorf("Unexpected resets: %v", responder.resets)
+	}
+}
+
+// TestComputerSystemResetAndWaitRestart tests that a restart is only verified
+// once the system was seen to restart.
+func TestComputerSystemResetAndWaitRestart(t *testing.T) {
+	system, responder := testResetSystem(t, OnPowerState, map[ResetType][]PowerState{
+		GracefulRestartResetType: {OnPowerState, OnPowerState, PoweringOffPowerState, OffPowerState, PoweringOnPowerState, OnPowerState},
+	})
+
+	outcome, err := system.ResetAndWait(GracefulRestartResetType, &ResetOptions{
+		Timeout:      time.Second,
+		PollInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Error making ResetAndWait call: %s", err)
+	}
+
+	if outcome.Applied != GracefulRestartResetType || !outcome.Verified || !outcome.Attempts[0].TransitionObserved {
+		t.Errorf("Unexpected outcome: %+v", outcome)
+	}
+	if len(responder.resets) != 1 || len(responder.queued) != 0 {
+		t.Errorf("Unexpected resets: %v, unread states: %v", responder.resets, responder.queued)
+	}
+
+	// A system that never restarts falls back to ForceRestart, which fails
+	system, responder = testResetSystem(t, OnPowerState, nil)
+	outcome, err = system.ResetAndWait(GracefulRestartResetType, &ResetOptions{
+		Timeout:      5 * time.Millisecond,
+		PollInterval: time.Millisecond,
+	})
+	if err == nil || outcome.Verified || len(outcome.Attempts) != 2 {
+		t.Errorf("Expected the restart to fail, got: %+v %v", outcome, err)
+	}
+	if len(responder.resets) != 2 || responder.resets[1] != ForceRestartResetType {
+		t.Errorf("Unexpected resets: %v", responder.resets)
+	}
+}
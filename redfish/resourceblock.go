@@ -285,20 +285,8 @@ func (resourceblock *ResourceBlock) Update() error {
 
 // GetResourceBlock will get a ResourceBlock instance from the service.
 func GetResourceBlock(c common.Client, uri string) (*ResourceBlock, error) {
-	resp, err := c.Get(uri)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
 	var resourceblock ResourceBlock
-	err = json.NewDecoder(resp.Body).Decode(&resourceblock)
-	if err != nil {
-		return nil, err
-	}
-
-	resourceblock.SetClient(c)
-	return &resourceblock, nil
+	return &resourceblock, resourceblock.Get(c, uri, &resourceblock)
 }
 
 // ListReferencedResourceBlocks gets the collection of ResourceBlock from
@@ -345,6 +333,153 @@ func ListReferencedResourceBlocks(c common.Client, link string) ([]*ResourceBloc
 	return result, collectionError
 }
 
+// ComputerSystems gets the computer systems of this resource block.
+func (resourceblock *ResourceBlock) ComputerSystems() ([]*ComputerSystem, error) {
+	var result []*ComputerSystem
+
+	collectionError := common.NewCollectionError()
+	for _, uri := range resourceblock.computerSystems {
+		item, err := GetComputerSystem(resourceblock.GetClient(), uri)
+		if err != nil {
+			collectionError.Failures[uri] = err
+		} else {
+			result = append(result, item)
+		}
+	}
+
+	if collectionError.Empty() {
+		return result, nil
+	}
+
+	return result, collectionError
+}
+
+// Drives gets the drives of this resource block.
+func (resourceblock *ResourceBlock) Drives() ([]*Drive, error) {
+	var result []*Drive
+
+	collectionError := common.NewCollectionError()
+	for _, uri := range resourceblock.drives {
+		item, err := GetDrive(resourceblock.GetClient(), uri)
+		if err != nil {
+			collectionError.Failures[uri] = err
+		} else {
+			result = append(result, item)
+		}
+	}
+
+	if collectionError.Empty() {
+		return result, nil
+	}
+
+	return result, collectionError
+}
+
+// EthernetInterfaces gets the Ethernet interfaces of this resource block.
+func (resourceblock *ResourceBlock) EthernetInterfaces() ([]*EthernetInterface, error) {
+	var result []*EthernetInterface
+
+	collectionError := common.NewCollectionError()
+	for _, uri := range resourceblock.ethernetInterfaces {
+		item, err := GetEthernetInterface(resourceblock.GetClient(), uri)
+		if err != nil {
+			collectionError.Failures[uri] = err
+		} else {
+			result = append(result, item)
+		}
+	}
+
+	if collectionError.Empty() {
+		return result, nil
+	}
+
+	return result, collectionError
+}
+
+// Memory gets the memory devices of this resource block.
+func (resourceblock *ResourceBlock) Memory() ([]*Memory, error) {
+	var result []*Memory
+
+	collectionError := common.NewCollectionError()
+	for _, uri := range resourceblock.memory {
+		item, err := GetMemory(resourceblock.GetClient(), uri)
+		if err != nil {
+			collectionError.Failures[uri] = err
+		} else {
+			result = append(result, item)
+		}
+	}
+
+	if collectionError.Empty() {
+		return result, nil
+	}
+
+	return result, collectionError
+}
+
+// NetworkInterfaces gets the network interfaces of this resource block.
+func (resourceblock *ResourceBlock) NetworkInterfaces() ([]*NetworkInterface, error) {
+	var result []*NetworkInterface
+
+	collectionError := common.NewCollectionError()
+	for _, uri := range resourceblock.networkInterfaces {
+		item, err := GetNetworkInterface(resourceblock.GetClient(), uri)
+		if err != nil {
+			collectionError.Failures[uri] = err
+		} else {
+			result = append(result, item)
+		}
+	}
+
+	if collectionError.Empty() {
+		return result, nil
+	}
+
+	return result, collectionError
+}
+
+// Processors gets the processors of this resource block.
+func (resourceblock *ResourceBlock) Processors() ([]*Processor, error) {
+	var result []*Processor
+
+	collectionError := common.NewCollectionError()
+	for _, uri := range resourceblock.processors {
+		item, err := GetProcessor(resourceblock.GetClient(), uri)
+		if err != nil {
+			collectionError.Failures[uri] = err
+		} else {
+			result = append(result, item)
+		}
+	}
+
+	if collectionError.Empty() {
+		return result, nil
+	}
+
+	return result, collectionError
+}
+
+// Storage gets the storage subsystems of this resource block.
+func (resourceblock *ResourceBlock) Storage() ([]*Storage, error) {
+	var result []*Storage
+
+	collectionError := common.NewCollectionError()
+	for _, uri := range resourceblock.storage {
+		item, err := GetStorage(resourceblock.GetClient(), uri)
+		if err != nil {
+			collectionError.Failures[uri] = err
+		} else {
+			result = append(result, item)
+		}
+	}
+
+	if collectionError.Empty() {
+		return result, nil
+	}
+
+	return result, collectionError
+}
+
 // ResourceBlockLimits shall specify the allowable quantities of types of resource blocks for a given composition
 // request.
 type ResourceBlockLimits struct {
@@ -5,7 +5,14 @@
 package redfish
 
 import (
+	"bytes"
+	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/stmcginnis/gofish/common"
 )
@@ -85,6 +92,213 @@ func (securebootdatabase *SecureBootDatabase) Signatures() ([]*Signature, error)
 	return ListReferencedSignatures(securebootdatabase.GetClient(), securebootdatabase.signatures)
 }
 
+// EnrollCertificate adds a PEM or DER encoded X.509 certificate to this UEFI
+// Secure Boot database. The owner is the GUID of the signature owner and may
+// be empty. Services that process the request asynchronously return a task
+// instead of the certificate.
+func (securebootdatabase *SecureBootDatabase) EnrollCertificate(certificate []byte, owner string) (*Certificate, *Task, error) {
+	if securebootdatabase.certificates == "" {
+		return nil, nil, errors.New("certificates are not supported by this secure boot database")
+	}
+
+	if block, _ := pem.Decode(certificate); block == nil {
+		if _, err := x509.ParseCertificate(certificate); err != nil {
+			return nil, nil, err
+		}
+		certificate = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate})
+	}
+
+	payload := struct {
+		CertificateString  string
+		CertificateType    CertificateType
+		UefiSignatureOwner string `json:",omitempty"`
+	}{
+		CertificateString:  string(certificate),
+		CertificateType:    PEMCertificateType,
+		UefiSignatureOwner: owner,
+	}
+
+	var result Certificate
+	task, err := CreateResource(securebootdatabase.GetClient(), securebootdatabase.certificates, &payload, &result)
+	if err != nil || task != nil {
+		return nil, task, err
+	}
+	result.SetClient(securebootdatabase.GetClient())
+	return &result, nil, nil
+}
+
+// RemoveCertificate deletes a certificate from this UEFI Secure Boot
+// database, conditional on the etag of the certificate.
+func (securebootdatabase *SecureBootDatabase) RemoveCertificate(certificate *Certificate) (*Task, error) {
+	return DeleteEntity(securebootdatabase.GetClient(), &certificate.Entity)
+}
+
+// EnrollSignature adds a signature from the UEFI registry to this UEFI Secure
+// Boot database. The signature type is the UEFI name of the type, such as
+// "EFI_CERT_SHA256_GUID", and the owner is the GUID of the signature owner and
+// may be empty.
+func (securebootdatabase *SecureBootDatabase) EnrollSignature(signatureType, signatureString, owner string) (*Signature, *Task, error) {
+	if securebootdatabase.signatures == "" {
+		return nil, nil, errors.New("signatures are not supported by this secure boot database")
+	}
+
+	payload := struct {
+		SignatureString       string
+		SignatureType         string
+		SignatureTypeRegistry SignatureTypeRegistry
+		UefiSignatureOwner    string `json:",omitempty"`
+	}{
+		SignatureString:       signatureString,
+		SignatureType:         signatureType,
+		SignatureTypeRegistry: UEFISignatureTypeRegistry,
+		UefiSignatureOwner:    owner,
+	}
+
+	var result Signature
+	task, err := CreateResource(securebootdatabase.GetClient(), securebootdatabase.signatures, &payload, &result)
+	if err != nil || task != nil {
+		return nil, task, err
+	}
+	result.SetClient(securebootdatabase.GetClient())
+	return &result, nil, nil
+}
+
+// efiHashSizes are the sizes of the hashes of the hash signature types.
+var efiHashSizes = map[EFIGUID]int{
+	EFICertSHA1GUID:   20,
+	EFICertSHA256GUID: 32,
+	EFICertSHA384GUID: 48,
+	EFICertSHA512GUID: 64,
+}
+
+// EnrollHash adds a hash, such as the SHA-256 hash of a revoked bootloader, to
+// this UEFI Secure Boot database. The signature type must be one of the SHA
+// hash types, such as EFICertSHA256GUID.
+func (securebootdatabase *SecureBootDatabase) EnrollHash(signatureType EFIGUID, hash []byte, owner string) (*Signature, *Task, error) {
+	size, ok := efiHashSizes[signatureType]
+	if !ok {
+		return nil, nil, fmt.Errorf("signature type %s is not a hash", signatureType)
+	}
+	if len(hash) != size {
+		return nil, nil, fmt.Errorf("%s hash must be %d bytes, got %d", EFISignatureTypeName(signatureType), size, len(hash))
+	}
+	return securebootdatabase.EnrollSignature(EFISignatureTypeName(signatureType), strings.ToUpper(hex.EncodeToString(hash)), owner)
+}
+
+// RemoveSignature deletes a signature from this UEFI Secure Boot database,
+// conditional on the etag of the signature.
+func (securebootdatabase *SecureBootDatabase) RemoveSignature(signature *Signature) (*Task, error) {
+	return DeleteEntity(securebootdatabase.GetClient(), &signature.Entity)
+}
+
+// SignatureListUpdate is the outcome of applying EFI signature lists to a UEFI
+// Secure Boot database.
+type SignatureListUpdate struct {
+	// Enrolled is the number of entries added to the database.
+	Enrolled int
+	// Skipped is the number of entries that were already in the database.
+	Skipped int
+	// Tasks are the tasks of entries the service enrolls asynchronously.
+	Tasks []*Task
+	// Errors are the errors of entries that could not be enrolled.
+	Errors []error
+}
+
+// ApplySignatureLists enrolls the entries of EFI signature lists, such as those
+// returned by ParseEFISignatureFile for a dbxupdate.bin, in this UEFI Secure
+// Boot database. X.509 certificates are enrolled as certificates and all other
+// entries as signatures. Entries already in the database are skipped, so
+// applying the same update again has no effect. Failing entries do not stop
+// the update; an error is returned if any entry failed.
+func (securebootdatabase *SecureBootDatabase) ApplySignatureLists(lists []EFISignatureList) (*SignatureListUpdate, error) {
+	existing, certificates, err := securebootdatabase.existingEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SignatureListUpdate{}
+	enrolled := func(task *Task, err error) {
+		switch {
+		case err != nil:
+			result.Errors = append(result.Errors, err)
+		case task != nil:
+			result.Tasks = append(result.Tasks, task)
+		default:
+			result.Enrolled++
+		}
+	}
+
+	for _, list := range lists {
+		name := EFISignatureTypeName(list.Type)
+		for _, entry := range list.Signatures {
+			if list.Type == EFICertX509GUID {
+				if containsCertificate(certificates, entry.Data) {
+					result.Skipped++
+					continue
+				}
+				_, task, err := securebootdatabase.EnrollCertificate(entry.Data, entry.Owner.String())
+				enrolled(task, err)
+				continue
+			}
+
+			if name == "" {
+				result.Errors = append(result.Errors, fmt.Errorf("unsupported signature type %s", list.Type))
+				continue
+			}
+
+			signature := strings.ToUpper(hex.EncodeToString(entry.Data))
+			if existing[name+":"+signature] {
+				result.Skipped++
+				continue
+			}
+			_, task, err := securebootdatabase.EnrollSignature(name, signature, entry.Owner.String())
+			enrolled(task, err)
+		}
+	}
+
+	if len(result.Errors) > 0 {
+		return result, fmt.Errorf("%d entries could not be enrolled: %w", len(result.Errors), result.Errors[0])
+	}
+	return result, nil
+}
+
+// existingEntries returns the signatures of this database keyed by type and
+// upper case value, and the DER encoding of its certificates.
+func (securebootdatabase *SecureBootDatabase) existingEntries() (map[string]bool, [][]byte, error) {
+	signatures, err := securebootdatabase.Signatures()
+	if err != nil {
+		return nil, nil, err
+	}
+	existing := make(map[string]bool, len(signatures))
+	for _, signature := range signatures {
+		existing[signature.SignatureType+":"+strings.ToUpper(signature.SignatureString)] = true
+	}
+
+	certificates, err := securebootdatabase.Certificates()
+	if err != nil {
+		return nil, nil, err
+	}
+	var encoded [][]byte
+	for _, certificate := range certificates {
+		// Certificates that cannot be parsed can never match an entry
+		parsed, _ := ParseCertificateString(certificate.CertificateString)
+		for _, cert := range parsed {
+			encoded = append(encoded, cert.Raw)
+		}
+	}
+
+	return existing, encoded, nil
+}
+
+func containsCertificate(certificates [][]byte, der []byte) bool {
+	for _, certificate := range certificates {
+		if bytes.Equal(certificate, der) {
+			return true
+		}
+	}
+	return false
+}
+
 // ResetKeys will perform a reset of this UEFI Secure Boot key database. The `ResetAllKeysToDefault`
 // value shall reset this UEFI Secure Boot key database to the default values. The `DeleteAllKeys`
 // value shall delete the contents of this UEFI Secure Boot key database.
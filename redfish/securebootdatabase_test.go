@@ -5,7 +5,9 @@
 package redfish
 
 import (
+	"bytes"
 	"encoding/json"
+	"net/http"
 	"strings"
 	"testing"
 
@@ -75,3 +77,148 @@ func TestSecureBootDatabaseResetKeys(t *testing.T) {
 		t.Errorf("Expected reset type not found in payload: %s", calls[0].Payload)
 	}
 }
+
+var dbBody = `{
+	"@odata.id": "/redfish/v1/Systems/1/SecureBoot/SecureBootDatabases/dbx",
+	"Id": "dbx",
+	"DatabaseId": "dbx",
+	"Certificates": {
+		"@odata.id": "/redfish/v1/Systems/1/SecureBoot/SecureBootDatabases/dbx/Certificates"
+	},
+	"Signatures": {
+		"@odata.id": "/redfish/v1/Systems/1/SecureBoot/SecureBootDatabases/dbx/Signatures"
+	}
+}`
+
+// TestSecureBootDatabaseApplySignatureLists tests enrolling the entries of a dbx update.
+func TestSecureBootDatabaseApplySignatureLists(t *testing.T) {
+	var result SecureBootDatabase
+	err := json.NewDecoder(strings.NewReader(dbBody)).Decode(&result)
+	if err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	ca, err := NewLocalCA("Revoked")
+	if err != nil {
+		t.Fatalf("Error creating CA: %s", err)
+	}
+
+	update := testAuthenticatedVariable(
+		testSignatureList(EFICertSHA256GUID, bytes.Repeat([]byte{0xAA}, 32), bytes.Repeat([]byte{0xBB}, 32)),
+		testSignatureList(EFICertX509GUID, ca.Certificate.Raw),
+	)
+
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {
+				getCall(`{"Members": [{"@odata.id": "/redfish/v1/Systems/1/SecureBoot/SecureBootDatabases/dbx/Signatures/1"}]}`),
+				getCall(`{"@odata.id": "/redfish/v1/Systems/1/SecureBoot/SecureBootDatabases/dbx/Signatures/1",
+					"SignatureType": "EFI_CERT_SHA256_GUID",
+					"SignatureString": "` + strings.Repeat("aa", 32) + `"}`),
+				getCall(`{"Members": []}`),
+			},
+			http.MethodPost: {
+				getCall(`{"@odata.id": "/redfish/v1/Systems/1/SecureBoot/SecureBootDatabases/dbx/Signatures/2"}`),
+				getCall(`{"@odata.id": "/redfish/v1/Systems/1/SecureBoot/SecureBootDatabases/dbx/Certificates/1"}`),
+			},
+		},
+	}
+	result.SetClient(testClient)
+
+	lists, err := ParseEFISignatureFile(update)
+	if err != nil {
+		t.Fatalf("Error parsing update: %s", err)
+	}
+
+	applied, err := result.ApplySignatureLists(lists)
+	if err != nil {
+		t.Fatalf("Error making ApplySignatureLists call: %s", err)
+	}
+
+	if applied.Enrolled != 2 || applied.Skipped != 1 {
+		t.Errorf("Unexpected update result: %+v", applied)
+	}
+
+	calls := testClient.CapturedCalls()
+	if len(calls) != 5 {
+		t.Fatalf("Unexpected calls: %v", calls)
+	}
+
+	if calls[3].URL != "/redfish/v1/Systems/1/SecureBoot/SecureBootDatabases/dbx/Signatures" ||
+		!strings.Contains(calls[3].Payload, "SignatureString:"+strings.Repeat("BB", 32)) ||
+		!strings.Contains(calls[3].Payload, "UefiSignatureOwner:77fa9abd-0359-4d32-bd60-28f4e78f784b") {
+		t.Errorf("Unexpected signature enrollment: %v", calls[3])
+	}
+
+	if calls[4].URL != "/redfish/v1/Systems/1/SecureBoot/SecureBootDatabases/dbx/Certificates" ||
+		!strings.Contains(calls[4].Payload, "BEGIN CERTIFICATE") {
+		t.Errorf("Unexpected certificate enrollment: %v", calls[4])
+	}
+}
+
+// TestSecureBootDatabaseRemoveSignature tests deleting a signature from a database.
+func TestSecureBootDatabaseRemoveSignature(t *testing.T) {
+	var result SecureBootDatabase
+	err := json.NewDecoder(strings.NewReader(dbBody)).Decode(&result)
+	if err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	signatureResp := getCall(`{"@odata.id": "/redfish/v1/Systems/1/SecureBoot/SecureBootDatabases/dbx/Signatures/1", "Id": "1"}`)
+	signatureResp.Header.Set("ETag", `"1"`)
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {signatureResp},
+		},
+	}
+	result.SetClient(testClient)
+
+	signature, err := GetSignature(testClient, "/redfish/v1/Systems/1/SecureBoot/SecureBootDatabases/dbx/Signatures/1")
+	if err != nil {
+		t.Fatalf("Error making GetSignature call: %s", err)
+	}
+	signature.StripEtagQuotes(true)
+
+	if _, err := result.RemoveSignature(signature); err != nil {
+		t.Fatalf("Error making RemoveSignature call: %s", err)
+	}
+
+	calls := testClient.CapturedCalls()
+	if len(calls) != 2 || calls[1].Action != http.MethodDelete || calls[1].CustomHeaders["If-Match"] != "1" {
+		t.Errorf("Unexpected calls: %v", calls)
+	}
+}
+
+// TestSecureBootDatabaseEnrollHash tests that only SHA hashes are enrolled as hashes.
+func TestSecureBootDatabaseEnrollHash(t *testing.T) {
+	var result SecureBootDatabase
+	err := json.NewDecoder(strings.NewReader(dbBody)).Decode(&result)
+	if err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodPost: {
+				getCall(`{"@odata.id": "/redfish/v1/Systems/1/SecureBoot/SecureBootDatabases/dbx/Signatures/2"}`),
+			},
+		},
+	}
+	result.SetClient(testClient)
+
+	if _, _, err := result.EnrollHash(EFICertRSA2048GUID, bytes.Repeat([]byte{0xAA}, 256), ""); err == nil {
+		t.Error("Expected an RSA-2048 key to be rejected as a hash")
+	}
+	if _, _, err := result.EnrollHash(EFICertSHA256GUID, bytes.Repeat([]byte{0xAA}, 20), ""); err == nil {
+		t.Error("Expected a hash of the wrong size to be rejected")
+	}
+
+	if _, _, err := result.EnrollHash(EFICertSHA384GUID, bytes.Repeat([]byte{0xAA}, 48), ""); err != nil {
+		t.Fatalf("Error making EnrollHash call: %s", err)
+	}
+
+	calls := testClient.CapturedCalls()
+	if len(calls) != 1 || !strings.Contains(calls[0].Payload, "SignatureType:EFI_CERT_SHA384_GUID") {
+		t.Errorf("Unexpected calls: %v", calls)
+	}
+}
@@ -131,6 +131,32 @@ type Threshold struct {
 	HysteresisReading float32
 	// The threshold value.
 	Reading float32
+	// Defined is set if the threshold has a value, which may be zero. It is
+	// set when the threshold is read from a service that reports its Reading,
+	// and needs to be set for thresholds built in code.
+	Defined bool `json:"-"`
+}
+
+// UnmarshalJSON unmarshals a Threshold object from the raw JSON.
+func (threshold *Threshold) UnmarshalJSON(b []byte) error {
+	type temp Threshold
+	var t struct {
+		temp
+		Reading *float32
+	}
+
+	err := json.Unmarshal(b, &t)
+	if err != nil {
+		return err
+	}
+
+	*threshold = Threshold(t.temp)
+	if t.Reading != nil {
+		threshold.Reading = *t.Reading
+		threshold.Defined = true
+	}
+
+	return nil
 }
 
 type Thresholds struct {
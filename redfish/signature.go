@@ -48,20 +48,8 @@ type Signature struct {
 
 // GetSignature will get a Signature instance from the service.
 func GetSignature(c common.Client, uri string) (*Signature, error) {
-	resp, err := c.Get(uri)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
 	var signature Signature
-	err = json.NewDecoder(resp.Body).Decode(&signature)
-	if err != nil {
-		return nil, err
-	}
-
-	signature.SetClient(c)
-	return &signature, nil
+	return &signature, signature.Get(c, uri, &signature)
 }
 
 // ListReferencedSignatures gets the collection of Signature from
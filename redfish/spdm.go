@@ -0,0 +1,296 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// SPDM request and response codes used in signed measurement transcripts, as
+// defined in DMTF DSP0274.
+const (
+	spdmGetVersion          = 0x84
+	spdmVersion             = 0x04
+	spdmGetCapabilities     = 0xE1
+	spdmCapabilities        = 0x61
+	spdmNegotiateAlgorithms = 0xE3
+	spdmAlgorithms          = 0x63
+	spdmGetMeasurements     = 0xE0
+	spdmMeasurements        = 0x60
+)
+
+// spdmNonceSize is the size of the nonces exchanged in SPDM messages.
+const spdmNonceSize = 32
+
+// SPDMMeasurementBlock is a single measurement block from an SPDM MEASUREMENTS
+// response.
+type SPDMMeasurementBlock struct {
+	// Index is the index of the measurement.
+	Index int
+	// MeasurementSpecification is the bit mask of the specification the
+	// measurement follows. Bit 0 indicates the DMTF specification.
+	MeasurementSpecification uint8
+	// ValueType is the DMTF measurement value type, without the raw bit
+	// stream flag. Only valid for DMTF measurements.
+	ValueType uint8
+	// RawBitStream indicates the value is a raw bit stream rather than a
+	// digest. Only valid for DMTF measurements.
+	RawBitStream bool
+	// Value is the measurement value. For DMTF measurements it is the
+	// measurement value without the DMTF header, otherwise the whole
+	// measurement.
+	Value []byte
+}
+
+// IsDMTF reports whether the block follows the DMTF measurement specification.
+func (block *SPDMMeasurementBlock) IsDMTF() bool {
+	return block.MeasurementSpecification&0x01 != 0
+}
+
+// MeasurementType returns the Redfish measurement type of a DMTF measurement
+// block.
+func (block *SPDMMeasurementBlock) MeasurementType() DMTFmeasurementTypes {
+	switch block.ValueType {
+	case 0:
+		return ImmutableROMDMTFmeasurementTypes
+	case 1:
+		return MutableFirmwareDMTFmeasurementTypes
+	case 2:
+		return HardwareConfigurationDMTFmeasurementTypes
+	case 3:
+		return FirmwareConfigurationDMTFmeasurementTypes
+	case 4:
+		return MeasurementManifestDMTFmeasurementTypes
+	case 6:
+		return MutableFirmwareVersionDMTFmeasurementTypes
+	case 7:
+		return MutableFirmwareSecurityVersionNumberDMTFmeasurementTypes
+	}
+	return ""
+}
+
+// SPDMMeasurements is the parsed transcript of a signed SPDM measurement
+// exchange.
+type SPDMMeasurements struct {
+	// Version is the SPDM version of the final MEASUREMENTS response, such as
+	// 0x11 for version 1.1.
+	Version uint8
+	// RequesterNonce is the nonce sent in the final GET_MEASUREMENTS request.
+	RequesterNonce []byte
+	// ResponderNonce is the nonce returned in the final MEASUREMENTS response.
+	ResponderNonce []byte
+	// SlotID is the certificate slot used to sign the measurements.
+	SlotID int
+	// Blocks are the measurement blocks of all MEASUREMENTS responses.
+	Blocks []SPDMMeasurementBlock
+	// OpaqueData is the opaque data of the final MEASUREMENTS response.
+	OpaqueData []byte
+	// Signed is the part of the transcript covered by the signature.
+	Signed []byte
+	// Signature is the signature over the transcript.
+	Signature []byte
+}
+
+// Block returns the measurement block with the given index, or nil if there
+// is none.
+func (measurements *SPDMMeasurements) Block(index int) *SPDMMeasurementBlock {
+	for i := range measurements.Blocks {
+		if measurements.Blocks[i].Index == index {
+			return &measurements.Blocks[i]
+		}
+	}
+	return nil
+}
+
+var errSPDMTruncated = errors.New("truncated SPDM message")
+
+// spdmMessageLength returns the length of the SPDM message at the start of b.
+// The final MEASUREMENTS response is handled separately since its length
+// depends on the signature.
+func spdmMessageLength(b []byte) (int, error) {
+	if len(b) < 4 {
+		return 0, errSPDMTruncated
+	}
+
+	version := b[0]
+	switch b[1] {
+	case spdmGetVersion:
+		return 4, nil
+	case spdmVersion:
+		if len(b) < 6 {
+			return 0, errSPDMTruncated
+		}
+		return 6 + 2*int(b[5]), nil
+	case spdmGetCapabilities:
+		switch {
+		case version < 0x11:
+			return 4, nil
+		case version == 0x11:
+			return 12, nil
+		}
+		return 20, nil
+	case spdmCapabilities:
+		if version < 0x12 {
+			return 12, nil
+		}
+		return 20, nil
+	case spdmNegotiateAlgorithms, spdmAlgorithms:
+		if len(b) < 6 {
+			return 0, errSPDMTruncated
+		}
+		length := int(binary.LittleEndian.Uint16(b[4:6]))
+		if length < 6 {
+			return 0, fmt.Errorf("SPDM message 0x%02x has invalid length %d", b[1], length)
+		}
+		return length, nil
+	case spdmGetMeasurements:
+		if b[2]&0x01 == 0 {
+			return 4, nil
+		}
+		length := 4 + spdmNonceSize
+		if version >= 0x11 {
+			// SlotIDParam
+			length++
+		}
+		if version >= 0x13 {
+			// RequesterContext
+			length += 8
+		}
+		return length, nil
+	case spdmMeasurements:
+		length, _, err := spdmMeasurementsLength(b)
+		return length, err
+	}
+	return 0, fmt.Errorf("unexpected SPDM message code 0x%02x", b[1])
+}
+
+// spdmMeasurementsLength returns the length of a MEASUREMENTS response,
+// excluding any signature, and the length of its measurement record.
+func spdmMeasurementsLength(b []byte) (length, recordLength int, err error) {
+	if len(b) < 8 {
+		return 0, 0, errSPDMTruncated
+	}
+	recordLength = int(b[5]) | int(b[6])<<8 | int(b[7])<<16
+
+	length = 8 + recordLength + spdmNonceSize
+	if len(b) < length+2 {
+		return 0, 0, errSPDMTruncated
+	}
+	length += 2 + int(binary.LittleEndian.Uint16(b[length:length+2]))
+	if b[0] >= 0x13 {
+		// RequesterContext
+		length += 8
+	}
+	if len(b) < length {
+		return 0, 0, errSPDMTruncated
+	}
+	return length, recordLength, nil
+}
+
+// parseSPDMMeasurementRecord parses the measurement blocks of a measurement
+// record.
+func parseSPDMMeasurementRecord(record []byte) ([]SPDMMeasurementBlock, error) {
+	var result []SPDMMeasurementBlock
+	for len(record) > 0 {
+		if len(record) < 4 {
+			return nil, errSPDMTruncated
+		}
+
+		size := int(binary.LittleEndian.Uint16(record[2:4]))
+		if len(record) < 4+size {
+			return nil, errSPDMTruncated
+		}
+
+		block := SPDMMeasurementBlock{
+			Index:                    int(record[0]),
+			MeasurementSpecification: record[1],
+			Value:                    record[4 : 4+size],
+		}
+
+		if block.IsDMTF() {
+			if size < 3 {
+				return nil, errSPDMTruncated
+			}
+			valueSize := int(binary.LittleEndian.Uint16(block.Value[1:3]))
+			if size < 3+valueSize {
+				return nil, errSPDMTruncated
+			}
+			block.ValueType = block.Value[0] & 0x7F
+			block.RawBitStream = block.Value[0]&0x80 != 0
+			block.Value = block.Value[3 : 3+valueSize]
+		}
+
+		result = append(result, block)
+		record = record[4+size:]
+	}
+	return result, nil
+}
+
+// ParseSPDMMeasurements parses a signed SPDM measurement transcript as
+// returned in the SignedMeasurements property of the SPDMGetSignedMeasurements
+// action. The transcript must end with a signed MEASUREMENTS response whose
+// signature has the given size in bytes.
+func ParseSPDMMeasurements(transcript []byte, signatureSize int) (*SPDMMeasurements, error) {
+	if len(transcript) < signatureSize {
+		return nil, errSPDMTruncated
+	}
+
+	result := &SPDMMeasurements{
+		Signed:    transcript[:len(transcript)-signatureSize],
+		Signature: transcript[len(transcript)-signatureSize:],
+	}
+
+	var request []byte
+	remaining := result.Signed
+	for len(remaining) > 0 {
+		length, err := spdmMessageLength(remaining)
+		if err != nil {
+			return nil, err
+		}
+		if length > len(remaining) {
+			return nil, errSPDMTruncated
+		}
+		message := remaining[:length]
+		remaining = remaining[length:]
+
+		switch message[1] {
+		case spdmGetMeasurements:
+			request = message
+		case spdmMeasurements:
+			_, recordLength, err := spdmMeasurementsLength(message)
+			if err != nil {
+				return nil, err
+			}
+
+			blocks, err := parseSPDMMeasurementRecord(message[8 : 8+recordLength])
+			if err != nil {
+				return nil, err
+			}
+			result.Blocks = append(result.Blocks, blocks...)
+
+			if len(remaining) == 0 {
+				nonceEnd := 8 + recordLength + spdmNonceSize
+				opaqueLength := int(binary.LittleEndian.Uint16(message[nonceEnd : nonceEnd+2]))
+
+				result.Version = message[0]
+				result.SlotID = int(message[3] & 0x0F)
+				result.ResponderNonce = message[8+recordLength : nonceEnd]
+				result.OpaqueData = message[nonceEnd+2 : nonceEnd+2+opaqueLength]
+			}
+		}
+	}
+
+	if result.ResponderNonce == nil {
+		return nil, errors.New("transcript does not end with a MEASUREMENTS response")
+	}
+	if request == nil || request[2]&0x01 == 0 {
+		return nil, errors.New("transcript does not contain a signed GET_MEASUREMENTS request")
+	}
+	result.RequesterNonce = request[4 : 4+spdmNonceSize]
+
+	return result, nil
+}
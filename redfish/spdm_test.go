@@ -0,0 +1,39 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestParseSPDMMeasurementsMalformed tests that malformed transcripts are
+// rejected without panicking.
+func TestParseSPDMMeasurementsMalformed(t *testing.T) {
+	signature := make([]byte, 64)
+	tests := map[string][]byte{
+		"empty":                         {},
+		"short header":                  {0x12, 0x84},
+		"algorithms without length":     {0x12, 0xE3, 0, 0},
+		"negotiate length zero":         {0x12, 0xE3, 0, 0, 0, 0, 0, 0},
+		"negotiate length below header": {0x12, 0xE3, 0, 0, 5, 0, 0, 0},
+		"algorithms length zero":        {0x12, 0x63, 0, 0, 0, 0, 0, 0},
+		"length beyond transcript":      {0x12, 0x63, 0, 0, 0xFF, 0, 0, 0},
+		"measurements truncated":        {0x12, 0x60, 0, 0, 1, 0xFF, 0, 0},
+		"version truncated":             {0x12, 0x04, 0, 0, 0, 4},
+		"unknown code":                  {0x12, 0x01, 0, 0},
+	}
+
+	for name, message := range tests {
+		transcript := append(append([]byte(nil), message...), signature...)
+		if _, err := ParseSPDMMeasurements(transcript, len(signature)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	if _, err := ParseSPDMMeasurements(bytes.Repeat([]byte{0}, 10), 64); err != errSPDMTruncated {
+		t.Errorf("Expected a transcript shorter than the signature to be truncated, got %v", err)
+	}
+}
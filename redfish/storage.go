@@ -6,6 +6,8 @@ package redfish
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"reflect"
 
 	"github.com/stmcginnis/gofish/common"
@@ -430,6 +432,100 @@ func (storage *Storage) SetEncryptionKey(key, currentEncryptionKey, encryptionKe
 	return storage.Post(storage.setEncryptionKeyTarget, t)
 }
 
+// CreateVolumeParameters contains the settings for a new volume.
+type CreateVolumeParameters struct {
+	// Name is the name of the new volume.
+	Name string
+	// RAIDType is the RAID type of the new volume.
+	RAIDType RAIDType
+	// Drives are the drives to build the volume from.
+	Drives []*Drive
+	// CapacityBytes is the size of the volume. If zero, the service decides
+	// the size based on the drives and RAID type.
+	CapacityBytes int64
+	// StripSizeBytes is the number of bytes per strip. If zero, the service
+	// default is used.
+	StripSizeBytes int64
+	// OperationApplyTime is when the service should create the volume. If
+	// empty, the service default is used.
+	OperationApplyTime common.OperationApplyTime
+}
+
+// CreateVolume creates a new volume in this storage subsystem.
+//
+// If the service creates the volume right away, the new volume is returned.
+// If the service processes the request asynchronously, or the apply time is
+// deferred, the task tracking the operation is returned instead. If the
+// service returns neither the volume nor its location, an error is returned.
+func (storage *Storage) CreateVolume(parameters *CreateVolumeParameters) (*Volume, *Task, error) {
+	if storage.volumes == "" {
+		return nil, nil, errors.New("volumes are not supported by this storage")
+	}
+	if parameters.RAIDType == "" && len(parameters.Drives) == 0 {
+		return nil, nil, errors.New("a RAID type or a set of drives is required to create a volume")
+	}
+
+	if parameters.OperationApplyTime != "" {
+		applyTimes, err := storage.GetOperationApplyTimeValues()
+		if err != nil {
+			return nil, nil, err
+		}
+		if !isOperationApplyTimeAllowed(parameters.OperationApplyTime, applyTimes) {
+			return nil, nil, fmt.Errorf("operation apply time '%s' is not supported by this storage", parameters.OperationApplyTime)
+		}
+	}
+
+	drives := make([]string, 0, len(parameters.Drives))
+	for _, drive := range parameters.Drives {
+		drives = append(drives, drive.ODataID)
+	}
+
+	t := struct {
+		Name               string                    `json:",omitempty"`
+		RAIDType           RAIDType                  `json:",omitempty"`
+		CapacityBytes      int64                     `json:",omitempty"`
+		StripSizeBytes     int64                     `json:",omitempty"`
+		OperationApplyTime common.OperationApplyTime `json:"@Redfish.OperationApplyTime,omitempty"`
+		Links              struct {
+			Drives []common.IDRef `json:",omitempty"`
+		}
+	}{
+		Name:               parameters.Name,
+		RAIDType:           parameters.RAIDType,
+		CapacityBytes:      parameters.CapacityBytes,
+		StripSizeBytes:     parameters.StripSizeBytes,
+		OperationApplyTime: parameters.OperationApplyTime,
+	}
+	t.Links.Drives = common.IDRefs(drives)
+
+	var volume Volume
+	task, err := CreateResource(storage.GetClient(), storage.volumes, t, &volume)
+	if err != nil || task != nil {
+		return nil, task, err
+	}
+	volume.SetClient(storage.GetClient())
+
+	return &volume, nil, nil
+}
+
+// DeleteVolume deletes a volume from this storage subsystem. If the service
+// processes the deletion asynchronously, the task tracking the operation is
+// returned.
+func (storage *Storage) DeleteVolume(volumeURI string) (*Task, error) {
+	return DeleteResource(storage.GetClient(), volumeURI)
+}
+
+// isOperationApplyTimeAllowed checks whether an apply time is in the set of
+// supported values.
+func isOperationApplyTimeAllowed(applyTime common.OperationApplyTime, allowed []common.OperationApplyTime) bool {
+	for _, value := range allowed {
+		if value == applyTime {
+			return true
+		}
+	}
+	return false
+}
+
 // Update commits updates to this object's properties to the running system.
 func (storage *Storage) Update() error {
 	// Get a representation of the object's original state so we can find what
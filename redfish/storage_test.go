@@ -6,6 +6,7 @@ package redfish
 
 import (
 	"encoding/json"
+	"net/http"
 	"strings"
 	"testing"
 
@@ -342,3 +343,202 @@ func TestStorageControllerUpdate(t *testing.T) {
 		t.Errorf("Unexpected AssetTag update payload: %s", calls[0].Payload)
 	}
 }
+
+// TestStorageCreateVolume tests the CreateVolume call.
+func TestStorageCreateVolume(t *testing.T) {
+	var result Storage
+	err := json.NewDecoder(strings.NewReader(storageBody)).Decode(&result)
+
+	if err != nil {
+		t.Errorf("Error decoding JSON: %s", err)
+	}
+
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {
+				getCall(`{"@Redfish.OperationApplyTimeSupport": {"SupportedValues": ["Immediate", "OnReset"]}}`),
+				getCall(`{"@Redfish.OperationApplyTimeSupport": {"SupportedValues": ["Immediate", "OnReset"]}}`),
+			},
+			http.MethodPost: {
+				getCall(`{"@odata.id": "/redfish/v1/Volumes/1/2", "Id": "2", "Name": "Boot", "RAIDType": "RAID1"}`),
+			},
+		},
+	}
+	result.SetClient(testClient)
+
+	drive1 := &Drive{}
+	drive1.ODataID = "/redfish/v1/Drives/1"
+	drive2 := &Drive{}
+	drive2.ODataID = "/redfish/v1/Drives/2"
+
+	volume, task, err := result.CreateVolume(&CreateVolumeParameters{
+		Name:               "Boot",
+		RAIDType:           RAID1RAIDType,
+		Drives:             []*Drive{drive1, drive2},
+		OperationApplyTime: common.OnResetOperationApplyTime,
+	})
+	if err != nil {
+		t.Fatalf("Error making CreateVolume call: %s", err)
+	}
+
+	if task != nil {
+		t.Errorf("Unexpected task returned: %v", task)
+	}
+
+	if volume.ID != "2" {
+		t.Errorf("Unexpected volume ID: %s", volume.ID)
+	}
+
+	calls := testClient.CapturedCalls()
+	if calls[1].URL != "/redfish/v1/Volumes/1" {
+		t.Errorf("Unexpected CreateVolume URL: %s", calls[1].URL)
+	}
+
+	if !strings.Contains(calls[1].Payload, "@Redfish.OperationApplyTime:OnReset") {
+		t.Errorf("Unexpected apply time in payload: %s", calls[1].Payload)
+	}
+
+	if !strings.Contains(calls[1].Payload, "Drives:[map[@odata.id:/redfish/v1/Drives/1] map[@odata.id:/redfish/v1/Drives/2]]") {
+		t.Errorf("Unexpected drives in payload: %s", calls[1].Payload)
+	}
+
+	_, _, err = result.CreateVolume(&CreateVolumeParameters{
+		RAIDType:           RAID1RAIDType,
+		OperationApplyTime: common.AtMaintenanceWindowStartOperationApplyTime,
+	})
+	if err == nil {
+		t.Error("Expected unsupported apply time to be rejected")
+	}
+}
+
+// TestStorageCreateVolumeAsync tests the CreateVolume call returning a task.
+func TestStorageCreateVolumeAsync(t *testing.T) {
+	var result Storage
+	err := json.NewDecoder(strings.NewReader(storageBody)).Decode(&result)
+
+	if err != nil {
+		t.Errorf("Error decoding JSON: %s", err)
+	}
+
+	resp := getCall(`{"@odata.id": "/redfish/v1/TaskService/Tasks/5", "Id": "5", "TaskState": "Running"}`)
+	resp.StatusCode = http.StatusAccepted
+	resp.Header.Set("Location", "https://bmc.example.com/redfish/v1/TaskService/TaskMonitors/5")
+
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodPost: {resp},
+		},
+	}
+	result.SetClient(testClient)
+
+	volume, task, err := result.CreateVolume(&CreateVolumeParameters{RAIDType: RAID1RAIDType})
+	if err != nil {
+		t.Fatalf("Error making CreateVolume call: %s", err)
+	}
+
+	if volume != nil {
+		t.Errorf("Unexpected volume returned: %v", volume)
+	}
+
+	if task.ID != "5" || task.TaskState != RunningTaskState {
+		t.Errorf("Unexpected task: %s %s", task.ID, task.TaskState)
+	}
+
+	if task.TaskMonitor != "/redfish/v1/TaskService/TaskMonitors/5" {
+		t.Errorf("Unexpected task monitor: %s", task.TaskMonitor)
+	}
+}
+
+// TestStorageCreateVolumeLocation tests the CreateVolume call when the service
+// only returns the location of the new volume, with or without a message, or
+// nothing at all.
+func TestStorageCreateVolumeLocation(t *testing.T) {
+	var result Storage
+	err := json.NewDecoder(strings.NewReader(storageBody)).Decode(&result)
+
+	if err != nil {
+		t.Errorf("Error decoding JSON: %s", err)
+	}
+
+	withLocation := getCall("")
+	withLocation.StatusCode = http.StatusCreated
+	withLocation.Header.Set("Location", "/redfish/v1/Volumes/1/3")
+	withMessage := getCall(`{"@Message.ExtendedInfo": [{"MessageId": "Base.1.16.0.Created"}]}`)
+	withMessage.StatusCode = http.StatusCreated
+	withMessage.Header.Set("Location", "/redfish/v1/Volumes/1/4")
+	empty := getCall("")
+	empty.StatusCode = http.StatusCreated
+
+	volumeResp := getCall(`{"@odata.id": "/redfish/v1/Volumes/1/3", "Id": "3", "Name": "Data"}`)
+	volumeResp.Header.Set("ETag", `W/"3"`)
+
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodPost: {withLocation, withMessage, empty},
+			http.MethodGet: {
+				volumeResp,
+				getCall(`{"@odata.id": "/redfish/v1/Volumes/1/4", "Id": "4", "Name": "Logs"}`),
+			},
+		},
+	}
+	result.SetClient(testClient)
+
+	volume, task, err := result.CreateVolume(&CreateVolumeParameters{RAIDType: RAID1RAIDType})
+	if err != nil {
+		t.Fatalf("Error making CreateVolume call: %s", err)
+	}
+	if task != nil || volume.ID != "3" || volume.ETag() != `W/"3"` {
+		t.Errorf("Unexpected volume: %+v %v", volume, task)
+	}
+
+	volume, task, err = result.CreateVolume(&CreateVolumeParameters{RAIDType: RAID1RAIDType})
+	if err != nil {
+		t.Fatalf("Error making CreateVolume call: %s", err)
+	}
+	if task != nil || volume.ID != "4" {
+		t.Errorf("Unexpected volume: %+v %v", volume, task)
+	}
+
+	volume, task, err = result.CreateVolume(&CreateVolumeParameters{RAIDType: RAID1RAIDType})
+	if err == nil {
+		t.Errorf("Expected an error when the volume cannot be located, got %v %v", volume, task)
+	}
+}
+
+// TestStorageDeleteVolume tests the DeleteVolume call.
+func TestStorageDeleteVolume(t *testing.T) {
+	var result Storage
+	err := json.NewDecoder(strings.NewReader(storageBody)).Decode(&result)
+
+	if err != nil {
+		t.Errorf("Error decoding JSON: %s", err)
+	}
+
+	resp := getCall("")
+	resp.StatusCode = http.StatusAccepted
+	resp.Header.Set("Location", "/redfish/v1/TaskService/Tasks/6")
+
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodDelete: {resp},
+			http.MethodGet: {
+				getCall(`{"@odata.id": "/redfish/v1/TaskService/Tasks/6", "Id": "6", "TaskState": "New"}`),
+			},
+		},
+	}
+	result.SetClient(testClient)
+
+	task, err := result.DeleteVolume("/redfish/v1/Volumes/1/2")
+	if err != nil {
+		t.Fatalf("Error making DeleteVolume call: %s", err)
+	}
+
+	if task == nil || task.ID != "6" {
+		t.Errorf("Unexpected task: %v", task)
+	}
+
+	calls := testClient.CapturedCalls()
+	if calls[0].Action != http.MethodDelete || calls[0].URL != "/redfish/v1/Volumes/1/2" {
+		t.Errorf("Unexpected DeleteVolume call: %v", calls[0])
+	}
+}
@@ -6,6 +6,11 @@ package redfish
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/stmcginnis/gofish/common"
 )
@@ -147,6 +152,10 @@ func (task *Task) UnmarshalJSON(b []byte) error {
 	var t struct {
 		temp
 		SubTasks common.LinksCollection
+		Links    struct {
+			CreatedResources      common.Links
+			CreatedResourcesCount int `json:"CreatedResources@odata.count"`
+		}
 	}
 
 	err := json.Unmarshal(b, &t)
@@ -157,6 +166,8 @@ func (task *Task) UnmarshalJSON(b []byte) error {
 	// Extract the links to other entities for later
 	*task = Task(t.temp)
 	task.subTasks = t.SubTasks.ToStrings()
+	task.Links.CreatedResources = t.Links.CreatedResources.ToStrings()
+	task.Links.CreatedResourcesCount = t.Links.CreatedResourcesCount
 
 	return nil
 }
@@ -189,6 +200,160 @@ func GetTask(c common.Client, uri string) (*Task, error) {
 	return &task, task.Get(c, uri, &task)
 }
 
+// TaskFromResponse returns the Task for an operation that the service
+// accepted for asynchronous processing (HTTP 202 Accepted). The task is taken
+// from the response body if present, otherwise it is fetched from the task
+// monitor returned in the Location header. If the operation was not
+// asynchronous, nil is returned. An error is returned if the service returned
+// neither a task nor a location to track it.
+func TaskFromResponse(c common.Client, resp *http.Response) (*Task, error) {
+	if resp == nil || resp.StatusCode != http.StatusAccepted {
+		return nil, nil
+	}
+
+	var task Task
+	if resp.Body != nil {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		if len(body) > 0 {
+			if err := json.Unmarshal(body, &task); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	location := locationFromResponse(resp)
+	if task.ODataID == "" && task.TaskMonitor == "" {
+		if location == "" {
+			return nil, errors.New("the service accepted the request but returned no task to track it")
+		}
+		return GetTask(c, location)
+	}
+	if task.TaskMonitor == "" {
+		task.TaskMonitor = location
+	}
+
+	task.SetClient(c)
+	return &task, nil
+}
+
+// entityGetter is implemented by the entities a created resource is decoded
+// into, so that reading it records its etag.
+type entityGetter interface {
+	Get(c common.Client, uri string, payload interface{}) error
+}
+
+// CreateResource posts a new resource to a collection. If the service creates
+// the resource right away, it is decoded into result, from the response body
+// if it contains the resource, otherwise from the location the service
+// returned. If the service accepts the request for asynchronous processing,
+// the task tracking the operation is returned and result is left untouched.
+// An error is returned if the created resource cannot be located.
+func CreateResource(c common.Client, collectionURI string, payload, result interface{}) (*Task, error) {
+	resp, err := c.Post(collectionURI, payload)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusAccepted {
+		return TaskFromResponse(c, resp)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Some services return a message or nothing at all instead of the
+	// resource, so only use the body if it identifies the new resource
+	var created common.Entity
+	if len(body) > 0 && json.Unmarshal(body, &created) == nil && created.ODataID != "" {
+		return nil, json.Unmarshal(body, result)
+	}
+
+	location := locationFromResponse(resp)
+	if location == "" {
+		return nil, errors.New("the service did not return the created resource or its location")
+	}
+
+	if entity, ok := result.(entityGetter); ok {
+		return nil, entity.Get(c, location, result)
+	}
+
+	getResp, err := c.Get(location)
+	if err != nil {
+		return nil, err
+	}
+	defer getResp.Body.Close()
+
+	return nil, json.NewDecoder(getResp.Body).Decode(result)
+}
+
+// DeleteResource deletes a resource. If the service processes the deletion
+// asynchronously, the task tracking the operation is returned.
+func DeleteResource(c common.Client, uri string) (*Task, error) {
+	if strings.TrimSpace(uri) == "" {
+		return nil, errors.New("uri should not be empty")
+	}
+
+	resp, err := c.Delete(uri)
+	if err != nil {
+		return nil, err
+	}
+	return taskFromDeleteResponse(c, resp)
+}
+
+// DeleteEntity deletes an entity that was fetched from the service. The
+// request is conditional on the etag the entity was fetched with, unless the
+// entity was configured with DisableEtagMatch, so the service rejects it if
+// the entity was modified in the meantime. If the entity has no client, c is
+// used. If the service processes the deletion asynchronously, the task
+// tracking the operation is returned.
+func DeleteEntity(c common.Client, entity *common.Entity) (*Task, error) {
+	if strings.TrimSpace(entity.ODataID) == "" {
+		return nil, errors.New("uri should not be empty")
+	}
+	if entity.GetClient() == nil {
+		entity.SetClient(c)
+	}
+
+	resp, err := entity.DeleteWithResponse(entity.ODataID)
+	if err != nil {
+		return nil, err
+	}
+	return taskFromDeleteResponse(entity.GetClient(), resp)
+}
+
+// taskFromDeleteResponse returns the task tracking an asynchronous deletion.
+// The body of a DELETE response is not guaranteed to be readable, so the task
+// can only be found through the task monitor.
+func taskFromDeleteResponse(c common.Client, resp *http.Response) (*Task, error) {
+	defer resp.Body.Close()
+
+	location := locationFromResponse(resp)
+	if resp.StatusCode != http.StatusAccepted || location == "" {
+		return nil, nil
+	}
+	return GetTask(c, location)
+}
+
+// locationFromResponse returns the relative URI from the Location header of a
+// response, or an empty string if there is none.
+func locationFromResponse(resp *http.Response) string {
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return ""
+	}
+
+	if parsed, err := url.ParseRequestURI(location); err == nil {
+		return parsed.RequestURI()
+	}
+	return location
+}
+
 // ListReferencedTasks gets the collection of Task from
 // a provided reference.
 func ListReferencedTasks(c common.Client, link string) ([]*Task, error) {
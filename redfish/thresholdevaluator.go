@@ -0,0 +1,369 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+// ThresholdState is the classification of a reading against the thresholds
+// of a sensor.
+type ThresholdState string
+
+const (
+	// NormalThresholdState indicates the reading is within the normal range.
+	NormalThresholdState ThresholdState = "Normal"
+	// CautionThresholdState indicates the reading has crossed a caution
+	// threshold.
+	CautionThresholdState ThresholdState = "Caution"
+	// CriticalThresholdState indicates the reading has crossed a critical
+	// threshold but is not yet fatal.
+	CriticalThresholdState ThresholdState = "Critical"
+	// FatalThresholdState indicates the reading has crossed a fatal threshold.
+	FatalThresholdState ThresholdState = "Fatal"
+)
+
+// severity returns the ordering of the state, from normal (0) to fatal (3).
+func (state ThresholdState) severity() int {
+	switch state {
+	case CautionThresholdState:
+		return 1
+	case CriticalThresholdState:
+		return 2
+	case FatalThresholdState:
+		return 3
+	}
+	return 0
+}
+
+// ThresholdDirection indicates which side of the normal range a reading is on.
+type ThresholdDirection string
+
+const (
+	// NoThresholdDirection is used when the reading is within the normal range.
+	NoThresholdDirection ThresholdDirection = ""
+	// UpperThresholdDirection indicates the reading is above the normal range.
+	UpperThresholdDirection ThresholdDirection = "Upper"
+	// LowerThresholdDirection indicates the reading is below the normal range.
+	LowerThresholdDirection ThresholdDirection = "Lower"
+)
+
+// ThresholdLimit is a single normalized threshold boundary.
+type ThresholdLimit struct {
+	// Reading is the threshold value.
+	Reading float64
+	// Hysteresis is the offset from Reading the value must move back past
+	// before the threshold is cleared.
+	Hysteresis float64
+}
+
+// ThresholdSet is a normalized set of thresholds that can be evaluated
+// regardless of whether it came from a Sensor or a legacy Thermal
+// Temperature. A nil limit means the threshold is not defined.
+type ThresholdSet struct {
+	LowerCaution  *ThresholdLimit
+	LowerCritical *ThresholdLimit
+	LowerFatal    *ThresholdLimit
+	UpperCaution  *ThresholdLimit
+	UpperCritical *ThresholdLimit
+	UpperFatal    *ThresholdLimit
+}
+
+// limit returns the limit for the given state and direction.
+func (set *ThresholdSet) limit(state ThresholdState, direction ThresholdDirection) *ThresholdLimit {
+	switch direction {
+	case UpperThresholdDirection:
+		switch state {
+		case CautionThresholdState:
+			return set.UpperCaution
+		case CriticalThresholdState:
+			return set.UpperCritical
+		case FatalThresholdState:
+			return set.UpperFatal
+		}
+	case LowerThresholdDirection:
+		switch state {
+		case CautionThresholdState:
+			return set.LowerCaution
+		case CriticalThresholdState:
+			return set.LowerCritical
+		case FatalThresholdState:
+			return set.LowerFatal
+		}
+	}
+	return nil
+}
+
+// Classify returns the state of a reading against the thresholds, ignoring
+// hysteresis.
+func (set *ThresholdSet) Classify(reading float64) (ThresholdState, ThresholdDirection) {
+	for _, state := range []ThresholdState{FatalThresholdState, CriticalThresholdState, CautionThresholdState} {
+		if limit := set.limit(state, UpperThresholdDirection); limit != nil && reading >= limit.Reading {
+			return state, UpperThresholdDirection
+		}
+		if limit := set.limit(state, LowerThresholdDirection); limit != nil && reading <= limit.Reading {
+			return state, LowerThresholdDirection
+		}
+	}
+	return NormalThresholdState, NoThresholdDirection
+}
+
+// held reports whether a reading is still inside the hysteresis band of the
+// given threshold, meaning a previously raised state should not yet clear.
+func (set *ThresholdSet) held(reading float64, state ThresholdState, direction ThresholdDirection) bool {
+	limit := set.limit(state, direction)
+	if limit == nil {
+		return false
+	}
+	if direction == UpperThresholdDirection {
+		return reading > limit.Reading-limit.Hysteresis
+	}
+	return reading < limit.Reading+limit.Hysteresis
+}
+
+// thresholdLimitFromThreshold converts a Sensor threshold. Thresholds that are
+// disabled or not defined are treated as undefined. A defined reading of zero
+// is a valid threshold.
+func thresholdLimitFromThreshold(threshold *Threshold) *ThresholdLimit {
+	if threshold.Activation == DisabledThresholdActivation || !threshold.Defined {
+		return nil
+	}
+	return &ThresholdLimit{
+		Reading:    float64(threshold.Reading),
+		Hysteresis: float64(threshold.HysteresisReading),
+	}
+}
+
+// reportedProperties returns the properties of a JSON object that are present
+// and not null.
+func reportedProperties(b []byte) map[string]bool {
+	var properties map[string]json.RawMessage
+	if err := json.Unmarshal(b, &properties); err != nil {
+		return nil
+	}
+	result := make(map[string]bool, len(properties))
+	for name, value := range properties {
+		if string(value) != "null" {
+			result[name] = true
+		}
+	}
+	return result
+}
+
+// SensorThresholdSet builds the threshold set for a Sensor. User-defined
+// caution and critical thresholds take precedence over the service defaults
+// when they are present.
+func SensorThresholdSet(sensor *Sensor) *ThresholdSet {
+	thresholds := &sensor.Thresholds
+	pick := func(user, standard *Threshold) *ThresholdLimit {
+		if limit := thresholdLimitFromThreshold(user); limit != nil {
+			return limit
+		}
+		return thresholdLimitFromThreshold(standard)
+	}
+
+	return &ThresholdSet{
+		LowerCaution:  pick(&thresholds.LowerCautionUser, &thresholds.LowerCaution),
+		LowerCritical: pick(&thresholds.LowerCriticalUser, &thresholds.LowerCritical),
+		LowerFatal:    thresholdLimitFromThreshold(&thresholds.LowerFatal),
+		UpperCaution:  pick(&thresholds.UpperCautionUser, &thresholds.UpperCaution),
+		UpperCritical: pick(&thresholds.UpperCriticalUser, &thresholds.UpperCritical),
+		UpperFatal:    thresholdLimitFromThreshold(&thresholds.UpperFatal),
+	}
+}
+
+// TemperatureThresholdSet builds the threshold set for a legacy Thermal
+// Temperature. Legacy thresholds carry no hysteresis. Only the thresholds
+// present in the JSON the temperature was read from are defined, so a
+// temperature that was not read from a service has no thresholds.
+func TemperatureThresholdSet(temperature *Temperature) *ThresholdSet {
+	reported := reportedProperties(temperature.rawData)
+	limit := func(name string, reading float32) *ThresholdLimit {
+		if !reported[name] {
+			return nil
+		}
+		return &ThresholdLimit{Reading: float64(reading)}
+	}
+
+	return &ThresholdSet{
+		LowerCaution:  limit("LowerThresholdNonCritical", temperature.LowerThresholdNonCritical),
+		LowerCritical: limit("LowerThresholdCritical", temperature.LowerThresholdCritical),
+		LowerFatal:    limit("LowerThresholdFatal", temperature.LowerThresholdFatal),
+		UpperCaution:  limit("UpperThresholdNonCritical", temperature.UpperThresholdNonCritical),
+		UpperCritical: limit("UpperThresholdCritical", temperature.UpperThresholdCritical),
+		UpperFatal:    limit("UpperThresholdFatal", temperature.UpperThresholdFatal),
+	}
+}
+
+// ThresholdEvent describes a transition of a sensor between threshold states.
+type ThresholdEvent struct {
+	// Key identifies the sensor, typically its @odata.id.
+	Key string
+	// Reading is the reading that caused the transition.
+	Reading float64
+	// PreviousState is the state before this reading.
+	PreviousState ThresholdState
+	// PreviousDirection is the direction before this reading.
+	PreviousDirection ThresholdDirection
+	// State is the state after this reading.
+	State ThresholdState
+	// Direction is the side of the normal range the reading is on.
+	Direction ThresholdDirection
+	// Threshold is the threshold value that was crossed, if the new state is
+	// not normal.
+	Threshold float64
+}
+
+// thresholdStatus is the last known state of one sensor.
+type thresholdStatus struct {
+	state     ThresholdState
+	direction ThresholdDirection
+}
+
+// ThresholdEvaluator classifies sensor readings against their thresholds and
+// keeps state between polls so that hysteresis can suppress flapping around a
+// threshold. It is safe for concurrent use.
+type ThresholdEvaluator struct {
+	mu       sync.Mutex
+	statuses map[string]thresholdStatus
+	// Events, if set, receives every transition reported by the evaluator.
+	// Sends are blocking, so the channel must be drained by the caller.
+	Events chan<- ThresholdEvent
+}
+
+// NewThresholdEvaluator creates a new ThresholdEvaluator.
+func NewThresholdEvaluator() *ThresholdEvaluator {
+	return &ThresholdEvaluator{statuses: make(map[string]thresholdStatus)}
+}
+
+// State returns the current state recorded for a sensor.
+func (evaluator *ThresholdEvaluator) State(key string) (ThresholdState, ThresholdDirection) {
+	evaluator.mu.Lock()
+	defer evaluator.mu.Unlock()
+
+	status, ok := evaluator.statuses[key]
+	if !ok {
+		return NormalThresholdState, NoThresholdDirection
+	}
+	return status.state, status.direction
+}
+
+// Forget removes any state recorded for a sensor.
+func (evaluator *ThresholdEvaluator) Forget(key string) {
+	evaluator.mu.Lock()
+	defer evaluator.mu.Unlock()
+	delete(evaluator.statuses, key)
+}
+
+// Evaluate classifies a reading for the sensor identified by key. If the state
+// changed since the previous reading, the transition is returned and the
+// boolean is true.
+//
+// A raised state is only lowered once the reading has moved back past the
+// threshold by at least its hysteresis offset.
+func (evaluator *ThresholdEvaluator) Evaluate(key string, reading float64, thresholds *ThresholdSet) (ThresholdEvent, bool) {
+	evaluator.mu.Lock()
+
+	if evaluator.statuses == nil {
+		evaluator.statuses = make(map[string]thresholdStatus)
+	}
+
+	previous, ok := evaluator.statuses[key]
+	if !ok {
+		previous = thresholdStatus{state: NormalThresholdState}
+	}
+
+	state, direction := thresholds.Classify(reading)
+	if previous.direction != NoThresholdDirection &&
+		(direction == previous.direction || direction == NoThresholdDirection) &&
+		state.severity() < previous.state.severity() {
+		for _, candidate := range []ThresholdState{FatalThresholdState, CriticalThresholdState, CautionThresholdState} {
+			if candidate.severity() > previous.state.severity() || candidate.severity() <= state.severity() {
+				continue
+			}
+			if thresholds.held(reading, candidate, previous.direction) {
+				state, direction = candidate, previous.direction
+				break
+			}
+		}
+	}
+
+	current := thresholdStatus{state: state, direction: direction}
+	evaluator.statuses[key] = current
+	evaluator.mu.Unlock()
+
+	if current == previous {
+		return ThresholdEvent{}, false
+	}
+
+	event := ThresholdEvent{
+		Key:               key,
+		Reading:           reading,
+		PreviousState:     previous.state,
+		PreviousDirection: previous.direction,
+		State:             state,
+		Direction:         direction,
+	}
+	if limit := thresholds.limit(state, direction); limit != nil {
+		event.Threshold = limit.Reading
+	}
+
+	if evaluator.Events != nil {
+		evaluator.Events <- event
+	}
+
+	return event, true
+}
+
+// EvaluateSensor classifies the current reading of a Sensor using its own
+// thresholds.
+func (evaluator *ThresholdEvaluator) EvaluateSensor(sensor *Sensor) (ThresholdEvent, bool) {
+	return evaluator.Evaluate(sensor.ODataID, float64(sensor.Reading), SensorThresholdSet(sensor))
+}
+
+// EvaluateTemperature classifies the current reading of a legacy Thermal
+// Temperature using its own thresholds.
+func (evaluator *ThresholdEvaluator) EvaluateTemperature(temperature *Temperature) (ThresholdEvent, bool) {
+	key := temperature.ODataID
+	if key == "" {
+		key = temperature.MemberID
+	}
+	return evaluator.Evaluate(key, float64(temperature.ReadingCelsius), TemperatureThresholdSet(temperature))
+}
+
+// EvaluateSensors classifies the readings of a set of sensors and returns all
+// resulting transitions. Absent sensors are skipped.
+func (evaluator *ThresholdEvaluator) EvaluateSensors(sensors []*Sensor) []ThresholdEvent {
+	var events []ThresholdEvent
+	for _, sensor := range sensors {
+		if sensor.Status.State == common.AbsentState {
+			continue
+		}
+		if event, changed := evaluator.EvaluateSensor(sensor); changed {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// EvaluateThermal classifies the readings of all temperatures of a legacy
+// Thermal resource and returns all resulting transitions. Absent temperature
+// sensors, which often report zero for their reading and thresholds, are
+// skipped.
+func (evaluator *ThresholdEvaluator) EvaluateThermal(thermal *Thermal) []ThresholdEvent {
+	var events []ThresholdEvent
+	for i := range thermal.Temperatures {
+		if thermal.Temperatures[i].Status.State == common.AbsentState {
+			continue
+		}
+		if event, changed := evaluator.EvaluateTemperature(&thermal.Temperatures[i]); changed {
+			events = append(events, event)
+		}
+	}
+	return events
+}
@@ -0,0 +1,207 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+var thresholdSensorBody = `{
+		"@odata.type": "#Sensor.v1_7_0.Sensor",
+		"@odata.id": "/redfish/v1/Chassis/1/Sensors/CPUTemp",
+		"Id": "CPUTemp",
+		"Name": "CPU Temperature",
+		"ReadingType": "Temperature",
+		"Reading": 50,
+		"Thresholds": {
+			"UpperCaution": {
+				"Reading": 70,
+				"HysteresisReading": 3
+			},
+			"UpperCritical": {
+				"Reading": 85,
+				"HysteresisReading": 2
+			},
+			"UpperFatal": {
+				"Reading": 95
+			},
+			"LowerCaution": {
+				"Reading": 5,
+				"Activation": "Disabled"
+			}
+		}
+	}`
+
+// TestThresholdEvaluatorSensor tests classification and hysteresis of Sensor readings.
+func TestThresholdEvaluatorSensor(t *testing.T) {
+	var sensor Sensor
+	if err := json.NewDecoder(strings.NewReader(thresholdSensorBody)).Decode(&sensor); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	set := SensorThresholdSet(&sensor)
+	if set.LowerCaution != nil {
+		t.Error("Disabled threshold should not be defined")
+	}
+	if set.LowerFatal != nil {
+		t.Error("Missing threshold should not be defined")
+	}
+
+	evaluator := NewThresholdEvaluator()
+
+	if _, changed := evaluator.EvaluateSensor(&sensor); changed {
+		t.Error("Normal reading should not report a transition")
+	}
+
+	steps := []struct {
+		reading   float32
+		changed   bool
+		state     ThresholdState
+		threshold float64
+	}{
+		{71, true, CautionThresholdState, 70},
+		// Within the hysteresis band, stays in caution
+		{68, false, CautionThresholdState, 0},
+		{86, true, CriticalThresholdState, 85},
+		// Below critical but within its hysteresis
+		{84, false, CriticalThresholdState, 0},
+		{82, true, CautionThresholdState, 70},
+		{96, true, FatalThresholdState, 95},
+		{60, true, NormalThresholdState, 0},
+	}
+
+	for _, step := range steps {
+		sensor.Reading = step.reading
+		event, changed := evaluator.EvaluateSensor(&sensor)
+		if changed != step.changed {
+			t.Errorf("Reading %v: expected changed %t, got %t", step.reading, step.changed, changed)
+		}
+		state, _ := evaluator.State(sensor.ODataID)
+		if state != step.state {
+			t.Errorf("Reading %v: expected state %s, got %s", step.reading, step.state, state)
+		}
+		if changed && event.Threshold != step.threshold {
+			t.Errorf("Reading %v: expected threshold %v, got %v", step.reading, step.threshold, event.Threshold)
+		}
+	}
+}
+
+// TestThresholdEvaluatorThermal tests classification of legacy Thermal temperatures.
+func TestThresholdEvaluatorThermal(t *testing.T) {
+	var thermal Thermal
+	if err := json.NewDecoder(strings.NewReader(thermalBody)).Decode(&thermal); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	events := make(chan ThresholdEvent, 10)
+	evaluator := NewThresholdEvaluator()
+	evaluator.Events = events
+
+	if result := evaluator.EvaluateThermal(&thermal); len(result) != 0 {
+		t.Errorf("Expected no transitions, got %d", len(result))
+	}
+
+	thermal.Temperatures[0].ReadingCelsius = 101
+	result := evaluator.EvaluateThermal(&thermal)
+	if len(result) != 1 {
+		t.Fatalf("Expected one transition, got %d", len(result))
+	}
+
+	event := <-events
+	assertEquals(t, "/redfish/v1/Chassis/1/Thermal#/Temperatures/0", event.Key)
+	assertEquals(t, string(FatalThresholdState), string(event.State))
+	assertEquals(t, string(UpperThresholdDirection), string(event.Direction))
+	assertEquals(t, string(NormalThresholdState), string(event.PreviousState))
+}
+
+var zeroThresholdSensorBody = `{
+		"@odata.type": "#Sensor.v1_7_0.Sensor",
+		"@odata.id": "/redfish/v1/Chassis/1/Sensors/Fan1",
+		"Id": "Fan1",
+		"Name": "Fan 1",
+		"ReadingType": "Rotational",
+		"Reading": 4000,
+		"Thresholds": {
+			"LowerCritical": {
+				"Reading": 0
+			}
+		}
+	}`
+
+var zeroThresholdTemperatureBody = `{
+		"@odata.id": "/redfish/v1/Chassis/1/Thermal#/Temperatures/0",
+		"MemberId": "0",
+		"Name": "Inlet Temp",
+		"ReadingCelsius": 10,
+		"LowerThresholdCritical": 0,
+		"UpperThresholdFatal": null
+	}`
+
+// TestThresholdEvaluatorZeroThresholds tests that reported thresholds of zero
+// are evaluated.
+func TestThresholdEvaluatorZeroThresholds(t *testing.T) {
+	var sensor Sensor
+	if err := json.NewDecoder(strings.NewReader(zeroThresholdSensorBody)).Decode(&sensor); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	set := SensorThresholdSet(&sensor)
+	if set.LowerCritical == nil || set.LowerCritical.Reading != 0 {
+		t.Errorf("Expected a zero lower critical threshold: %+v", set.LowerCritical)
+	}
+	if set.UpperCritical != nil {
+		t.Error("Missing threshold should not be defined")
+	}
+
+	sensor.Reading = 0
+	if event, _ := NewThresholdEvaluator().EvaluateSensor(&sensor); event.State != CriticalThresholdState {
+		t.Errorf("Expected a stopped fan to be critical, got %s", event.State)
+	}
+
+	var temperature Temperature
+	if err := json.NewDecoder(strings.NewReader(zeroThresholdTemperatureBody)).Decode(&temperature); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	set = TemperatureThresholdSet(&temperature)
+	if set.LowerCritical == nil || set.LowerCritical.Reading != 0 {
+		t.Errorf("Expected a zero lower critical threshold: %+v", set.LowerCritical)
+	}
+	if set.UpperFatal != nil || set.LowerCaution != nil {
+		t.Error("Null or missing thresholds should not be defined")
+	}
+
+	temperature.ReadingCelsius = -1
+	if event, _ := NewThresholdEvaluator().EvaluateTemperature(&temperature); event.State != CriticalThresholdState {
+		t.Errorf("Expected a reading below 0 to be critical, got %s", event.State)
+	}
+}
+
+// TestThresholdEvaluatorBuiltThresholds tests that thresholds built in code are
+// evaluated like thresholds read from a service.
+func TestThresholdEvaluatorBuiltThresholds(t *testing.T) {
+	var sensor Sensor
+	sensor.Reading = 95
+	sensor.Thresholds.UpperCritical = Threshold{Reading: 90, Defined: true}
+	sensor.Thresholds.LowerCritical = Threshold{Reading: 0, Defined: true}
+	sensor.Thresholds.UpperFatal = Threshold{Reading: 100}
+
+	set := SensorThresholdSet(&sensor)
+	if set.UpperCritical == nil || set.UpperCritical.Reading != 90 {
+		t.Errorf("Expected the built upper critical threshold: %+v", set.UpperCritical)
+	}
+	if set.LowerCritical == nil || set.LowerCritical.Reading != 0 {
+		t.Errorf("Expected a zero lower critical threshold: %+v", set.LowerCritical)
+	}
+	if set.UpperFatal != nil {
+		t.Error("Threshold that is not defined should be ignored")
+	}
+
+	if event, _ := NewThresholdEvaluator().EvaluateSensor(&sensor); event.State != CriticalThresholdState {
+		t.Errorf("Expected the reading to be critical, got %s", event.State)
+	}
+}
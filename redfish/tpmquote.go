@@ -0,0 +1,205 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package redfish
+
+import (
+	"crypto"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// TPM algorithm identifiers from the TCG Algorithm Registry.
+const (
+	tpmAlgSHA1   = 0x0004
+	tpmAlgSHA256 = 0x000B
+	tpmAlgSHA384 = 0x000C
+	tpmAlgSHA512 = 0x000D
+	tpmAlgRSASSA = 0x0014
+	tpmAlgRSAPSS = 0x0016
+	tpmAlgECDSA  = 0x0018
+)
+
+const (
+	// tpmGeneratedValue is the magic value of structures generated by a TPM.
+	tpmGeneratedValue = 0xFF544347
+	// tpmSTAttestQuote is the structure tag of a quote.
+	tpmSTAttestQuote = 0x8018
+)
+
+// TPMPCRSelection is a selection of PCRs of one bank.
+type TPMPCRSelection struct {
+	// HashAlgorithm is the TPM algorithm identifier of the PCR bank.
+	HashAlgorithm uint16
+	// PCRs are the selected PCR indices.
+	PCRs []int
+}
+
+// TPMQuote is a parsed TPM2_Quote as returned in the SignedMeasurements
+// property of the TPMGetSignedMeasurements action.
+type TPMQuote struct {
+	// Attested is the TPMS_ATTEST structure covered by the signature.
+	Attested []byte
+	// ExtraData is the qualifying data, which contains the nonce.
+	ExtraData []byte
+	// Clock is the TPM clock when the quote was generated.
+	Clock uint64
+	// ResetCount is the number of TPM resets.
+	ResetCount uint32
+	// RestartCount is the number of TPM restarts since the last reset.
+	RestartCount uint32
+	// FirmwareVersion is the TPM firmware version.
+	FirmwareVersion uint64
+	// PCRSelections are the PCRs included in the quote.
+	PCRSelections []TPMPCRSelection
+	// PCRDigest is the digest of the selected PCR values.
+	PCRDigest []byte
+	// SignatureAlgorithm is the TPM algorithm identifier of the signature
+	// scheme.
+	SignatureAlgorithm uint16
+	// SignatureHashAlgorithm is the TPM algorithm identifier of the hash used
+	// for the signature.
+	SignatureHashAlgorithm uint16
+	// Signature is the RSA signature, or the concatenated R and S values of
+	// an ECDSA signature.
+	Signature []byte
+	// R and S are the values of an ECDSA signature.
+	R, S *big.Int
+}
+
+// tpmReader reads big-endian TPM structures.
+type tpmReader struct {
+	b   []byte
+	err error
+}
+
+func (r *tpmReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.b) < n {
+		r.err = errors.New("truncated TPM structure")
+		return nil
+	}
+	result := r.b[:n]
+	r.b = r.b[n:]
+	return result
+}
+
+func (r *tpmReader) uint8() uint8 {
+	if b := r.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *tpmReader) uint16() uint16 {
+	if b := r.bytes(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *tpmReader) uint32() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *tpmReader) uint64() uint64 {
+	if b := r.bytes(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+// sized reads a TPM2B structure.
+func (r *tpmReader) sized() []byte {
+	return r.bytes(int(r.uint16()))
+}
+
+// ParseTPMQuote parses the concatenation of the TPM2B_ATTEST and TPMT_SIGNATURE
+// returned by TPM2_Quote.
+func ParseTPMQuote(signedMeasurements []byte) (*TPMQuote, error) {
+	reader := &tpmReader{b: signedMeasurements}
+	quote := &TPMQuote{Attested: reader.sized()}
+	if reader.err != nil {
+		return nil, reader.err
+	}
+
+	attest := &tpmReader{b: quote.Attested}
+	if attest.uint32() != tpmGeneratedValue {
+		return nil, errors.New("quote was not generated by a TPM")
+	}
+	if attest.uint16() != tpmSTAttestQuote {
+		return nil, errors.New("attestation structure is not a quote")
+	}
+
+	// Qualified name of the signing key
+	attest.sized()
+	quote.ExtraData = attest.sized()
+	quote.Clock = attest.uint64()
+	quote.ResetCount = attest.uint32()
+	quote.RestartCount = attest.uint32()
+	// Safe flag
+	attest.uint8()
+	quote.FirmwareVersion = attest.uint64()
+
+	count := attest.uint32()
+	for i := uint32(0); i < count && attest.err == nil; i++ {
+		selection := TPMPCRSelection{HashAlgorithm: attest.uint16()}
+		for byteIndex, bits := range attest.bytes(int(attest.uint8())) {
+			for bit := 0; bit < 8; bit++ {
+				if bits&(1<<bit) != 0 {
+					selection.PCRs = append(selection.PCRs, byteIndex*8+bit)
+				}
+			}
+		}
+		quote.PCRSelections = append(quote.PCRSelections, selection)
+	}
+	quote.PCRDigest = attest.sized()
+	if attest.err != nil {
+		return nil, attest.err
+	}
+
+	quote.SignatureAlgorithm = reader.uint16()
+	quote.SignatureHashAlgorithm = reader.uint16()
+	switch quote.SignatureAlgorithm {
+	case tpmAlgRSASSA, tpmAlgRSAPSS:
+		quote.Signature = reader.sized()
+	case tpmAlgECDSA:
+		r := reader.sized()
+		s := reader.sized()
+		quote.R = new(big.Int).SetBytes(r)
+		quote.S = new(big.Int).SetBytes(s)
+		quote.Signature = append(append([]byte{}, r...), s...)
+	default:
+		if reader.err == nil {
+			return nil, fmt.Errorf("unsupported TPM signature algorithm 0x%04x", quote.SignatureAlgorithm)
+		}
+	}
+	if reader.err != nil {
+		return nil, reader.err
+	}
+
+	return quote, nil
+}
+
+// tpmHash maps a TPM hash algorithm identifier to a hash function.
+func tpmHash(algorithm uint16) (crypto.Hash, error) {
+	switch algorithm {
+	case tpmAlgSHA1:
+		return crypto.SHA1, nil
+	case tpmAlgSHA256:
+		return crypto.SHA256, nil
+	case tpmAlgSHA384:
+		return crypto.SHA384, nil
+	case tpmAlgSHA512:
+		return crypto.SHA512, nil
+	}
+	return 0, fmt.Errorf("unsupported TPM hash algorithm 0x%04x", algorithm)
+}
@@ -370,20 +370,8 @@ func (zone *Zone) Update() error {
 
 // GetZone will get a Zone instance from the service.
 func GetZone(c common.Client, uri string) (*Zone, error) {
-	resp, err := c.Get(uri)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
 	var zone Zone
-	err = json.NewDecoder(resp.Body).Decode(&zone)
-	if err != nil {
-		return nil, err
-	}
-
-	zone.SetClient(c)
-	return &zone, nil
+	return &zone, zone.Get(c, uri, &zone)
 }
 
 // ListReferencedZones gets the collection of Zone from
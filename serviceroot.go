@@ -472,6 +472,13 @@ func (serviceroot *Service) CreateSession(username, password string) (*redfish.A
 	return redfish.CreateSession(serviceroot.GetClient(), serviceroot.sessions, username, password)
 }
 
+// VerifyDirectoryLogin logs in with the credentials of a user of an external
+// account provider, such as LDAP or Active Directory, and reports the roles the
+// user was mapped to. The session is deleted again afterwards.
+func (serviceroot *Service) VerifyDirectoryLogin(username, password string) (*redfish.DirectoryLogin, error) {
+	return redfish.VerifyDirectoryLogin(serviceroot.GetClient(), serviceroot.sessions, username, password)
+}
+
 // ManagerProvidingService gets the manager for this Redfish service.
 func (serviceroot *Service) ManagerProvidingService() (*redfish.Manager, error) {
 	if serviceroot.managerProvidingService == "" {
@@ -534,6 +541,12 @@ func (serviceroot *Service) MessageByLanguage(messageID, language string) (*redf
 	return redfish.GetMessageFromMessageRegistryByLanguage(serviceroot.GetClient(), serviceroot.registries, messageID, language)
 }
 
+// MessageFormatter gets a formatter rendering messages from the message
+// registries of the service, falling back to the bundled DMTF registries.
+func (serviceroot *Service) MessageFormatter() *redfish.MessageFormatter {
+	return redfish.NewMessageFormatter(serviceroot.GetClient(), serviceroot.registries)
+}
+
 // Systems get the system instances from the service
 func (serviceroot *Service) Systems() ([]*redfish.ComputerSystem, error) {
 	return redfish.ListReferencedComputerSystems(serviceroot.GetClient(), serviceroot.systems)
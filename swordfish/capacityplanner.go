@@ -0,0 +1,287 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package swordfish
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+// PoolCapacity summarizes the capacity of a storage pool.
+type PoolCapacity struct {
+	// Pool is the storage pool being summarized.
+	Pool *StoragePool
+	// AllocatedBytes is the sum of the data, metadata and snapshot capacity
+	// allocated to the pool.
+	AllocatedBytes int64
+	// ConsumedBytes is the sum of the data, metadata and snapshot capacity
+	// consumed within the pool.
+	ConsumedBytes int64
+	// GuaranteedBytes is the sum of the data, metadata and snapshot capacity
+	// guaranteed to be available within the pool.
+	GuaranteedBytes int64
+	// ProvisionedBytes is the sum of the data, metadata and snapshot capacity
+	// promised to consumers of the pool.
+	ProvisionedBytes int64
+	// AvailableBytes is the data capacity that can still be consumed.
+	AvailableBytes int64
+	// IsThinProvisioned indicates that the pool can promise more capacity
+	// than it has allocated.
+	IsThinProvisioned bool
+}
+
+// NewPoolCapacity creates a capacity summary for a storage pool.
+func NewPoolCapacity(pool *StoragePool) PoolCapacity {
+	capacity := &pool.Capacity
+	summary := PoolCapacity{
+		Pool:              pool,
+		IsThinProvisioned: capacity.IsThinProvisioned,
+	}
+
+	for _, info := range []CapacityInfo{capacity.Data, capacity.Metadata, capacity.Snapshot} {
+		summary.AllocatedBytes += info.AllocatedBytes
+		summary.ConsumedBytes += info.ConsumedBytes
+		summary.GuaranteedBytes += info.GuaranteedBytes
+		summary.ProvisionedBytes += info.ProvisionedBytes
+	}
+
+	summary.AvailableBytes = capacity.Data.AllocatedBytes - capacity.Data.ConsumedBytes
+	if summary.AvailableBytes < 0 {
+		summary.AvailableBytes = 0
+	}
+
+	return summary
+}
+
+// UtilizationPercent returns how much of the allocated capacity is consumed.
+func (capacity *PoolCapacity) UtilizationPercent() float64 {
+	if capacity.AllocatedBytes == 0 {
+		return 0
+	}
+	return float64(capacity.ConsumedBytes) * 100 / float64(capacity.AllocatedBytes)
+}
+
+// CapacitySummary aggregates the capacity of a set of storage pools.
+type CapacitySummary struct {
+	// Pools contains the capacity of each individual pool.
+	Pools []PoolCapacity
+	// AllocatedBytes is the total capacity allocated to all pools.
+	AllocatedBytes int64
+	// ConsumedBytes is the total capacity consumed in all pools.
+	ConsumedBytes int64
+	// GuaranteedBytes is the total capacity guaranteed in all pools.
+	GuaranteedBytes int64
+	// ProvisionedBytes is the total capacity promised by all pools.
+	ProvisionedBytes int64
+	// AvailableBytes is the total data capacity that can still be consumed.
+	AvailableBytes int64
+}
+
+// NewCapacitySummary aggregates the capacity of a set of storage pools.
+func NewCapacitySummary(pools []*StoragePool) *CapacitySummary {
+	summary := &CapacitySummary{}
+	for _, pool := range pools {
+		capacity := NewPoolCapacity(pool)
+		summary.Pools = append(summary.Pools, capacity)
+		summary.AllocatedBytes += capacity.AllocatedBytes
+		summary.ConsumedBytes += capacity.ConsumedBytes
+		summary.GuaranteedBytes += capacity.GuaranteedBytes
+		summary.ProvisionedBytes += capacity.ProvisionedBytes
+		summary.AvailableBytes += capacity.AvailableBytes
+	}
+	return summary
+}
+
+// CapacitySummary aggregates the capacity of all storage pools of this
+// storage service.
+func (storageservice *StorageService) CapacitySummary() (*CapacitySummary, error) {
+	pools, err := storageservice.StoragePools()
+	if err != nil {
+		return nil, err
+	}
+	return NewCapacitySummary(pools), nil
+}
+
+// CapacityRequirement describes the capacity and service level a new
+// allocation needs.
+type CapacityRequirement struct {
+	// CapacityBytes is the size of the allocation.
+	CapacityBytes int64
+	// ClassOfService is the ID or name of the class of service the
+	// allocation must conform to. If empty, any class of service is accepted.
+	ClassOfService string
+	// RequireReplication requires the class of service to include at least
+	// one data protection line of service.
+	RequireReplication bool
+	// AllowOvercommit allows thin provisioned pools to be selected even when
+	// they do not have enough free capacity for the whole allocation.
+	AllowOvercommit bool
+}
+
+// PoolCandidate is the evaluation of a storage pool against a capacity
+// requirement.
+type PoolCandidate struct {
+	// Capacity is the capacity summary of the pool.
+	Capacity PoolCapacity
+	// ClassOfService is the matching class of service supported by the pool.
+	ClassOfService *ClassOfService
+	// Eligible indicates the pool can host the allocation.
+	Eligible bool
+	// Reasons explains why the pool is not eligible.
+	Reasons []string
+	// RemainingBytes is the available capacity left after the allocation.
+	RemainingBytes int64
+	// Metrics are the metrics of the pool, if the pool reports them.
+	Metrics *StoragePoolMetrics
+	// MetricsError is the error reading the metrics of the pool, if any.
+	MetricsError error
+}
+
+// uncorrectableErrorCount returns the number of errors the pool could not
+// recover from, as reported by its metrics.
+func (candidate *PoolCandidate) uncorrectableErrorCount() int {
+	if candidate.Metrics == nil {
+		return 0
+	}
+	return candidate.Metrics.UncorrectableIOReadErrorCount +
+		candidate.Metrics.UncorrectableIOWriteErrorCount +
+		candidate.Metrics.RebuildErrorCount
+}
+
+// mayHaveErrors reports whether the pool reported uncorrectable errors or
+// its metrics could not be read.
+func (candidate *PoolCandidate) mayHaveErrors() bool {
+	return candidate.MetricsError != nil || candidate.uncorrectableErrorCount() > 0
+}
+
+// matchesClassOfService checks a class of service against the requested ID or
+// name and replication requirement.
+func matchesClassOfService(classOfService *ClassOfService, requirement *CapacityRequirement) bool {
+	if requirement.ClassOfService != "" &&
+		!strings.EqualFold(classOfService.ID, requirement.ClassOfService) &&
+		!strings.EqualFold(classOfService.Name, requirement.ClassOfService) {
+		return false
+	}
+
+	if requirement.RequireReplication &&
+		classOfService.DataProtectionLinesOfServiceCount == 0 &&
+		len(classOfService.dataProtectionLinesOfService) == 0 {
+		return false
+	}
+
+	return true
+}
+
+// EvaluatePool checks whether a storage pool, offering the given classes of
+// service, can host an allocation.
+func EvaluatePool(pool *StoragePool, classesOfService []*ClassOfService, requirement *CapacityRequirement) PoolCandidate {
+	candidate := PoolCandidate{Capacity: NewPoolCapacity(pool)}
+	candidate.RemainingBytes = candidate.Capacity.AvailableBytes - requirement.CapacityBytes
+
+	if pool.Status.State != "" && pool.Status.State != common.EnabledState {
+		candidate.Reasons = append(candidate.Reasons, fmt.Sprintf("pool state is %s", pool.Status.State))
+	}
+	if pool.Status.Health == common.CriticalHealth {
+		candidate.Reasons = append(candidate.Reasons, "pool health is critical")
+	}
+
+	if candidate.RemainingBytes < 0 && !(requirement.AllowOvercommit && candidate.Capacity.IsThinProvisioned) {
+		candidate.Reasons = append(candidate.Reasons, fmt.Sprintf("only %d bytes available, %d requested",
+			candidate.Capacity.AvailableBytes, requirement.CapacityBytes))
+	}
+
+	if requirement.ClassOfService != "" || requirement.RequireReplication {
+		for _, classOfService := range classesOfService {
+			if matchesClassOfService(classOfService, requirement) {
+				candidate.ClassOfService = classOfService
+				break
+			}
+		}
+		if candidate.ClassOfService == nil {
+			candidate.Reasons = append(candidate.Reasons, "no supported class of service matches the requested service level")
+		}
+	}
+
+	candidate.Eligible = len(candidate.Reasons) == 0
+	return candidate
+}
+
+// RankPools orders pool candidates so that eligible pools come first. Among
+// those, pools whose metrics report no uncorrectable IO or rebuild errors come
+// before pools that do or whose metrics could not be read, and then the pools
+// that are least utilized after the allocation come first to spread
+// consumption across pools.
+func RankPools(candidates []PoolCandidate) {
+	utilizationAfter := func(candidate *PoolCandidate) float64 {
+		allocated := candidate.Capacity.AllocatedBytes
+		if allocated == 0 {
+			return 100
+		}
+		return float64(allocated-candidate.RemainingBytes) * 100 / float64(allocated)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Eligible != candidates[j].Eligible {
+			return candidates[i].Eligible
+		}
+		iErrors, jErrors := candidates[i].mayHaveErrors(), candidates[j].mayHaveErrors()
+		if iErrors != jErrors {
+			return jErrors
+		}
+		return utilizationAfter(&candidates[i]) < utilizationAfter(&candidates[j])
+	})
+}
+
+// PlanCapacity evaluates all storage pools of this storage service against a
+// capacity requirement and returns them ranked from best to worst. The metrics
+// of each pool are read to rank pools with uncorrectable errors last. A pool
+// whose metrics cannot be read is ranked with them and the error is kept in
+// its MetricsError. Pools that cannot host the allocation are included with
+// the reasons why.
+func (storageservice *StorageService) PlanCapacity(requirement *CapacityRequirement) ([]PoolCandidate, error) {
+	if requirement.CapacityBytes <= 0 {
+		return nil, errors.New("a capacity is required to plan an allocation")
+	}
+
+	pools, err := storageservice.StoragePools()
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]PoolCandidate, 0, len(pools))
+	for _, pool := range pools {
+		var classesOfService []*ClassOfService
+		if requirement.ClassOfService != "" || requirement.RequireReplication {
+			classesOfService, err = pool.supportedClassesOfService()
+			if err != nil {
+				return nil, err
+			}
+		}
+		candidate := EvaluatePool(pool, classesOfService, requirement)
+		candidate.Metrics, candidate.MetricsError = pool.Metrics()
+		candidates = append(candidates, candidate)
+	}
+
+	RankPools(candidates)
+	return candidates, nil
+}
+
+// supportedClassesOfService gets the classes of service of a pool, falling
+// back to its default class of service.
+func (storagepool *StoragePool) supportedClassesOfService() ([]*ClassOfService, error) {
+	if storagepool.classesOfService != "" {
+		return storagepool.ClassesOfService()
+	}
+
+	classOfService, err := storagepool.DefaultClassOfService()
+	if err != nil || classOfService == nil {
+		return nil, err
+	}
+	return []*ClassOfService{classOfService}, nil
+}
@@ -0,0 +1,240 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package swordfish
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+const tebibyte = 1024 * 1024 * 1024 * 1024
+
+func capacityPool(t *testing.T, id string, allocated, consumed int64, thin bool) *StoragePool {
+	var result StoragePool
+	if err := json.NewDecoder(strings.NewReader(provisioningPoolBody)).Decode(&result); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	result.ID = id
+	result.Capacity.Data.AllocatedBytes = allocated
+	result.Capacity.Data.ConsumedBytes = consumed
+	result.Capacity.Metadata.AllocatedBytes = 1024
+	result.Capacity.Metadata.ConsumedBytes = 512
+	result.Capacity.IsThinProvisioned = thin
+	result.Status.State = common.EnabledState
+	return &result
+}
+
+// TestCapacitySummary tests aggregating capacity across pools.
+func TestCapacitySummary(t *testing.T) {
+	summary := NewCapacitySummary([]*StoragePool{
+		capacityPool(t, "1", 4*tebibyte, 1*tebibyte, false),
+		capacityPool(t, "2", 2*tebibyte, 3*tebibyte, true),
+	})
+
+	if summary.AllocatedBytes != 6*tebibyte+2048 {
+		t.Errorf("Unexpected allocated bytes: %d", summary.AllocatedBytes)
+	}
+
+	if summary.ConsumedBytes != 4*tebibyte+1024 {
+		t.Errorf("Unexpected consumed bytes: %d", summary.ConsumedBytes)
+	}
+
+	// The over consumed thin pool has no available capacity left
+	if summary.AvailableBytes != 3*tebibyte {
+		t.Errorf("Unexpected available bytes: %d", summary.AvailableBytes)
+	}
+}
+
+// TestRankPools tests ranking pools for a requested size and service level.
+func TestRankPools(t *testing.T) {
+	gold := classOfService("/redfish/v1/StorageServices/1/ClassesOfService/Gold", "Gold")
+	gold.DataProtectionLinesOfServiceCount = 1
+	silver := classOfService("/redfish/v1/StorageServices/1/ClassesOfService/Silver", "Silver")
+
+	requirement := &CapacityRequirement{
+		CapacityBytes:      2 * tebibyte,
+		ClassOfService:     "gold",
+		RequireReplication: true,
+	}
+
+	degraded := capacityPool(t, "degraded", 10*tebibyte, 0, false)
+	degraded.Status.State = common.DisabledState
+
+	candidates := []PoolCandidate{
+		EvaluatePool(capacityPool(t, "small", 3*tebibyte, 2*tebibyte, false), []*ClassOfService{gold}, requirement),
+		EvaluatePool(capacityPool(t, "busy", 8*tebibyte, 5*tebibyte, false), []*ClassOfService{silver, gold}, requirement),
+		EvaluatePool(capacityPool(t, "silver", 10*tebibyte, 0, false), []*ClassOfService{silver}, requirement),
+		EvaluatePool(capacityPool(t, "empty", 8*tebibyte, 1*tebibyte, false), []*ClassOfService{gold}, requirement),
+		EvaluatePool(degraded, []*ClassOfService{gold}, requirement),
+	}
+	RankPools(candidates)
+
+	expected := []struct {
+		id       string
+		eligible bool
+	}{
+		{"empty", true},
+		{"busy", true},
+		{"silver", false},
+		{"degraded", false},
+		{"small", false},
+	}
+
+	for i, e := range expected {
+		candidate := candidates[i]
+		if candidate.Capacity.Pool.ID != e.id {
+			t.Errorf("Position %d: expected pool %s, got %s", i, e.id, candidate.Capacity.Pool.ID)
+		}
+		if candidate.Eligible != e.eligible {
+			t.Errorf("Pool %s: expected eligible %t, got %t (%v)", candidate.Capacity.Pool.ID, e.eligible, candidate.Eligible, candidate.Reasons)
+		}
+		if candidate.Eligible && candidate.ClassOfService != gold {
+			t.Errorf("Pool %s: expected Gold class of service", candidate.Capacity.Pool.ID)
+		}
+	}
+}
+
+// TestRankPoolsOvercommit tests that thin pools can be overcommitted.
+func TestRankPoolsOvercommit(t *testing.T) {
+	pool := capacityPool(t, "thin", 1*tebibyte, 0, true)
+
+	candidate := EvaluatePool(pool, nil, &CapacityRequirement{CapacityBytes: 2 * tebibyte})
+	if candidate.Eligible {
+		t.Error("Thin pool should not be overcommitted by default")
+	}
+
+	candidate = EvaluatePool(pool, nil, &CapacityRequirement{CapacityBytes: 2 * tebibyte, AllowOvercommit: true})
+	if !candidate.Eligible {
+		t.Errorf("Thin pool should allow overcommit: %v", candidate.Reasons)
+	}
+}
+
+// TestRankPoolsMetrics tests that pools with uncorrectable errors are ranked
+// after healthy pools.
+func TestRankPoolsMetrics(t *testing.T) {
+	requirement := &CapacityRequirement{CapacityBytes: tebibyte}
+
+	failing := EvaluatePool(capacityPool(t, "failing", 8*tebibyte, 0, false), nil, requirement)
+	failing.Metrics = &StoragePoolMetrics{UncorrectableIOReadErrorCount: 1}
+	busy := EvaluatePool(capacityPool(t, "busy", 8*tebibyte, 4*tebibyte, false), nil, requirement)
+	busy.Metrics = &StoragePoolMetrics{CorrectableIOReadErrorCount: 10}
+
+	candidates := []PoolCandidate{failing, busy}
+	RankPools(candidates)
+
+	if candidates[0].Capacity.Pool.ID != "busy" || !candidates[1].Eligible {
+		t.Errorf("Expected the pool with uncorrectable errors to be ranked last: %s", candidates[0].Capacity.Pool.ID)
+	}
+}
+
+// TestPlanCapacity tests planning against the pools of a storage service.
+func TestPlanCapacity(t *testing.T) {
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {
+				common.TestResponse(http.StatusOK, `{
+					"Members": [{"@odata.id": "/redfish/v1/StorageServices/1/StoragePools/1"}],
+					"Members@odata.count": 1
+				}`),
+				common.TestResponse(http.StatusOK, `{
+					"@odata.id": "/redfish/v1/StorageServices/1/StoragePools/1",
+					"Id": "1",
+					"Capacity": {"Data": {"AllocatedBytes": 4398046511104, "ConsumedBytes": 1099511627776}},
+					"Status": {"State": "Enabled", "Health": "OK"},
+					"ClassesOfService": {
+						"@odata.id": "/redfish/v1/StorageServices/1/StoragePools/1/ClassesOfService"
+					},
+					"Metrics": {
+						"@odata.id": "/redfish/v1/StorageServices/1/StoragePools/1/Metrics"
+					}
+				}`),
+				common.TestResponse(http.StatusOK, poolClassesOfServiceCollection),
+				common.TestResponse(http.StatusOK, `{
+					"@odata.id": "/redfish/v1/StorageServices/1/ClassesOfService/Gold",
+					"Id": "Gold",
+					"Name": "Gold",
+					"DataProtectionLinesOfService": [{"@odata.id": "/redfish/v1/StorageServices/1/DataProtectionLoS/1"}]
+				}`),
+				common.TestResponse(http.StatusOK, `{
+					"@odata.id": "/redfish/v1/StorageServices/1/StoragePools/1/Metrics",
+					"Id": "Metrics",
+					"RebuildErrorCount": 2
+				}`),
+			},
+		},
+	}
+	service := provisioningService(t, testClient)
+
+	candidates, err := service.PlanCapacity(&CapacityRequirement{
+		CapacityBytes:      2 * tebibyte,
+		ClassOfService:     "Gold",
+		RequireReplication: true,
+	})
+	if err != nil {
+		t.Fatalf("Error making PlanCapacity call: %s", err)
+	}
+
+	if len(candidates) != 1 || !candidates[0].Eligible {
+		t.Fatalf("Expected one eligible pool, got: %v", candidates)
+	}
+
+	if candidates[0].RemainingBytes != tebibyte {
+		t.Errorf("Unexpected remaining bytes: %d", candidates[0].RemainingBytes)
+	}
+
+	if candidates[0].Metrics == nil || candidates[0].uncorrectableErrorCount() != 2 {
+		t.Errorf("Unexpected pool metrics: %+v", candidates[0].Metrics)
+	}
+}
+
+// TestPlanCapacityMetricsError tests that a pool whose metrics cannot be read
+// is ranked last instead of failing the plan.
+func TestPlanCapacityMetricsError(t *testing.T) {
+	// The pools are read concurrently, so they only differ in their ID
+	pool := `{
+		"@odata.id": "/redfish/v1/StorageServices/1/StoragePools/%[1]s",
+		"Id": "%[1]s",
+		"Capacity": {"Data": {"AllocatedBytes": 4398046511104}},
+		"Metrics": {"@odata.id": "/redfish/v1/StorageServices/1/StoragePools/%[1]s/Metrics"}
+	}`
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {
+				common.TestResponse(http.StatusOK, `{
+					"Members": [
+						{"@odata.id": "/redfish/v1/StorageServices/1/StoragePools/1"},
+						{"@odata.id": "/redfish/v1/StorageServices/1/StoragePools/2"}
+					],
+					"Members@odata.count": 2
+				}`),
+				common.TestResponse(http.StatusOK, fmt.Sprintf(pool, "1")),
+				common.TestResponse(http.StatusOK, fmt.Sprintf(pool, "2")),
+				common.TestResponse(http.StatusInternalServerError, `{"error": {"code": "Base.1.0.InternalError", "message": "metrics unavailable"}}`),
+				common.TestResponse(http.StatusOK, `{"@odata.id": "/redfish/v1/StorageServices/1/StoragePools/1/Metrics", "Id": "Metrics"}`),
+			},
+		},
+	}
+	service := provisioningService(t, testClient)
+
+	candidates, err := service.PlanCapacity(&CapacityRequirement{CapacityBytes: tebibyte})
+	if err != nil {
+		t.Fatalf("Error making PlanCapacity call: %s", err)
+	}
+
+	if len(candidates) != 2 {
+		t.Fatalf("Expected two pools, got: %+v", candidates)
+	}
+	if candidates[0].MetricsError != nil || candidates[0].Metrics == nil {
+		t.Errorf("Expected the pool with metrics to be ranked first: %+v", candidates[0])
+	}
+	if candidates[1].MetricsError == nil || !candidates[1].Eligible {
+		t.Errorf("Unexpected candidate: %+v", candidates[1])
+	}
+}
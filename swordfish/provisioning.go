@@ -0,0 +1,192 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package swordfish
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+// collectionContains checks whether a resource is a member of a collection.
+// If the collection is not advertised, there is nothing to check against and
+// the resource is assumed to be a member.
+func collectionContains(c common.Client, collection, uri string) (bool, error) {
+	if collection == "" {
+		return true, nil
+	}
+
+	members, err := common.GetCollection(c, collection)
+	if err != nil {
+		return false, err
+	}
+
+	for _, member := range members.ItemLinks {
+		if strings.TrimSuffix(member, "/") == strings.TrimSuffix(uri, "/") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// validateClassOfService makes sure a class of service is offered by the
+// storage service and, if a pool is given, supported by that pool.
+func (storageservice *StorageService) validateClassOfService(classOfService *ClassOfService, pool *StoragePool) error {
+	if classOfService == nil {
+		return nil
+	}
+
+	found, err := collectionContains(storageservice.GetClient(), storageservice.classesOfService, classOfService.ODataID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("class of service '%s' is not offered by this storage service", classOfService.ID)
+	}
+
+	if pool == nil {
+		return nil
+	}
+
+	supported, err := pool.SupportsClassOfService(classOfService)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return fmt.Errorf("class of service '%s' is not supported by storage pool '%s'", classOfService.ID, pool.ID)
+	}
+	return nil
+}
+
+// validateDataStorage makes sure the requested provisioning policy and access
+// capabilities are within the data storage capabilities of the service.
+func (storageservice *StorageService) validateDataStorage(policy ProvisioningPolicy, accessCapabilities []StorageAccessCapability) error {
+	if storageservice.dataStorageLoSCapabilities == "" || (policy == "" && len(accessCapabilities) == 0) {
+		return nil
+	}
+
+	capabilities, err := storageservice.DataStorageLoSCapabilities()
+	if err != nil {
+		return err
+	}
+
+	if policy != "" && len(capabilities.SupportedProvisioningPolicies) > 0 {
+		supported := false
+		for _, supportedPolicy := range capabilities.SupportedProvisioningPolicies {
+			if supportedPolicy == policy {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return fmt.Errorf("provisioning policy '%s' is not supported by this storage service", policy)
+		}
+	}
+
+	if len(capabilities.SupportedAccessCapabilities) == 0 {
+		return nil
+	}
+
+	for _, access := range accessCapabilities {
+		supported := false
+		for _, supportedAccess := range capabilities.SupportedAccessCapabilities {
+			if supportedAccess == access {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return fmt.Errorf("access capability '%s' is not supported by this storage service", access)
+		}
+	}
+	return nil
+}
+
+// accessProtocol maps a file sharing protocol to the access protocol
+// advertised in the IO connectivity capabilities.
+func (protocol FileProtocol) accessProtocol() common.Protocol {
+	switch {
+	case protocol == NFSv3FileProtocol:
+		return common.NFSv3Protocol
+	case strings.HasPrefix(string(protocol), "NFSv4"):
+		return common.NFSv4Protocol
+	case strings.HasPrefix(string(protocol), "SMB"):
+		return common.SMBProtocol
+	}
+	return common.Protocol(protocol)
+}
+
+// validateFileProtocols makes sure the file sharing protocols are within the
+// IO connectivity capabilities of the service.
+func (storageservice *StorageService) validateFileProtocols(protocols []FileProtocol) error {
+	if storageservice.ioConnectivityLoSCapabilities == "" || len(protocols) == 0 {
+		return nil
+	}
+
+	capabilities, err := storageservice.IOConnectivityLoSCapabilities()
+	if err != nil {
+		return err
+	}
+	if len(capabilities.SupportedAccessProtocols) == 0 {
+		return nil
+	}
+
+	for _, protocol := range protocols {
+		supported := false
+		for _, supportedProtocol := range capabilities.SupportedAccessProtocols {
+			if supportedProtocol == protocol.accessProtocol() {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return fmt.Errorf("file sharing protocol '%s' is not supported by this storage service", protocol)
+		}
+	}
+	return nil
+}
+
+// capacitySourceRequest is the request representation of a capacity source.
+type capacitySourceRequest struct {
+	ProvidingDrives []common.IDRef `json:",omitempty"`
+	ProvidingPools  []common.IDRef `json:",omitempty"`
+}
+
+// capacityRequest is the request representation of a capacity.
+type capacityRequest struct {
+	Data struct {
+		AllocatedBytes int64
+	}
+}
+
+// newCapacityRequest creates a capacity request for the number of bytes, or
+// nil if no size was requested.
+func newCapacityRequest(allocatedBytes int64) *capacityRequest {
+	if allocatedBytes == 0 {
+		return nil
+	}
+
+	capacity := &capacityRequest{}
+	capacity.Data.AllocatedBytes = allocatedBytes
+	return capacity
+}
+
+// poolCapacitySources creates the capacity sources for a resource allocated
+// from a storage pool, or nil if no pool was requested.
+func poolCapacitySources(pool *StoragePool) []capacitySourceRequest {
+	if pool == nil {
+		return nil
+	}
+	return []capacitySourceRequest{{ProvidingPools: common.IDRefs([]string{pool.ODataID})}}
+}
+
+// idRef creates a reference to a resource, or nil if there is none.
+func idRef(uri string) *common.IDRef {
+	if uri == "" {
+		return nil
+	}
+	return &common.IDRef{ODataID: uri}
+}
@@ -0,0 +1,294 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package swordfish
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stmcginnis/gofish/common"
+)
+
+var provisioningServiceBody = `{
+		"@odata.id": "/redfish/v1/StorageServices/1",
+		"Id": "1",
+		"Name": "Storage Service",
+		"ClassesOfService": {
+			"@odata.id": "/redfish/v1/StorageServices/1/ClassesOfService"
+		},
+		"DataStorageLoSCapabilities": {
+			"@odata.id": "/redfish/v1/StorageServices/1/DataStorageLoSCapabilities"
+		},
+		"IOConnectivityLoSCapabilities": {
+			"@odata.id": "/redfish/v1/StorageServices/1/IOConnectivityLoSCapabilities"
+		},
+		"FileSystems": {
+			"@odata.id": "/redfish/v1/StorageServices/1/FileSystems"
+		},
+		"StorageGroups": {
+			"@odata.id": "/redfish/v1/StorageServices/1/StorageGroups"
+		},
+		"StoragePools": {
+			"@odata.id": "/redfish/v1/StorageServices/1/StoragePools"
+		},
+		"Volumes": {
+			"@odata.id": "/redfish/v1/StorageServices/1/Volumes"
+		}
+	}`
+
+var provisioningPoolBody = `{
+		"@odata.id": "/redfish/v1/StorageServices/1/StoragePools/1",
+		"Id": "1",
+		"Name": "Pool",
+		"ClassesOfService": {
+			"@odata.id": "/redfish/v1/StorageServices/1/StoragePools/1/ClassesOfService"
+		}
+	}`
+
+var classesOfServiceCollection = `{
+		"Members": [
+			{"@odata.id": "/redfish/v1/StorageServices/1/ClassesOfService/Gold"},
+			{"@odata.id": "/redfish/v1/StorageServices/1/ClassesOfService/Silver"}
+		],
+		"Members@odata.count": 2
+	}`
+
+var poolClassesOfServiceCollection = `{
+		"Members": [
+			{"@odata.id": "/redfish/v1/StorageServices/1/ClassesOfService/Gold"}
+		],
+		"Members@odata.count": 1
+	}`
+
+func provisioningService(t *testing.T, testClient *common.TestClient) *StorageService {
+	var result StorageService
+	if err := json.NewDecoder(strings.NewReader(provisioningServiceBody)).Decode(&result); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	result.SetClient(testClient)
+	return &result
+}
+
+func provisioningPool(t *testing.T, testClient *common.TestClient) *StoragePool {
+	var result StoragePool
+	if err := json.NewDecoder(strings.NewReader(provisioningPoolBody)).Decode(&result); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	result.SetClient(testClient)
+	return &result
+}
+
+func classOfService(uri, id string) *ClassOfService {
+	result := &ClassOfService{}
+	result.ODataID = uri
+	result.ID = id
+	return result
+}
+
+// TestCreateVolume tests creating a volume from a storage pool.
+func TestCreateVolume(t *testing.T) {
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {
+				common.TestResponse(http.StatusOK, classesOfServiceCollection),
+				common.TestResponse(http.StatusOK, poolClassesOfServiceCollection),
+				common.TestResponse(http.StatusOK, `{"SupportedProvisioningPolicies": ["Thin"]}`),
+			},
+			http.MethodPost: {
+				common.TestResponse(http.StatusCreated, `{"@odata.id": "/redfish/v1/StorageServices/1/Volumes/7", "Id": "7", "CapacityBytes": 2199023255552}`),
+			},
+		},
+	}
+	service := provisioningService(t, testClient)
+
+	volume, task, err := service.CreateVolume(&CreateVolumeParameters{
+		Name:                 "data",
+		CapacityBytes:        2199023255552,
+		ProvisioningPolicy:   ThinProvisioningPolicy,
+		ClassOfService:       classOfService("/redfish/v1/StorageServices/1/ClassesOfService/Gold", "Gold"),
+		ProvidingStoragePool: provisioningPool(t, testClient),
+	})
+	if err != nil {
+		t.Fatalf("Error making CreateVolume call: %s", err)
+	}
+
+	if task != nil {
+		t.Errorf("Unexpected task returned: %v", task)
+	}
+
+	if volume.ID != "7" {
+		t.Errorf("Unexpected volume ID: %s", volume.ID)
+	}
+
+	calls := testClient.CapturedCalls()
+	post := calls[len(calls)-1]
+
+	if post.URL != "/redfish/v1/StorageServices/1/Volumes" {
+		t.Errorf("Unexpected CreateVolume URL: %s", post.URL)
+	}
+
+	if !strings.Contains(post.Payload, "ProvidingPools:[map[@odata.id:/redfish/v1/StorageServices/1/StoragePools/1]]") {
+		t.Errorf("Unexpected capacity sources in payload: %s", post.Payload)
+	}
+
+	if !strings.Contains(post.Payload, "Links:map[ClassOfService:map[@odata.id:/redfish/v1/StorageServices/1/ClassesOfService/Gold]]") {
+		t.Errorf("Unexpected class of service in payload: %s", post.Payload)
+	}
+}
+
+// TestCreateVolumeUnsupportedClassOfService tests a class of service the pool does not support.
+func TestCreateVolumeUnsupportedClassOfService(t *testing.T) {
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {
+				common.TestResponse(http.StatusOK, classesOfServiceCollection),
+				common.TestResponse(http.StatusOK, poolClassesOfServiceCollection),
+			},
+		},
+	}
+	service := provisioningService(t, testClient)
+
+	_, _, err := service.CreateVolume(&CreateVolumeParameters{
+		CapacityBytes:        1024,
+		ClassOfService:       classOfService("/redfish/v1/StorageServices/1/ClassesOfService/Silver", "Silver"),
+		ProvidingStoragePool: provisioningPool(t, testClient),
+	})
+	if err == nil || !strings.Contains(err.Error(), "not supported by storage pool") {
+		t.Errorf("Expected class of service to be rejected, got: %v", err)
+	}
+
+	for _, call := range testClient.CapturedCalls() {
+		if call.Action == http.MethodPost {
+			t.Errorf("No create request should be sent: %v", call)
+		}
+	}
+}
+
+// TestCreateFileShare tests creating a file share and validating its protocols.
+func TestCreateFileShare(t *testing.T) {
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodGet: {
+				common.TestResponse(http.StatusOK, `{"SupportedAccessProtocols": ["NFSv4"]}`),
+				common.TestResponse(http.StatusOK, `{"SupportedAccessProtocols": ["NFSv4"]}`),
+			},
+			http.MethodPost: {
+				common.TestResponse(http.StatusCreated, `{"@odata.id": "/redfish/v1/StorageServices/1/FileSystems/1/ExportedFileShares/2", "Id": "2"}`),
+			},
+		},
+	}
+	service := provisioningService(t, testClient)
+
+	fileSystem := &FileSystem{exportedShares: "/redfish/v1/StorageServices/1/FileSystems/1/ExportedFileShares"}
+
+	_, _, err := service.CreateFileShare(fileSystem, &CreateFileShareParameters{
+		Name:                 "smb",
+		FileSharingProtocols: []FileProtocol{SMBv30FileProtocol},
+	})
+	if err == nil {
+		t.Error("Expected SMB share to be rejected")
+	}
+
+	share, _, err := service.CreateFileShare(fileSystem, &CreateFileShareParameters{
+		Name:                 "nfs",
+		FileSharePath:        "/export/nfs",
+		FileSharingProtocols: []FileProtocol{NFSv41FileProtocol},
+		RootAccess:           true,
+	})
+	if err != nil {
+		t.Fatalf("Error making CreateFileShare call: %s", err)
+	}
+
+	if share.ID != "2" {
+		t.Errorf("Unexpected file share ID: %s", share.ID)
+	}
+
+	calls := testClient.CapturedCalls()
+	post := calls[len(calls)-1]
+
+	if !strings.Contains(post.Payload, "FileSharingProtocols:[NFSv4_1]") {
+		t.Errorf("Unexpected protocols in payload: %s", post.Payload)
+	}
+}
+
+// TestCreateStorageGroup tests creating and exposing a storage group.
+func TestCreateStorageGroup(t *testing.T) {
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodPost: {
+				common.TestResponse(http.StatusCreated, `{
+					"@odata.id": "/redfish/v1/StorageServices/1/StorageGroups/3",
+					"Id": "3",
+					"Actions": {
+						"#StorageGroup.ExposeVolumes": {
+							"target": "/redfish/v1/StorageServices/1/StorageGroups/3/Actions/StorageGroup.ExposeVolumes"
+						}
+					}
+				}`),
+				nil,
+			},
+		},
+	}
+	service := provisioningService(t, testClient)
+
+	group, task, err := service.CreateStorageGroup(&CreateStorageGroupParameters{
+		Name: "hosts",
+		MappedVolumes: []MappedVolume{
+			{LogicalUnitNumber: 1, Volume: common.Link("/redfish/v1/StorageServices/1/Volumes/7")},
+		},
+		ExposeVolumes: true,
+	})
+	if err != nil {
+		t.Fatalf("Error making CreateStorageGroup call: %s", err)
+	}
+
+	if task != nil {
+		t.Errorf("Unexpected task returned: %v", task)
+	}
+
+	if !group.VolumesAreExposed {
+		t.Error("Expected volumes to be exposed")
+	}
+
+	calls := testClient.CapturedCalls()
+	if len(calls) != 2 {
+		t.Fatalf("Expected create and expose calls, got: %v", calls)
+	}
+
+	if !strings.Contains(calls[0].Payload, "MappedVolumes:[map[LogicalUnitNumber:1 Volume:map[@odata.id:/redfish/v1/StorageServices/1/Volumes/7]]]") {
+		t.Errorf("Unexpected mapped volumes in payload: %s", calls[0].Payload)
+	}
+
+	if calls[1].URL != "/redfish/v1/StorageServices/1/StorageGroups/3/Actions/StorageGroup.ExposeVolumes" {
+		t.Errorf("Unexpected ExposeVolumes URL: %s", calls[1].URL)
+	}
+}
+
+// TestDeleteStoragePool tests deleting a storage pool asynchronously.
+func TestDeleteStoragePool(t *testing.T) {
+	resp := common.TestResponse(http.StatusAccepted, "")
+	resp.Header.Set("Location", "/redfish/v1/TaskService/Tasks/9")
+
+	testClient := &common.TestClient{
+		CustomReturnForActions: map[string][]interface{}{
+			http.MethodDelete: {resp},
+			http.MethodGet: {
+				common.TestResponse(http.StatusOK, `{"@odata.id": "/redfish/v1/TaskService/Tasks/9", "Id": "9", "TaskState": "Running"}`),
+			},
+		},
+	}
+	service := provisioningService(t, testClient)
+
+	task, err := service.DeleteStoragePool("/redfish/v1/StorageServices/1/StoragePools/1")
+	if err != nil {
+		t.Fatalf("Error making DeleteStoragePool call: %s", err)
+	}
+
+	if task == nil || task.ID != "9" {
+		t.Errorf("Unexpected task: %v", task)
+	}
+}
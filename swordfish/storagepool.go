@@ -473,6 +473,18 @@ func (storagepool *StoragePool) ClassesOfService() ([]*ClassOfService, error) {
 	return ListReferencedClassOfServices(storagepool.GetClient(), storagepool.classesOfService)
 }
 
+// SupportsClassOfService checks whether capacity allocated from this pool can
+// conform to the class of service. If the pool does not list the classes of
+// service it supports, only its default class of service is accepted, and if
+// neither is advertised any class of service is assumed to be supported.
+func (storagepool *StoragePool) SupportsClassOfService(classOfService *ClassOfService) (bool, error) {
+	if storagepool.classesOfService == "" {
+		return storagepool.defaultClassOfService == "" ||
+			storagepool.defaultClassOfService == classOfService.ODataID, nil
+	}
+	return collectionContains(storagepool.GetClient(), storagepool.classesOfService, classOfService.ODataID)
+}
+
 // DefaultClassOfService gets the default ClassOfService for this pool.
 func (storagepool *StoragePool) DefaultClassOfService() (*ClassOfService, error) {
 	if storagepool.defaultClassOfService == "" {
@@ -6,6 +6,7 @@ package swordfish
 
 import (
 	"encoding/json"
+	"errors"
 	"reflect"
 
 	"github.com/stmcginnis/gofish/common"
@@ -422,6 +423,11 @@ func (storageservice *StorageService) StorageGroups() ([]*StorageGroup, error) {
 	return result, collectionError
 }
 
+// StoragePools gets the storage pools that are a part of this storage service.
+func (storageservice *StorageService) StoragePools() ([]*StoragePool, error) {
+	return ListReferencedStoragePools(storageservice.GetClient(), storageservice.storagePools)
+}
+
 // Volumes gets the volumes that are a part of this storage service.
 func (storageservice *StorageService) Volumes() ([]*Volume, error) {
 	return ListReferencedVolumes(storageservice.GetClient(), storageservice.volumes)
@@ -443,3 +449,402 @@ func (storageservice *StorageService) Metrics() (*StorageServiceMetrics, error)
 	}
 	return GetStorageServiceMetrics(storageservice.GetClient(), storageservice.metrics)
 }
+
+// CreateStoragePoolParameters contains the settings for a new storage pool.
+type CreateStoragePoolParameters struct {
+	// Name is the name of the new storage pool.
+	Name string
+	// AllocatedBytes is the capacity to allocate to the pool. If zero, the
+	// service decides the size based on the capacity sources.
+	AllocatedBytes int64
+	// ProvidingDrives are the drives that provide capacity to the pool.
+	ProvidingDrives []*redfish.Drive
+	// ProvidingPools are the storage pools that provide capacity to the pool.
+	ProvidingPools []*StoragePool
+	// DefaultClassOfService is the default class of service for capacity
+	// allocated from the pool.
+	DefaultClassOfService *ClassOfService
+}
+
+// CreateStoragePool creates a new storage pool from the given capacity
+// sources. If the service processes the request asynchronously, the task
+// tracking the operation is returned instead of the pool.
+func (storageservice *StorageService) CreateStoragePool(parameters *CreateStoragePoolParameters) (*StoragePool, *redfish.Task, error) {
+	if storageservice.storagePools == "" {
+		return nil, nil, errors.New("storage pools are not supported by this storage service")
+	}
+	if len(parameters.ProvidingDrives) == 0 && len(parameters.ProvidingPools) == 0 {
+		return nil, nil, errors.New("at least one capacity source is required to create a storage pool")
+	}
+	if err := storageservice.validateClassOfService(parameters.DefaultClassOfService, nil); err != nil {
+		return nil, nil, err
+	}
+
+	var source capacitySourceRequest
+	for _, drive := range parameters.ProvidingDrives {
+		source.ProvidingDrives = append(source.ProvidingDrives, common.IDRef{ODataID: drive.ODataID})
+	}
+	for _, pool := range parameters.ProvidingPools {
+		source.ProvidingPools = append(source.ProvidingPools, common.IDRef{ODataID: pool.ODataID})
+	}
+
+	t := struct {
+		Name                  string                  `json:",omitempty"`
+		Capacity              *capacityRequest        `json:",omitempty"`
+		CapacitySources       []capacitySourceRequest `json:",omitempty"`
+		DefaultClassOfService *common.IDRef           `json:",omitempty"`
+	}{
+		Name:            parameters.Name,
+		Capacity:        newCapacityRequest(parameters.AllocatedBytes),
+		CapacitySources: []capacitySourceRequest{source},
+	}
+	if parameters.DefaultClassOfService != nil {
+		t.DefaultClassOfService = idRef(parameters.DefaultClassOfService.ODataID)
+	}
+
+	var storagePool StoragePool
+	task, err := redfish.CreateResource(storageservice.GetClient(), storageservice.storagePools, t, &storagePool)
+	if err != nil || task != nil {
+		return nil, task, err
+	}
+	storagePool.SetClient(storageservice.GetClient())
+
+	return &storagePool, nil, nil
+}
+
+// DeleteStoragePool deletes a storage pool. If the service processes the
+// deletion asynchronously, the task tracking the operation is returned.
+func (storageservice *StorageService) DeleteStoragePool(storagePoolURI string) (*redfish.Task, error) {
+	return redfish.DeleteResource(storageservice.GetClient(), storagePoolURI)
+}
+
+// CreateVolumeParameters contains the settings for a new volume.
+type CreateVolumeParameters struct {
+	// Name is the name of the new volume.
+	Name string
+	// CapacityBytes is the size of the volume.
+	CapacityBytes int64
+	// RAIDType is the RAID type of the volume, if the service should not
+	// derive it from the class of service.
+	RAIDType RAIDType
+	// ProvisioningPolicy is the provisioning policy of the volume.
+	ProvisioningPolicy ProvisioningPolicy
+	// AccessCapabilities are the supported IO access capabilities.
+	AccessCapabilities []StorageAccessCapability
+	// ClassOfService is the class of service the volume must conform to.
+	ClassOfService *ClassOfService
+	// ProvidingStoragePool is the storage pool to allocate the volume from.
+	ProvidingStoragePool *StoragePool
+}
+
+// CreateVolume creates a new volume. The class of service, provisioning
+// policy and access capabilities are validated against the lines of service
+// capabilities of this service before the request is sent. If the service
+// processes the request asynchronously, the task tracking the operation is
+// returned instead of the volume.
+func (storageservice *StorageService) CreateVolume(parameters *CreateVolumeParameters) (*Volume, *redfish.Task, error) {
+	if storageservice.volumes == "" {
+		return nil, nil, errors.New("volumes are not supported by this storage service")
+	}
+	if parameters.CapacityBytes <= 0 {
+		return nil, nil, errors.New("a capacity is required to create a volume")
+	}
+	if err := storageservice.validateClassOfService(parameters.ClassOfService, parameters.ProvidingStoragePool); err != nil {
+		return nil, nil, err
+	}
+	if err := storageservice.validateDataStorage(parameters.ProvisioningPolicy, parameters.AccessCapabilities); err != nil {
+		return nil, nil, err
+	}
+
+	t := struct {
+		Name               string                    `json:",omitempty"`
+		CapacityBytes      int64                     `json:",omitempty"`
+		RAIDType           RAIDType                  `json:",omitempty"`
+		ProvisioningPolicy ProvisioningPolicy        `json:",omitempty"`
+		AccessCapabilities []StorageAccessCapability `json:",omitempty"`
+		CapacitySources    []capacitySourceRequest   `json:",omitempty"`
+		Links              struct {
+			ClassOfService *common.IDRef `json:",omitempty"`
+		}
+	}{
+		Name:               parameters.Name,
+		CapacityBytes:      parameters.CapacityBytes,
+		RAIDType:           parameters.RAIDType,
+		ProvisioningPolicy: parameters.ProvisioningPolicy,
+		AccessCapabilities: parameters.AccessCapabilities,
+		CapacitySources:    poolCapacitySources(parameters.ProvidingStoragePool),
+	}
+	if parameters.ClassOfService != nil {
+		t.Links.ClassOfService = idRef(parameters.ClassOfService.ODataID)
+	}
+
+	var volume Volume
+	task, err := redfish.CreateResource(storageservice.GetClient(), storageservice.volumes, t, &volume)
+	if err != nil || task != nil {
+		return nil, task, err
+	}
+	volume.SetClient(storageservice.GetClient())
+
+	return &volume, nil, nil
+}
+
+// DeleteVolume deletes a volume. If the service processes the deletion
+// asynchronously, the task tracking the operation is returned.
+func (storageservice *StorageService) DeleteVolume(volumeURI string) (*redfish.Task, error) {
+	return redfish.DeleteResource(storageservice.GetClient(), volumeURI)
+}
+
+// CreateFileSystemParameters contains the settings for a new file system.
+type CreateFileSystemParameters struct {
+	// Name is the name of the new file system.
+	Name string
+	// CapacityBytes is the capacity to allocate to the file system.
+	CapacityBytes int64
+	// AccessCapabilities are the supported IO access capabilities.
+	AccessCapabilities []StorageAccessCapability
+	// CharacterCodeSet are the character sets or encodings supported by the
+	// file system.
+	CharacterCodeSet []CharacterCodeSet
+	// ClassOfService is the class of service the file system must conform to.
+	ClassOfService *ClassOfService
+	// ProvidingStoragePool is the storage pool to allocate the file system
+	// from.
+	ProvidingStoragePool *StoragePool
+}
+
+// CreateFileSystem creates a new file system. If the service processes the
+// request asynchronously, the task tracking the operation is returned instead
+// of the file system.
+func (storageservice *StorageService) CreateFileSystem(parameters *CreateFileSystemParameters) (*FileSystem, *redfish.Task, error) {
+	if storageservice.fileSystems == "" {
+		return nil, nil, errors.New("file systems are not supported by this storage service")
+	}
+	if err := storageservice.validateClassOfService(parameters.ClassOfService, parameters.ProvidingStoragePool); err != nil {
+		return nil, nil, err
+	}
+	if err := storageservice.validateDataStorage("", parameters.AccessCapabilities); err != nil {
+		return nil, nil, err
+	}
+
+	t := struct {
+		Name               string                    `json:",omitempty"`
+		Capacity           *capacityRequest          `json:",omitempty"`
+		AccessCapabilities []StorageAccessCapability `json:",omitempty"`
+		CharacterCodeSet   []CharacterCodeSet        `json:",omitempty"`
+		CapacitySources    []capacitySourceRequest   `json:",omitempty"`
+		Links              struct {
+			ClassOfService *common.IDRef `json:",omitempty"`
+		}
+	}{
+		Name:               parameters.Name,
+		Capacity:           newCapacityRequest(parameters.CapacityBytes),
+		AccessCapabilities: parameters.AccessCapabilities,
+		CharacterCodeSet:   parameters.CharacterCodeSet,
+		CapacitySources:    poolCapacitySources(parameters.ProvidingStoragePool),
+	}
+	if parameters.ClassOfService != nil {
+		t.Links.ClassOfService = idRef(parameters.ClassOfService.ODataID)
+	}
+
+	var fileSystem FileSystem
+	task, err := redfish.CreateResource(storageservice.GetClient(), storageservice.fileSystems, t, &fileSystem)
+	if err != nil || task != nil {
+		return nil, task, err
+	}
+	fileSystem.SetClient(storageservice.GetClient())
+
+	return &fileSystem, nil, nil
+}
+
+// DeleteFileSystem deletes a file system. If the service processes the
+// deletion asynchronously, the task tracking the operation is returned.
+func (storageservice *StorageService) DeleteFileSystem(fileSystemURI string) (*redfish.Task, error) {
+	return redfish.DeleteResource(storageservice.GetClient(), fileSystemURI)
+}
+
+// CreateFileShareParameters contains the export settings for a new file share.
+type CreateFileShareParameters struct {
+	// Name is the name of the new file share.
+	Name string
+	// FileSharePath is the path of the share within the file system.
+	FileSharePath string
+	// FileSharingProtocols are the protocols (NFS and/or SMB) the share is
+	// exported through.
+	FileSharingProtocols []FileProtocol
+	// DefaultAccessCapabilities are the default access capabilities of the
+	// share.
+	DefaultAccessCapabilities []StorageAccessCapability
+	// RootAccess controls whether root access is allowed (NFS).
+	RootAccess bool
+	// ExecuteSupport controls whether execute access is allowed.
+	ExecuteSupport bool
+	// CASupported controls whether SMB continuous availability is enabled.
+	CASupported bool
+	// FileShareQuotaType is the type of quota enforced on the share.
+	FileShareQuotaType QuotaType
+	// FileShareTotalQuotaBytes is the maximum number of bytes that can be
+	// written to the share.
+	FileShareTotalQuotaBytes int64
+	// ClassOfService is the class of service the share must conform to.
+	ClassOfService *ClassOfService
+}
+
+// CreateFileShare creates a new file share exported from a file system of this
+// storage service. The file sharing protocols are validated against the IO
+// connectivity capabilities of this service before the request is sent. If
+// the service processes the request asynchronously, the task tracking the
+// operation is returned instead of the file share.
+func (storageservice *StorageService) CreateFileShare(fileSystem *FileSystem, parameters *CreateFileShareParameters) (*FileShare, *redfish.Task, error) {
+	if fileSystem.exportedShares == "" {
+		return nil, nil, errors.New("file shares are not supported by this file system")
+	}
+	if len(parameters.FileSharingProtocols) == 0 {
+		return nil, nil, errors.New("at least one file sharing protocol is required to create a file share")
+	}
+	if err := storageservice.validateClassOfService(parameters.ClassOfService, nil); err != nil {
+		return nil, nil, err
+	}
+	if err := storageservice.validateFileProtocols(parameters.FileSharingProtocols); err != nil {
+		return nil, nil, err
+	}
+	if err := storageservice.validateDataStorage("", parameters.DefaultAccessCapabilities); err != nil {
+		return nil, nil, err
+	}
+
+	t := struct {
+		Name                      string                    `json:",omitempty"`
+		FileSharePath             string                    `json:",omitempty"`
+		FileSharingProtocols      []FileProtocol            `json:",omitempty"`
+		DefaultAccessCapabilities []StorageAccessCapability `json:",omitempty"`
+		RootAccess                bool
+		ExecuteSupport            bool
+		CASupported               bool
+		FileShareQuotaType        QuotaType `json:",omitempty"`
+		FileShareTotalQuotaBytes  int64     `json:",omitempty"`
+		Links                     struct {
+			ClassOfService *common.IDRef `json:",omitempty"`
+		}
+	}{
+		Name:                      parameters.Name,
+		FileSharePath:             parameters.FileSharePath,
+		FileSharingProtocols:      parameters.FileSharingProtocols,
+		DefaultAccessCapabilities: parameters.DefaultAccessCapabilities,
+		RootAccess:                parameters.RootAccess,
+		ExecuteSupport:            parameters.ExecuteSupport,
+		CASupported:               parameters.CASupported,
+		FileShareQuotaType:        parameters.FileShareQuotaType,
+		FileShareTotalQuotaBytes:  parameters.FileShareTotalQuotaBytes,
+	}
+	if parameters.ClassOfService != nil {
+		t.Links.ClassOfService = idRef(parameters.ClassOfService.ODataID)
+	}
+
+	var fileShare FileShare
+	task, err := redfish.CreateResource(storageservice.GetClient(), fileSystem.exportedShares, t, &fileShare)
+	if err != nil || task != nil {
+		return nil, task, err
+	}
+	fileShare.SetClient(storageservice.GetClient())
+
+	return &fileShare, nil, nil
+}
+
+// DeleteFileShare deletes a file share. If the service processes the deletion
+// asynchronously, the task tracking the operation is returned.
+func (storageservice *StorageService) DeleteFileShare(fileShareURI string) (*redfish.Task, error) {
+	return redfish.DeleteResource(storageservice.GetClient(), fileShareURI)
+}
+
+// CreateStorageGroupParameters contains the settings for a new storage group.
+type CreateStorageGroupParameters struct {
+	// Name is the name of the new storage group.
+	Name string
+	// MappedVolumes are the volumes to include in the group.
+	MappedVolumes []MappedVolume
+	// ClientEndpointGroups are the initiator endpoint groups that may access
+	// the volumes.
+	ClientEndpointGroups []*EndpointGroup
+	// ServerEndpointGroups are the target endpoint groups through which the
+	// volumes are exposed.
+	ServerEndpointGroups []*EndpointGroup
+	// AccessState is the access state of the group.
+	AccessState AccessState
+	// AuthenticationMethod is the authentication used by the endpoints.
+	AuthenticationMethod AuthenticationMethod
+	// ClassOfService is the class of service all storage in the group must
+	// conform to.
+	ClassOfService *ClassOfService
+	// ExposeVolumes, if set, exposes the volumes of the group once it has
+	// been created.
+	ExposeVolumes bool
+}
+
+// CreateStorageGroup creates a new storage group. If ExposeVolumes is set, the
+// volumes are exposed through the group after it has been created. If the
+// service processes the request asynchronously, the task tracking the
+// operation is returned instead of the group and the volumes are not exposed.
+func (storageservice *StorageService) CreateStorageGroup(parameters *CreateStorageGroupParameters) (*StorageGroup, *redfish.Task, error) {
+	if storageservice.storageGroups == "" {
+		return nil, nil, errors.New("storage groups are not supported by this storage service")
+	}
+	if err := storageservice.validateClassOfService(parameters.ClassOfService, nil); err != nil {
+		return nil, nil, err
+	}
+
+	type mappedVolume struct {
+		LogicalUnitNumber int
+		Volume            common.IDRef
+	}
+
+	t := struct {
+		Name                 string               `json:",omitempty"`
+		AccessState          AccessState          `json:",omitempty"`
+		AuthenticationMethod AuthenticationMethod `json:",omitempty"`
+		MappedVolumes        []mappedVolume       `json:",omitempty"`
+		ClientEndpointGroups []common.IDRef       `json:",omitempty"`
+		ServerEndpointGroups []common.IDRef       `json:",omitempty"`
+		Links                struct {
+			ClassOfService *common.IDRef `json:",omitempty"`
+		}
+	}{
+		Name:                 parameters.Name,
+		AccessState:          parameters.AccessState,
+		AuthenticationMethod: parameters.AuthenticationMethod,
+	}
+	for _, volume := range parameters.MappedVolumes {
+		t.MappedVolumes = append(t.MappedVolumes, mappedVolume{
+			LogicalUnitNumber: volume.LogicalUnitNumber,
+			Volume:            common.IDRef{ODataID: volume.Volume.String()},
+		})
+	}
+	for _, group := range parameters.ClientEndpointGroups {
+		t.ClientEndpointGroups = append(t.ClientEndpointGroups, common.IDRef{ODataID: group.ODataID})
+	}
+	for _, group := range parameters.ServerEndpointGroups {
+		t.ServerEndpointGroups = append(t.ServerEndpointGroups, common.IDRef{ODataID: group.ODataID})
+	}
+	if parameters.ClassOfService != nil {
+		t.Links.ClassOfService = idRef(parameters.ClassOfService.ODataID)
+	}
+
+	var storageGroup StorageGroup
+	task, err := redfish.CreateResource(storageservice.GetClient(), storageservice.storageGroups, t, &storageGroup)
+	if err != nil || task != nil {
+		return nil, task, err
+	}
+	storageGroup.SetClient(storageservice.GetClient())
+
+	if parameters.ExposeVolumes {
+		if err := storageGroup.ExposeVolumes(); err != nil {
+			return &storageGroup, nil, err
+		}
+	}
+
+	return &storageGroup, nil, nil
+}
+
+// DeleteStorageGroup deletes a storage group. If the service processes the
+// deletion asynchronously, the task tracking the operation is returned.
+func (storageservice *StorageService) DeleteStorageGroup(storageGroupURI string) (*redfish.Task, error) {
+	return redfish.DeleteResource(storageservice.GetClient(), storageGroupURI)
+}